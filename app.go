@@ -17,8 +17,10 @@ package bosgo
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 // AppClient is a client used for interacting with services in the context of
@@ -26,64 +28,75 @@ import (
 // for concurrent use by multiple goroutines.
 type AppClient struct {
 	// never modified once they have been set
-	hc             *http.Client
-	addr           string
+	clientOptions
 	applicationKey string
-	ua             string
-	environment    string
-	retryPolicy    RetryPolicy
 
-	Providers *ProvidersService
-	Users     *AppUsersService
-	IBAN      *IBANService
+	Providers  *ProvidersService
+	Users      *AppUsersService
+	IBAN       *IBANService
+	Categories *CategoriesService
 }
 
 // NewAppClient creates a new client that may be used to interact with
-// services that require a specific application context.
-func NewAppClient(client *http.Client, addr string, applicationKey string) *AppClient {
+// services that require a specific application context. The options are
+// interpreted in the same way as by New.
+func NewAppClient(client *http.Client, addr string, applicationKey string, opts ...ClientOption) *AppClient {
+	c := New(client, addr, opts...)
+	return newAppClient(c.clientOptions, applicationKey)
+}
+
+// newAppClient creates an AppClient that uses the supplied options.
+func newAppClient(opts clientOptions, applicationKey string) *AppClient {
 	ac := &AppClient{
-		hc:             client,
-		addr:           addr,
+		clientOptions:  opts,
 		applicationKey: applicationKey,
 	}
 
 	ac.Providers = NewProvidersService(ac)
 	ac.Users = NewAppUsersService(ac)
 	ac.IBAN = NewIBANService(ac)
+	ac.Categories = NewCategoriesService(ac)
 	return ac
 }
 
 func (a *AppClient) newReq(path string) req {
-	return req{
-		hc:   a.hc,
-		addr: a.addr,
-		path: path,
-		headers: headers{
-			"User-Agent":        a.userAgent(),
-			"x-application-key": a.applicationKey,
-		},
-		par:         params{},
-		environment: a.environment,
-		retryPolicy: a.retryPolicy,
-	}
+	r := a.clientOptions.newReq(path)
+	r.headers["x-application-key"] = a.applicationKey
+	return r
 }
 
-func (a *AppClient) userAgent() string {
-	if a.ua == "" {
-		return DefaultUserAgent
-	}
+// ApplicationKey returns the application key used by the client. An app
+// client has no session of its own; the key, together with a user's ID and
+// session token, is all that is needed to restore a user session with
+// WithUserIDAndUserToken or NewUserClient.
+func (a *AppClient) ApplicationKey() string {
+	return a.applicationKey
+}
 
-	return DefaultUserAgent + " " + a.ua
+// SessionToken returns the credential that authenticates the client. App
+// clients are authenticated by their application key alone, so this is the
+// same value as ApplicationKey; it is provided so that app, developer and user
+// clients can be persisted and restored alike.
+func (a *AppClient) SessionToken() string {
+	return a.applicationKey
 }
 
 // WithUserToken creates a UserClient with the supplied user token and
 // application ID, copying options set on the receiver.
 func (a *AppClient) WithUserIDAndUserToken(userID, token string) *UserClient {
-	uc := NewUserClient(a.hc, a.addr, userID, token, a.applicationKey)
-	uc.ua = a.ua
-	uc.environment = a.environment
-	uc.retryPolicy = a.retryPolicy
-	return uc
+	return newUserClient(a.clientOptions, userID, token, a.applicationKey)
+}
+
+// UserFromToken creates a UserClient for an existing session using a session
+// token saved from an earlier login, copying options set on the receiver. The
+// UserID of the returned client is empty; use WithUserIDAndUserToken if the
+// user's ID was saved with the token.
+//
+// The token is not checked until the client is used. If the session has
+// expired or been logged out then requests fail with an *Error for which
+// IsAuth reports true, and the user must log in again.
+func (a *AppClient) UserFromToken(token string) *UserClient {
+	return a.WithUserIDAndUserToken("", token)
 }
 
 // ProvidersService provides access to financial provider related API services.
@@ -120,6 +133,34 @@ func (r *ProvidersSearchReq) ClientID(id string) *ProvidersSearchReq {
 	return r
 }
 
+// Country restricts the search to providers in the country with the given
+// ISO 3166-1 alpha-2 code.
+func (r *ProvidersSearchReq) Country(code string) *ProvidersSearchReq {
+	r.req.par.Set("country", code)
+	return r
+}
+
+// Capability restricts the search to providers that allow the named
+// operation, such as "transfer". Capabilities are named as in the JSON
+// encoding of ProviderAllowedOperations. Capability may be called more than
+// once to require several operations.
+func (r *ProvidersSearchReq) Capability(cap string) *ProvidersSearchReq {
+	r.req.par["capability"] = append(r.req.par["capability"], cap)
+	return r
+}
+
+// Limit sets the maximum number of results to return.
+func (r *ProvidersSearchReq) Limit(limit int) *ProvidersSearchReq {
+	r.req.par["limit"] = []string{fmt.Sprintf("%d", limit)}
+	return r
+}
+
+// Offset sets the number of results to skip before the first result returned.
+func (r *ProvidersSearchReq) Offset(offset int) *ProvidersSearchReq {
+	r.req.par["offset"] = []string{fmt.Sprintf("%d", offset)}
+	return r
+}
+
 // Send sends the request to search providers.
 func (r *ProvidersSearchReq) Send() (*ProviderSearchResults, error) {
 	res, cleanup, err := r.req.get()
@@ -136,6 +177,33 @@ func (r *ProvidersSearchReq) Send() (*ProviderSearchResults, error) {
 	return &srch, nil
 }
 
+// SendPage sends the request to search providers and returns the page of
+// results together with the total number of matching providers.
+func (r *ProvidersSearchReq) SendPage() (*ProviderSearchPage, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	page := ProviderSearchPage{Total: -1}
+	if err := json.NewDecoder(res.Body).Decode(&page.Results); err != nil {
+		return nil, decodeError(err, res)
+	}
+	if v, ok := headerInt(res.Header, "X-Total-Count"); ok {
+		page.Total = v
+	}
+	page.Limit, _ = strconv.Atoi(r.req.par.Get("limit"))
+	page.Offset, _ = strconv.Atoi(r.req.par.Get("offset"))
+
+	return &page, nil
+}
+
+// SendInto decodes the response into v, a pointer to a slice of structs embedding ProviderSearchResult.
+func (r *ProvidersSearchReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 // Get returns a request that may be used to get the details of a single financial provider.
 func (c *ProvidersService) Get(id string) *ProvidersGetReq {
 	return &ProvidersGetReq{
@@ -177,6 +245,69 @@ func (r *ProvidersGetReq) Send() (*Provider, error) {
 	return &p, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding Provider.
+func (r *ProvidersGetReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// InitialChallenge prepares and returns a request for the challenges that must
+// be answered to add an access to the provider with the given id. The
+// challenges are those of the provider's Challenges, in the order they should
+// be presented to the user.
+func (c *ProvidersService) InitialChallenge(id string) *InitialChallengeReq {
+	return &InitialChallengeReq{
+		req: c.client.newReq(apiV1 + "/providers/" + url.PathEscape(id)),
+	}
+}
+
+type InitialChallengeReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *InitialChallengeReq) Context(ctx context.Context) *InitialChallengeReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *InitialChallengeReq) ClientID(id string) *InitialChallengeReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request and returns the fields to be rendered for the
+// provider's initial challenges.
+func (r *InitialChallengeReq) Send() ([]ChallengeField, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var p Provider
+	if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	fields := make([]ChallengeField, 0, len(p.Challenges))
+	for _, spec := range p.Challenges {
+		fields = append(fields, ChallengeField{
+			ID:            spec.ID,
+			Description:   spec.Description,
+			ChallengeType: string(spec.Type),
+			Secure:        spec.Secure,
+			Optional:      spec.Optional,
+			UnStoreable:   spec.UnStoreable,
+			Methods:       spec.Methods,
+			Info:          spec.Info,
+		})
+	}
+	return fields, nil
+}
+
 // AppUsersService provides access to application user related API services.
 type AppUsersService struct {
 	client *AppClient
@@ -199,8 +330,9 @@ func (a *AppUsersService) Create(username, password string) *UserCreateReq {
 // UserCreateReq is a request that may be used to create a user.
 type UserCreateReq struct {
 	req
-	client *AppClient
-	data   UserCredentials
+	client  *AppClient
+	data    UserCredentials
+	orLogin bool
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -217,12 +349,27 @@ func (r *UserCreateReq) ClientID(id string) *UserCreateReq {
 	return r
 }
 
+// OrLogin makes the request log in with the same credentials if a user with
+// the username already exists, instead of failing. This allows users to be
+// provisioned idempotently.
+func (r *UserCreateReq) OrLogin() *UserCreateReq {
+	r.orLogin = true
+	return r
+}
+
 // Send sends the request to create the user and returns a client that can be
 // used to access services within the new users's session.
 func (r *UserCreateReq) Send() (*UserClient, error) {
 	res, cleanup, err := r.req.postJSON(r.data)
 	defer cleanup()
 	if err != nil {
+		if r.orLogin && isErrorCode(err, "authentication_email_not_unique") {
+			login := r.client.Users.Login(r.data.Username, r.data.Password).ClientID(r.req.clientID)
+			if r.req.ctx != nil {
+				login.Context(r.req.ctx)
+			}
+			return login.Send()
+		}
 		return nil, err
 	}
 
@@ -336,7 +483,7 @@ func NewIBANService(c *AppClient) *IBANService { return &IBANService{client: c}
 // Validate returns a request that may be used to validate an IBAN.
 func (a *IBANService) Validate(iban string) *ValidateIBANReq {
 	return &ValidateIBANReq{
-		req:    a.client.newReq(apiV1 + "/iban/" + url.PathEscape(iban)),
+		req:    a.client.newReq(apiV1 + "/iban/" + url.PathEscape(NormalizeIBAN(iban))),
 		client: a.client,
 	}
 }
@@ -376,3 +523,113 @@ func (r *ValidateIBANReq) Send() (*IBANDetails, error) {
 
 	return &id, nil
 }
+
+// CategoriesService provides access to the categories assigned to
+// transactions.
+type CategoriesService struct {
+	client *AppClient
+}
+
+func NewCategoriesService(c *AppClient) *CategoriesService { return &CategoriesService{client: c} }
+
+// List returns a request that may be used to list all transaction categories.
+func (c *CategoriesService) List() *CategoriesReq {
+	return &CategoriesReq{
+		req: c.client.newReq(apiV1 + "/categories"),
+	}
+}
+
+// CategoriesReq is a request that may be used to list transaction categories.
+type CategoriesReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *CategoriesReq) Context(ctx context.Context) *CategoriesReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *CategoriesReq) ClientID(id string) *CategoriesReq {
+	r.req.clientID = id
+	return r
+}
+
+// Locale requests category names in the given locale, such as "de". Names in
+// DefaultCategoryLocale are returned for categories that have no name in the
+// requested locale.
+func (r *CategoriesReq) Locale(lang string) *CategoriesReq {
+	r.req.par.Set("locale", lang)
+	return r
+}
+
+// Send sends the request to list categories.
+func (r *CategoriesReq) Send() (CategoryList, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var list CategoryList
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return list, nil
+}
+
+// Get returns a request that may be used to get a single transaction category.
+func (c *CategoriesService) Get(id int64) *CategoryGetReq {
+	return &CategoryGetReq{
+		req: c.client.newReq(apiV1 + "/categories/" + strconv.FormatInt(id, 10)),
+	}
+}
+
+// CategoryGetReq is a request that may be used to get a single transaction
+// category.
+type CategoryGetReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *CategoryGetReq) Context(ctx context.Context) *CategoryGetReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *CategoryGetReq) ClientID(id string) *CategoryGetReq {
+	r.req.clientID = id
+	return r
+}
+
+// Locale requests the category name in the given locale, such as "de". The
+// name in DefaultCategoryLocale is returned if the category has no name in the
+// requested locale.
+func (r *CategoryGetReq) Locale(lang string) *CategoryGetReq {
+	r.req.par.Set("locale", lang)
+	return r
+}
+
+// Send sends the request to get the category. If there is no category with
+// the requested id then IsNotFound reports true for the returned error.
+func (r *CategoryGetReq) Send() (*Category, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var cat Category
+	if err := json.NewDecoder(res.Body).Decode(&cat); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &cat, nil
+}
@@ -53,6 +53,75 @@ func TestUserLogin(t *testing.T) {
 	}
 }
 
+func TestClientCredentials(t *testing.T) {
+	devClient := NewDevClient(http.DefaultClient, SandboxAddr, "devtoken")
+	if got := devClient.SessionToken(); got != "devtoken" {
+		t.Errorf("got developer session token %q, wanted %q", got, "devtoken")
+	}
+
+	appClient := NewAppClient(http.DefaultClient, SandboxAddr, "appkey")
+	if got := appClient.ApplicationKey(); got != "appkey" {
+		t.Errorf("got application key %q, wanted %q", got, "appkey")
+	}
+	if got := appClient.SessionToken(); got != "appkey" {
+		t.Errorf("got app session token %q, wanted %q", got, "appkey")
+	}
+	if got := NewAppClient(http.DefaultClient, SandboxAddr, appClient.SessionToken()).ApplicationKey(); got != "appkey" {
+		t.Errorf("got restored application key %q, wanted %q", got, "appkey")
+	}
+
+	userClient := NewUserClient(http.DefaultClient, SandboxAddr, "uid", "usertoken", "appkey")
+	if got := userClient.SessionToken(); got != "usertoken" {
+		t.Errorf("got user session token %q, wanted %q", got, "usertoken")
+	}
+	if got := userClient.ApplicationKey(); got != "appkey" {
+		t.Errorf("got user application key %q, wanted %q", got, "appkey")
+	}
+
+	restored := appClient.WithUserIDAndUserToken(userClient.UserID, userClient.SessionToken())
+	if restored.UserID != "uid" || restored.SessionToken() != "usertoken" || restored.ApplicationKey() != "appkey" {
+		t.Errorf("got restored session (%q, %q, %q), wanted (uid, usertoken, appkey)", restored.UserID, restored.SessionToken(), restored.ApplicationKey())
+	}
+}
+
+func TestUserClientApp(t *testing.T) {
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("x-application-key") != "appkey" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.Header.Get("x-token") != "" {
+					t.Errorf("got user token sent with provider search, wanted none")
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"score":1,"provider":{"id":"DE-BIN-10000000","name":"Bankrs Bank"}}]`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey", WithUserAgent("test"))
+	appClient := userClient.App()
+	if appClient.hc != hc {
+		t.Errorf("derived application client does not share the user client's HTTP client")
+	}
+	if appClient.ua != "test" {
+		t.Errorf("got user agent %q, wanted %q", appClient.ua, "test")
+	}
+
+	results, err := appClient.Providers.Search("bankrs").Send()
+	if err != nil {
+		t.Fatalf("failed to search providers: %v", err)
+	}
+	if len(*results) != 1 || (*results)[0].Provider.ID != "DE-BIN-10000000" {
+		t.Errorf("got results %+v, wanted provider DE-BIN-10000000", *results)
+	}
+}
+
 func TestUserLoginUnknown(t *testing.T) {
 	routes := routeMap{
 		"/v1/users/login": {
@@ -109,3 +178,46 @@ func TestUserCreate(t *testing.T) {
 	}
 
 }
+
+func TestDerivedClientsShareHTTPClient(t *testing.T) {
+	routes := routeMap{
+		"/v1/developers/login": {
+			http.MethodPost: devTokenHandler,
+		},
+		"/v1/users/login": {
+			http.MethodPost: userTokenHandler,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	client := New(hc, SandboxAddr)
+
+	devClient, err := client.Login("dev@example.com", "password").Send()
+	if err != nil {
+		t.Fatalf("failed to login as developer: %v", err)
+	}
+	if devClient.hc != hc {
+		t.Errorf("developer client does not share the base HTTP client")
+	}
+	if team := devClient.WithTeam("team"); team.hc != hc {
+		t.Errorf("team scoped developer client does not share the base HTTP client")
+	}
+
+	appClient := client.WithApplicationKey("appkey")
+	if appClient.hc != hc {
+		t.Errorf("application client does not share the base HTTP client")
+	}
+
+	userClient, err := appClient.Users.Login("name", "password").Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+	if userClient.hc != hc {
+		t.Errorf("user client does not share the base HTTP client")
+	}
+	if userClient.hc.Transport != appClient.hc.Transport {
+		t.Errorf("user client does not share the application client's transport")
+	}
+}
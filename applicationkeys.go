@@ -24,6 +24,7 @@ func (d *ApplicationKeysService) Delete(applicationKey string) *DeleteAppKeyReq
 
 type DeleteAppKeyReq struct {
 	req
+	confirm *DeveloperConfirmAction
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -40,12 +41,14 @@ func (r *DeleteAppKeyReq) ClientID(id string) *DeleteAppKeyReq {
 	return r
 }
 
-func (r *DeleteAppKeyReq) Send() error {
-	_, cleanup, err := r.req.delete(nil)
-	defer cleanup()
-	if err != nil {
-		return err
-	}
+// Confirm confirms deleting the application key with the developer's password.
+// Without confirmation the request may be refused, in which case Send returns
+// an error matching ErrConfirmationRequired.
+func (r *DeleteAppKeyReq) Confirm(password string) *DeleteAppKeyReq {
+	r.confirm = &DeveloperConfirmAction{Password: password}
+	return r
+}
 
-	return nil
+func (r *DeleteAppKeyReq) Send() error {
+	return r.req.deleteConfirmed(r.confirm)
 }
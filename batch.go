@@ -0,0 +1,78 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+// BatchResult records the outcome of each item of an operation that acts on
+// several items at once, such as refreshing all of a user's accesses. Items are
+// identified by a string key, for example a job URI.
+type BatchResult struct {
+	keys []string
+	errs map[string]error
+}
+
+// add records the outcome for the item identified by key. A nil err marks the
+// item as having succeeded.
+func (b *BatchResult) add(key string, err error) {
+	if b.errs == nil {
+		b.errs = map[string]error{}
+	}
+	if _, exists := b.errs[key]; !exists {
+		b.keys = append(b.keys, key)
+	}
+	b.errs[key] = err
+}
+
+// Keys returns the keys of all items in the batch in the order they were processed.
+func (b *BatchResult) Keys() []string {
+	return append([]string(nil), b.keys...)
+}
+
+// Succeeded reports whether the item identified by key is part of the batch
+// and completed without error.
+func (b *BatchResult) Succeeded(key string) bool {
+	err, exists := b.errs[key]
+	return exists && err == nil
+}
+
+// Err returns the error encountered for the item identified by key, or nil if
+// the item succeeded or is not part of the batch.
+func (b *BatchResult) Err(key string) error {
+	return b.errs[key]
+}
+
+// Errors returns the errors encountered in the batch, indexed by item key. It
+// returns nil if every item succeeded.
+func (b *BatchResult) Errors() map[string]error {
+	var errs map[string]error
+	for _, key := range b.keys {
+		if err := b.errs[key]; err != nil {
+			if errs == nil {
+				errs = map[string]error{}
+			}
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+// AllSucceeded reports whether every item in the batch completed without error.
+func (b *BatchResult) AllSucceeded() bool {
+	for _, err := range b.errs {
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBatchResultMixed(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var b BatchResult
+	b.add("a", nil)
+	b.add("b", errFailed)
+	b.add("c", nil)
+
+	if b.AllSucceeded() {
+		t.Errorf("AllSucceeded: got true, wanted false")
+	}
+
+	wantKeys := []string{"a", "b", "c"}
+	if keys := b.Keys(); !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("Keys: got %v, wanted %v", keys, wantKeys)
+	}
+
+	wantErrs := map[string]error{"b": errFailed}
+	if errs := b.Errors(); !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("Errors: got %v, wanted %v", errs, wantErrs)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "unknown": false} {
+		if got := b.Succeeded(key); got != want {
+			t.Errorf("Succeeded(%q): got %v, wanted %v", key, got, want)
+		}
+	}
+
+	if err := b.Err("b"); err != errFailed {
+		t.Errorf("Err: got %v, wanted %v", err, errFailed)
+	}
+}
+
+func TestBatchResultAllSucceeded(t *testing.T) {
+	var b BatchResult
+	if !b.AllSucceeded() {
+		t.Errorf("empty batch: got AllSucceeded false, wanted true")
+	}
+
+	b.add("a", nil)
+	b.add("b", nil)
+	if !b.AllSucceeded() {
+		t.Errorf("got AllSucceeded false, wanted true")
+	}
+	if errs := b.Errors(); errs != nil {
+		t.Errorf("got errors %v, wanted nil", errs)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// WithRecordedResponses is a client option that layers a cassette of recorded
+// responses under the client's HTTP client. It is intended for demos and tests
+// that need to run deterministically without access to the API.
+//
+// Each request is identified by its method, path, query and body. If a
+// response for the request has been recorded in dir then it is replayed
+// without contacting the API, otherwise the request is sent and the response
+// is recorded in dir for use by later runs. Only successful responses are
+// recorded, so that transient failures are retried by later runs rather than
+// replayed. Request headers do not form part of the key so sessions replay
+// correctly even though their tokens differ.
+//
+// Each interaction is stored in dir as a JSON file named after the method and
+// the hex encoded SHA-256 hash of the key, for example GET-4f2a...json. The
+// file holds a single object:
+//
+//	{
+//	  "request": {"method": "GET", "uri": "/v1/accesses", "header": {...}},
+//	  "response": {"status_code": 200, "header": {...}, "body": "..."}
+//	}
+//
+// Secrets are redacted before a cassette is written. Request bodies, which may
+// hold passwords or challenge answers, are not stored; only their hash forms
+// part of the file name. The X-Token, X-Application-Key, Authorization, Cookie
+// and Set-Cookie headers are replaced by "REDACTED", as are the values of any JSON fields in
+// response bodies named "pin" or whose names contain "password", "secret" or
+// "token". Since tokens returned by logins are redacted, replayed sessions use
+// the token "REDACTED". A recording made with different credentials has
+// different request hashes and so is not replayed.
+func WithRecordedResponses(dir string) ClientOption {
+	return func(c *Client) {
+		c.cassetteDir = dir
+	}
+}
+
+// redacted replaces secrets in recorded cassettes.
+const redacted = "REDACTED"
+
+var redactedHeaders = []string{"X-Token", "X-Application-Key", "Authorization", "Cookie", "Set-Cookie"}
+
+var redactedFields = []string{"password", "secret", "token"}
+
+// cassette is the content of a single recorded interaction.
+type cassette struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// cassetteTransport replays recorded responses, falling back to next and
+// recording its response when none has been recorded.
+type cassetteTransport struct {
+	dir  string
+	next http.RoundTripper
+
+	mu sync.Mutex // serializes writes to dir
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	filename := filepath.Join(t.dir, cassetteName(req.Method, req.URL.RequestURI(), body))
+	if data, err := ioutil.ReadFile(filename); err == nil {
+		var cas cassette
+		if err := json.Unmarshal(data, &cas); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %v", filename, err)
+		}
+		return cas.Response.httpResponse(req), nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+	if res.StatusCode/100 != 2 {
+		return res, nil
+	}
+
+	resHeader := redactHeader(res.Header)
+	resHeader.Del("Content-Length") // the body length may change when redacted
+
+	cas := cassette{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URI:    req.URL.RequestURI(),
+			Header: redactHeader(req.Header),
+		},
+		Response: cassetteResponse{
+			StatusCode: res.StatusCode,
+			Header:     resHeader,
+			Body:       string(redactBody(resBody)),
+		},
+	}
+	if err := t.write(filename, cas); err != nil {
+		return nil, fmt.Errorf("failed to record response: %v", err)
+	}
+	return res, nil
+}
+
+func (t *cassetteTransport) write(filename string, cas cassette) error {
+	data, err := json.MarshalIndent(cas, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+func (r cassetteResponse) httpResponse(req *http.Request) *http.Response {
+	header := r.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// cassetteName returns the name of the file holding the recorded interaction
+// for a request.
+func cassetteName(method, uri string, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", method, uri)
+	h.Write(body)
+	return method + "-" + hex.EncodeToString(h.Sum(nil)) + ".json"
+}
+
+func redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	h := make(http.Header, len(header))
+	for k, v := range header {
+		h[k] = append([]string(nil), v...)
+	}
+	for _, k := range redactedHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+// redactBody replaces the values of secret fields in a JSON body. Bodies that
+// are not JSON are returned unchanged.
+func redactBody(body []byte) []byte {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if len(body) == 0 || dec.Decode(&v) != nil {
+		return body
+	}
+	data, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+func redactValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range vv {
+			if isSecretField(k) {
+				if _, ok := fv.(string); ok {
+					vv[k] = redacted
+					continue
+				}
+			}
+			vv[k] = redactValue(fv)
+		}
+	case []interface{}:
+		for i := range vv {
+			vv[i] = redactValue(vv[i])
+		}
+	}
+	return v
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	if name == "pin" {
+		return true
+	}
+	for _, f := range redactedFields {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
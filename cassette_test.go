@@ -0,0 +1,148 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network unavailable")
+}
+
+func TestRecordedResponses(t *testing.T) {
+	hits := 0
+	routes := routeMap{
+		"/v1/users/login": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Header().Add("Set-Cookie", "session=secret-cookie")
+				fmt.Fprint(w, `{"id":"uid","token":"secret-session-token"}`)
+			},
+		},
+		"/v1/accesses": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[{"id":4,"provider_id":"bankrs","name":"Bankrs Bank"}]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "bosgo-cassette")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	flow := func(client *Client) *AccessPage {
+		userClient, err := client.WithApplicationKey("appkey").Users.Login("username", "secret-password").Send()
+		if err != nil {
+			t.Fatalf("failed to login user: %v", err)
+		}
+		page, err := userClient.Accesses.List().Send()
+		if err != nil {
+			t.Fatalf("failed to list accesses: %v", err)
+		}
+		return page
+	}
+
+	recorded := flow(New(nil, SandboxAddr, WithTransport(hc.Transport), WithRecordedResponses(dir)))
+	if hits != 2 {
+		t.Fatalf("got %d requests to server while recording, wanted 2", hits)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list cassettes: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d cassettes, wanted 2", len(files))
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatalf("failed to read cassette: %v", err)
+		}
+		for _, secret := range []string{"secret-password", "secret-session-token", "appkey", "secret-cookie"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("cassette %s contains secret %q", filepath.Base(f), secret)
+			}
+		}
+	}
+
+	replayed := flow(New(nil, SandboxAddr, WithTransport(failingTransport{}), WithRecordedResponses(dir)))
+	if hits != 2 {
+		t.Errorf("got %d requests to server while replaying, wanted 2", hits)
+	}
+	if len(replayed.Accesses) != 1 || replayed.Accesses[0].ID != recorded.Accesses[0].ID {
+		t.Errorf("got replayed accesses %+v, wanted %+v", replayed.Accesses, recorded.Accesses)
+	}
+}
+
+func TestRecordedResponsesSkipsErrors(t *testing.T) {
+	hits := 0
+	routes := routeMap{
+		"/v1/accesses": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, `{"errors":[{"code":"general"}]}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "bosgo-cassette")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := New(nil, SandboxAddr, WithTransport(hc.Transport), WithRecordedResponses(dir))
+	userClient := client.WithApplicationKey("appkey").WithUserIDAndUserToken("uid", "token")
+	for i := 0; i < 2; i++ {
+		if _, err := userClient.Accesses.List().Send(); err == nil {
+			t.Fatalf("got no error, wanted bad gateway")
+		}
+	}
+	if hits != 2 {
+		t.Errorf("got %d requests to server, wanted 2 since errors are not replayed", hits)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list cassettes: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("got %d cassettes, wanted none for failed requests", len(files))
+	}
+}
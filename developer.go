@@ -17,71 +17,99 @@ package bosgo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// ErrConfirmationRequired matches the error returned when a destructive
+// operation is refused because the developer did not confirm it. Use the
+// Confirm method of the request to supply the developer's password.
+var ErrConfirmationRequired = errors.New("confirmation required")
+
+// errCodeConfirmationRequired is the error code returned by the API when an
+// operation requires confirmation.
+const errCodeConfirmationRequired = "confirmation_required"
+
 // DevClient is a client used for interacting with services that require a
 // valid developer session. It is safe for concurrent use by multiple goroutines.
 type DevClient struct {
 	// never modified once they have been set
-	hc          *http.Client
-	addr        string
-	token       string // session token
-	ua          string
-	environment string
-	retryPolicy RetryPolicy
+	clientOptions
+	token     string // session token
+	expiresAt time.Time
+	scopes    []string
+	teamID    string
 
 	Applications    *ApplicationsService
 	ApplicationKeys *ApplicationKeysService
 	Stats           *StatsService
 	Webhooks        *WebhooksService
 	Credentials     *CredentialsService
+	Teams           *TeamsService
+}
+
+// NewDevClient creates a new developer client, ready to use. The options are
+// interpreted in the same way as by New.
+func NewDevClient(client *http.Client, addr string, token string, opts ...ClientOption) *DevClient {
+	c := New(client, addr, opts...)
+	return newDevClient(c.clientOptions, token)
 }
 
-// NewDevClient creates a new developer client, ready to use.
-func NewDevClient(client *http.Client, addr string, token string) *DevClient {
+// newDevClient creates a DevClient that uses the supplied options.
+func newDevClient(opts clientOptions, token string) *DevClient {
 	dc := &DevClient{
-		hc:    client,
-		addr:  addr,
-		token: token,
+		clientOptions: opts,
+		token:         token,
 	}
 	dc.Applications = NewApplicationsService(dc)
 	dc.ApplicationKeys = NewApplicationKeysService(dc)
 	dc.Stats = NewStatsService(dc)
 	dc.Webhooks = NewWebhooksService(dc)
 	dc.Credentials = NewCredentialsService(dc)
+	dc.Teams = NewTeamsService(dc)
 
 	return dc
 }
 
-func (d *DevClient) userAgent() string {
-	if d.ua == "" {
-		return DefaultUserAgent
-	}
-
-	return DefaultUserAgent + " " + d.ua
-}
-
 // SessionToken returns the current session token.
 func (d *DevClient) SessionToken() string {
 	return d.token
 }
 
+// SessionExpiresAt returns the time at which the current session expires. The
+// boolean result is false if the API did not report an expiry, for example
+// when the client was created from a token using NewDevClient.
+func (d *DevClient) SessionExpiresAt() (time.Time, bool) {
+	return d.expiresAt, !d.expiresAt.IsZero()
+}
+
+// SessionScopes returns the scopes granted to the current session, if they
+// were reported by the API.
+func (d *DevClient) SessionScopes() []string {
+	return d.scopes
+}
+
 func (d *DevClient) newReq(path string) req {
-	return req{
-		hc:   d.hc,
-		addr: d.addr,
-		path: path,
-		headers: headers{
-			"User-Agent": d.userAgent(),
-			"x-token":    d.token,
-		},
-		par:         params{},
-		environment: d.environment,
-		retryPolicy: d.retryPolicy,
+	r := d.clientOptions.newReq(path)
+	r.headers["x-token"] = d.token
+	if d.teamID != "" {
+		r.headers["x-team-id"] = d.teamID
 	}
+	return r
+}
+
+// WithTeam returns a copy of the client whose requests are made in the
+// context of the team with the supplied ID. The teams a developer belongs to
+// may be obtained with LinkedTeams.
+func (d *DevClient) WithTeam(teamID string) *DevClient {
+	dc := newDevClient(d.clientOptions, d.token)
+	dc.expiresAt = d.expiresAt
+	dc.scopes = d.scopes
+	dc.teamID = teamID
+	return dc
 }
 
 // Logout prepares and returns a request to log a developer out of the Bankrs
@@ -133,6 +161,7 @@ func (d *DevClient) Delete() *DeveloperDeleteReq {
 
 type DeveloperDeleteReq struct {
 	req
+	confirm *DeveloperConfirmAction
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -149,15 +178,18 @@ func (r *DeveloperDeleteReq) ClientID(id string) *DeveloperDeleteReq {
 	return r
 }
 
+// Confirm confirms deleting the developer account with the developer's password.
+// Without confirmation the request may be refused, in which case Send returns
+// an error matching ErrConfirmationRequired.
+func (r *DeveloperDeleteReq) Confirm(password string) *DeveloperDeleteReq {
+	r.confirm = &DeveloperConfirmAction{Password: password}
+	return r
+}
+
 // Send sends the request to delete developer. Once this request has been sent
 // the developer client should not be used again.
 func (r *DeveloperDeleteReq) Send() error {
-	_, cleanup, err := r.req.delete(nil)
-	defer cleanup()
-	if err != nil {
-		return err
-	}
-	return nil
+	return r.req.deleteConfirmed(r.confirm)
 }
 
 // ChangePassword prepares and returns a request to change a developer's
@@ -246,6 +278,47 @@ func (r *DeveloperProfileReq) Send() (*DeveloperProfile, error) {
 	return &profile, nil
 }
 
+// LinkedTeams prepares and returns a request to list the teams the developer
+// owns or is a member of.
+func (d *DevClient) LinkedTeams() *LinkedTeamsReq {
+	return &LinkedTeamsReq{
+		req: d.newReq(apiV1 + "/developers/profile"),
+	}
+}
+
+type LinkedTeamsReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *LinkedTeamsReq) Context(ctx context.Context) *LinkedTeamsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *LinkedTeamsReq) ClientID(id string) *LinkedTeamsReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to list the developer's teams.
+func (r *LinkedTeamsReq) Send() ([]LinkedTeam, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	var profile DeveloperProfile
+	if err := json.NewDecoder(res.Body).Decode(&profile); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return profile.LinkedTeam, nil
+}
+
 // SetProfile sets the developer's profile.
 func (d *DevClient) SetProfile(profile *DeveloperProfile) *DeveloperSetProfileReq {
 	return &DeveloperSetProfileReq{
@@ -419,6 +492,7 @@ func (d *ApplicationsService) Delete(applicationID string) *DeleteApplicationsRe
 
 type DeleteApplicationsReq struct {
 	req
+	confirm *DeveloperConfirmAction
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -435,14 +509,16 @@ func (r *DeleteApplicationsReq) ClientID(id string) *DeleteApplicationsReq {
 	return r
 }
 
-func (r *DeleteApplicationsReq) Send() error {
-	_, cleanup, err := r.req.delete(nil)
-	defer cleanup()
-	if err != nil {
-		return err
-	}
+// Confirm confirms deleting the application with the developer's password.
+// Without confirmation the request may be refused, in which case Send returns
+// an error matching ErrConfirmationRequired.
+func (r *DeleteApplicationsReq) Confirm(password string) *DeleteApplicationsReq {
+	r.confirm = &DeveloperConfirmAction{Password: password}
+	return r
+}
 
-	return nil
+func (r *DeleteApplicationsReq) Send() error {
+	return r.req.deleteConfirmed(r.confirm)
 }
 
 func (d *ApplicationsService) ListKeys(applicationID string) *ListAppKeysReq {
@@ -523,6 +599,49 @@ func (r *CreateAppKeyReq) Send() (*ApplicationKey, error) {
 	return &key, nil
 }
 
+// DeleteKey returns a request that may be used to remove a key from an application.
+func (d *ApplicationsService) DeleteKey(applicationID string, key string) *DeleteApplicationKeyReq {
+	return &DeleteApplicationKeyReq{
+		req: d.client.newReq(apiV1 + "/developers/applications/" + url.PathEscape(applicationID) + "/keys/" + url.PathEscape(key)),
+	}
+}
+
+type DeleteApplicationKeyReq struct {
+	req
+	confirm *DeveloperConfirmAction
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *DeleteApplicationKeyReq) Context(ctx context.Context) *DeleteApplicationKeyReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *DeleteApplicationKeyReq) ClientID(id string) *DeleteApplicationKeyReq {
+	r.req.clientID = id
+	return r
+}
+
+// Confirm confirms deleting the application key with the developer's password.
+// Without confirmation the request may be refused, in which case Send returns
+// an error matching ErrConfirmationRequired.
+func (r *DeleteApplicationKeyReq) Confirm(password string) *DeleteApplicationKeyReq {
+	r.confirm = &DeveloperConfirmAction{Password: password}
+	return r
+}
+
+// Send sends the request to delete the application key. Requests made with
+// the key fail once it has been deleted.
+func (r *DeleteApplicationKeyReq) Send() error {
+	return r.req.deleteConfirmed(r.confirm)
+}
+
+// ListUsers prepares and returns a request to list the IDs of the users of
+// the application identified by applicationKey. Large lists may be paged
+// using Limit and Cursor.
 func (d *ApplicationsService) ListUsers(applicationKey string) *ListDevUsersReq {
 	r := d.client.newReq(apiV1 + "/developers/users")
 	r.headers["x-application-key"] = applicationKey
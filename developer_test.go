@@ -15,6 +15,7 @@
 package bosgo
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 )
@@ -35,3 +36,42 @@ func TestDeveloperLogout(t *testing.T) {
 		t.Fatalf("failed to send logout request: %v", err)
 	}
 }
+
+func TestDeveloperLinkedTeams(t *testing.T) {
+	routes := routeMap{
+		"/v1/developers/profile": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, `{"linked_teams":[{"id":"own","name":"Own team","active":%t,"owner":true},{"id":"other","name":"Other team","active":%t}]}`,
+					r.Header.Get("x-team-id") == "", r.Header.Get("x-team-id") == "other")
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	devClient := NewDevClient(hc, SandboxAddr, "devtoken")
+	teams, err := devClient.LinkedTeams().Send()
+	if err != nil {
+		t.Fatalf("failed to send linked teams request: %v", err)
+	}
+	if len(teams) != 2 {
+		t.Fatalf("got %d teams, wanted 2", len(teams))
+	}
+	if !teams[0].Active || !teams[0].Owner {
+		t.Errorf("got team %+v, wanted active owned team", teams[0])
+	}
+
+	teams, err = devClient.WithTeam("other").LinkedTeams().Send()
+	if err != nil {
+		t.Fatalf("failed to send linked teams request: %v", err)
+	}
+	if len(teams) != 2 {
+		t.Fatalf("got %d teams, wanted 2", len(teams))
+	}
+	if teams[0].Active || !teams[1].Active {
+		t.Errorf("got teams %+v, wanted other team to be active", teams)
+	}
+}
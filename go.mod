@@ -1,3 +1,3 @@
 module code.bankrs.com/bosgo
 
-go 1.12
+go 1.13
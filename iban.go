@@ -0,0 +1,85 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidIBAN is returned by ValidateIBAN when an IBAN is malformed, has the
+// wrong length for its country or fails checksum validation.
+var ErrInvalidIBAN = errors.New("invalid IBAN")
+
+// ibanLengths holds the length of IBANs issued in each country, indexed by
+// ISO 3166-1 country code.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16,
+	"BG": 22, "BH": 22, "BR": 29, "CH": 21, "CR": 22, "CY": 28, "CZ": 24,
+	"DE": 22, "DK": 18, "DO": 28, "EE": 20, "ES": 24, "FI": 18, "FO": 18,
+	"FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IS": 26, "IT": 27, "JO": 30,
+	"KW": 30, "KZ": 20, "LB": 28, "LI": 21, "LT": 20, "LU": 20, "LV": 21,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30,
+	"NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29,
+	"RO": 24, "RS": 22, "SA": 24, "SE": 24, "SI": 19, "SK": 24, "SM": 27,
+	"TN": 24, "TR": 26, "VG": 24, "XK": 20,
+}
+
+// NormalizeIBAN returns iban in its electronic format, in upper case with all
+// whitespace removed. It does not validate the IBAN.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// ValidateIBAN reports whether iban is a well formed IBAN with the correct
+// length for its country and a valid checksum. The IBAN is normalized before
+// it is validated. It returns ErrInvalidIBAN if the IBAN is not valid.
+func ValidateIBAN(iban string) error {
+	iban = NormalizeIBAN(iban)
+	if len(iban) < 4 {
+		return ErrInvalidIBAN
+	}
+
+	length, known := ibanLengths[iban[:2]]
+	if !known || len(iban) != length {
+		return ErrInvalidIBAN
+	}
+
+	// Move the country code and check digits to the end and interpret the
+	// result as a number with letters replaced by two digits, A = 10 to Z = 35
+	rem := 0
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return ErrInvalidIBAN
+		}
+	}
+	if rem != 1 {
+		return ErrInvalidIBAN
+	}
+	return nil
+}
+
+// sameIBAN reports whether a and b are the same IBAN, ignoring case and
+// whitespace.
+func sameIBAN(a, b string) bool {
+	a, b = NormalizeIBAN(a), NormalizeIBAN(b)
+	return a != "" && a == b
+}
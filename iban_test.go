@@ -0,0 +1,98 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeIBAN(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{in: "DE89370400440532013000", want: "DE89370400440532013000"},
+		{in: "de89 3704 0044 0532 0130 00", want: "DE89370400440532013000"},
+		{in: " gb82 WEST 1234 5698 7654 32\t", want: "GB82WEST12345698765432"},
+		{in: "", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := NormalizeIBAN(tc.in); got != tc.want {
+			t.Errorf("NormalizeIBAN(%q): got %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestValidateIBAN(t *testing.T) {
+	testCases := []struct {
+		iban  string
+		valid bool
+	}{
+		{iban: "DE89370400440532013000", valid: true},
+		{iban: "de89 3704 0044 0532 0130 00", valid: true},
+		{iban: "GB82 WEST 1234 5698 7654 32", valid: true},
+		{iban: "NO9386011117947", valid: true},
+		{iban: "MT84MALT011000012345MTLCAST001S", valid: true},
+		{iban: "DE89370400440532013001", valid: false},   // bad checksum
+		{iban: "DE8937040044053201300", valid: false},    // too short for DE
+		{iban: "NO93860111179470", valid: false},         // too long for NO
+		{iban: "XX89370400440532013000", valid: false},   // unknown country
+		{iban: "DE89-3704-0044-0532-0130", valid: false}, // bad characters
+		{iban: "DE", valid: false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateIBAN(tc.iban)
+		if tc.valid && err != nil {
+			t.Errorf("ValidateIBAN(%q): unexpected error: %v", tc.iban, err)
+		}
+		if !tc.valid && err != ErrInvalidIBAN {
+			t.Errorf("ValidateIBAN(%q): got error %v, wanted %v", tc.iban, err, ErrInvalidIBAN)
+		}
+	}
+}
+
+func TestCreateTransferNormalizesIBAN(t *testing.T) {
+	var iban string
+	routes := routeMap{
+		"/v1/transfers": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				var data transferParams
+				if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				iban = data.To.IBAN
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"1"}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+	to := TransferAddress{Name: "Jane Doe", IBAN: "de89 3704 0044 0532 0130 00"}
+	if _, err := userClient.Transfers.Create(1, to, MoneyAmount{Currency: "EUR", Value: "1.00"}).Send(); err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+	if iban != "DE89370400440532013000" {
+		t.Errorf("got IBAN %q, wanted DE89370400440532013000", iban)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// OverviewRecentTransactions is the maximum number of transactions included in
+// an Overview.
+const OverviewRecentTransactions = 10
+
+// Overview summarises a user's financial position across all of their
+// accesses.
+type Overview struct {
+	// TotalBalance is the sum of the balances of all accounts held in the
+	// requested currency. Accounts held in other currencies are not included.
+	TotalBalance MoneyAmount
+
+	// AccountCount is the number of accounts held by the user.
+	AccountCount int
+
+	// AccessCount is the number of accesses held by the user.
+	AccessCount int
+
+	// RecentTransactions holds up to OverviewRecentTransactions of the user's
+	// transactions.
+	RecentTransactions []Transaction
+}
+
+// Overview fetches the user's accesses, accounts and recent transactions
+// concurrently and summarises them. The balances of all accounts held in
+// currency are totalled. If any of the requests fails then the first error
+// encountered is returned.
+func (u *UserClient) Overview(ctx context.Context, currency string) (*Overview, error) {
+	var (
+		wg           sync.WaitGroup
+		accesses     *AccessPage
+		accounts     *AccountPage
+		transactions *TransactionPage
+		errs         [3]error
+	)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		accesses, errs[0] = u.Accesses.List().Context(ctx).Send()
+	}()
+	go func() {
+		defer wg.Done()
+		accounts, errs[1] = u.Accounts.List().Context(ctx).Send()
+	}()
+	go func() {
+		defer wg.Done()
+		transactions, errs[2] = u.Transactions.List().Context(ctx).Limit(OverviewRecentTransactions).Send()
+	}()
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	total := new(big.Rat)
+	for _, ac := range accounts.Accounts {
+		if ac.Currency != currency {
+			continue
+		}
+		balance, ok := new(big.Rat).SetString(ac.Balance)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q for account %d", ac.Balance, ac.ID)
+		}
+		total.Add(total, balance)
+	}
+
+	return &Overview{
+		TotalBalance: MoneyAmount{
+			Currency: currency,
+			Value:    total.FloatString(2),
+		},
+		AccountCount:       len(accounts.Accounts),
+		AccessCount:        len(accesses.Accesses),
+		RecentTransactions: transactions.Transactions,
+	}, nil
+}
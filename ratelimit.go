@@ -0,0 +1,212 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitSnapshot describes the rate limit reported by the Bankrs API in the
+// most recent response that carried rate limit headers. Integrators may use it
+// to throttle their requests before the API starts rejecting them with 429 Too
+// Many Requests.
+type RateLimitSnapshot struct {
+	// Limit is the number of requests permitted in the current window, from
+	// the X-RateLimit-Limit header. It is -1 if the header was not supplied.
+	Limit int
+
+	// Remaining is the number of requests remaining in the current window,
+	// from the X-RateLimit-Remaining header. It is -1 if the header was not
+	// supplied.
+	Remaining int
+
+	// Reset is the time at which the current window ends, from the
+	// X-RateLimit-Reset header. It is zero if the header was not supplied.
+	Reset time.Time
+
+	// UpdatedAt is the time the snapshot was taken. It is zero if no response
+	// has carried rate limit headers.
+	UpdatedAt time.Time
+}
+
+// Known reports whether any response has carried rate limit headers.
+func (s RateLimitSnapshot) Known() bool {
+	return !s.UpdatedAt.IsZero()
+}
+
+// resetEpochThreshold distinguishes X-RateLimit-Reset values that are Unix
+// timestamps from those that are a number of seconds until the reset.
+const resetEpochThreshold = 1000000000
+
+// rateLimitTracker records the rate limit reported by responses. It is shared
+// by a client and all clients derived from it.
+type rateLimitTracker struct {
+	mu   sync.Mutex
+	snap RateLimitSnapshot
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{
+		snap: RateLimitSnapshot{Limit: -1, Remaining: -1},
+	}
+}
+
+// update records the rate limit reported by header. Headers that are absent or
+// malformed are ignored.
+func (t *rateLimitTracker) update(header http.Header, now time.Time) {
+	if t == nil {
+		return
+	}
+
+	snap := RateLimitSnapshot{Limit: -1, Remaining: -1, UpdatedAt: now}
+	var found bool
+	if v, ok := headerInt(header, "X-RateLimit-Limit"); ok {
+		snap.Limit = v
+		found = true
+	}
+	if v, ok := headerInt(header, "X-RateLimit-Remaining"); ok {
+		snap.Remaining = v
+		found = true
+	}
+	if v, ok := headerInt(header, "X-RateLimit-Reset"); ok {
+		if v >= resetEpochThreshold {
+			snap.Reset = time.Unix(int64(v), 0)
+		} else {
+			snap.Reset = now.Add(time.Duration(v) * time.Second)
+		}
+		found = true
+	}
+	if !found {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.snap = snap
+}
+
+func (t *rateLimitTracker) snapshot() RateLimitSnapshot {
+	if t == nil {
+		return RateLimitSnapshot{Limit: -1, Remaining: -1}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snap
+}
+
+// headerInt parses the named header as a non-negative integer.
+func headerInt(header http.Header, name string) (int, bool) {
+	v := strings.TrimSpace(header.Get(name))
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// RateLimit returns the rate limit reported by the most recent response to a
+// request made by the client or by any client derived from it.
+func (c *Client) RateLimit() RateLimitSnapshot {
+	return c.rateLimit.snapshot()
+}
+
+// RateLimit returns the rate limit reported by the most recent response to a
+// request made by the client. The snapshot is shared with the client it was
+// derived from and with all other clients derived from that client.
+func (d *DevClient) RateLimit() RateLimitSnapshot {
+	return d.rateLimit.snapshot()
+}
+
+// RateLimit returns the rate limit reported by the most recent response to a
+// request made by the client. The snapshot is shared with the client it was
+// derived from and with all other clients derived from that client.
+func (a *AppClient) RateLimit() RateLimitSnapshot {
+	return a.rateLimit.snapshot()
+}
+
+// RateLimit returns the rate limit reported by the most recent response to a
+// request made by the client. The snapshot is shared with the client it was
+// derived from and with all other clients derived from that client.
+func (u *UserClient) RateLimit() RateLimitSnapshot {
+	return u.rateLimit.snapshot()
+}
+
+// tokenBucket paces requests sent by a client and the clients derived from it.
+// Tokens accumulate at rate per second up to burst and each request consumes
+// one.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller must
+// wait before the token may be used.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if b.last.IsZero() || now.After(b.last) {
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by a request that was abandoned while waiting.
+func (b *tokenBucket) cancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
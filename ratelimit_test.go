@@ -0,0 +1,183 @@
+// Copyright 2017 Bankrs AG.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bosgo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	remaining := 10
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				remaining--
+				w.Header().Set("X-RateLimit-Limit", "10")
+				w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+				w.Header().Set("X-RateLimit-Reset", "30")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[]`)
+			},
+		},
+		"/v1/iban/": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "not a number")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `{"valid":true}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	now := time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)
+	client := New(hc, SandboxAddr, WithClock(&fakeClock{now: now}))
+	appClient := client.WithApplicationKey("applicationkey")
+
+	if snap := appClient.RateLimit(); snap.Known() {
+		t.Errorf("got known rate limit %+v before any request", snap)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := appClient.Providers.Search("foo").Send(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := RateLimitSnapshot{
+		Limit:     10,
+		Remaining: 8,
+		Reset:     now.Add(30 * time.Second),
+		UpdatedAt: now,
+	}
+	if snap := appClient.RateLimit(); snap != want {
+		t.Errorf("got snapshot %+v, wanted %+v", snap, want)
+	}
+	if snap := client.RateLimit(); snap != want {
+		t.Errorf("got snapshot %+v from parent client, wanted %+v", snap, want)
+	}
+
+	// Malformed headers are ignored and leave the snapshot unchanged
+	if _, err := appClient.IBAN.Validate("DE89370400440532013000").Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap := appClient.RateLimit(); snap != want {
+		t.Errorf("got snapshot %+v after malformed headers, wanted %+v", snap, want)
+	}
+}
+
+func TestRateLimitTrackerResetEpoch(t *testing.T) {
+	tr := newRateLimitTracker()
+	now := time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)
+	reset := now.Add(time.Minute)
+
+	h := http.Header{}
+	h.Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+	tr.update(h, now)
+
+	snap := tr.snapshot()
+	if !snap.Reset.Equal(reset) {
+		t.Errorf("got reset %v, wanted %v", snap.Reset, reset)
+	}
+	if snap.Limit != -1 || snap.Remaining != -1 {
+		t.Errorf("got limit %d and remaining %d, wanted -1 for absent headers", snap.Limit, snap.Remaining)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var sent []time.Time
+	clock := &fakeClock{now: time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)}
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				sent = append(sent, clock.Now())
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	const (
+		rps   = 10
+		burst = 3
+		calls = 23
+	)
+	start := clock.Now()
+	appClient := New(hc, SandboxAddr, WithClock(clock), WithRateLimit(rps, burst)).WithApplicationKey("applicationkey")
+	for i := 0; i < calls; i++ {
+		if _, err := appClient.Providers.Search("foo").Send(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(sent) != calls {
+		t.Fatalf("got %d requests, wanted %d", len(sent), calls)
+	}
+	for i := 0; i < burst; i++ {
+		if !sent[i].Equal(start) {
+			t.Errorf("request %d was delayed by %v, wanted no delay within burst", i, sent[i].Sub(start))
+		}
+	}
+
+	// Over any interval no more than burst plus the rate's allowance may be sent
+	for i := range sent {
+		for j := i; j < len(sent); j++ {
+			allowed := burst + int(sent[j].Sub(sent[i]).Seconds()*rps+1e-9)
+			if n := j - i + 1; n > allowed {
+				t.Fatalf("sent %d requests in %v, wanted at most %d", n, sent[j].Sub(sent[i]), allowed)
+			}
+		}
+	}
+
+	want := time.Duration(calls-burst) * time.Second / rps
+	if elapsed := sent[calls-1].Sub(start); elapsed != want {
+		t.Errorf("got %v to send %d requests, wanted %v", elapsed, calls, want)
+	}
+}
+
+func TestWithRateLimitHonorsContext(t *testing.T) {
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	appClient := New(hc, SandboxAddr, WithRateLimit(0.001, 1)).WithApplicationKey("applicationkey")
+	if _, err := appClient.Providers.Search("foo").Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := appClient.Providers.Search("foo").Context(ctx).Send()
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, wanted context.DeadlineExceeded", err)
+	}
+}
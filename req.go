@@ -18,28 +18,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type req struct {
-	hc                *http.Client
+	clientOptions
 	ctx               context.Context
 	clientID          string
-	addr              string
 	path              string
 	par               params
 	headers           headers
-	environment       string
 	requestsAttempted int
-	retryPolicy       RetryPolicy
 	allowRetry        bool
+	requestID         string // from the X-Request-Id header of the last response
+	origin            *req   // the request first attempted, if this is a retry
+}
+
+// LastRequestID returns the ID assigned by the Bankrs API to the last attempt
+// to send the request, as reported in the X-Request-Id response header. It is
+// empty until the request has been sent or if the API did not supply an ID.
+// The ID may be quoted when contacting support about a request.
+func (r *req) LastRequestID() string {
+	return r.requestID
 }
 
 func (r *req) url() *url.URL {
@@ -56,21 +65,157 @@ func (r *req) policyAllowsRetry() bool {
 	return r.retryPolicy.canRetry(r.requestsAttempted + 1)
 }
 
-func (r *req) nextReq() (*req, time.Duration) {
+// nextReq returns the request to use when retrying r after it failed with
+// the response res and error err, together with the time to wait before
+// sending it. The wait is taken from the response's Retry-After header if it
+// has one, limited to the retry policy's MaxWait, otherwise it is computed by
+// the retry policy. The retry is reported to the request's logger.
+func (r *req) nextReq(res *http.Response, err error) (*req, time.Duration) {
 	r2 := &req{
-		hc:                r.hc,
+		clientOptions:     r.clientOptions,
 		ctx:               r.ctx,
 		clientID:          r.clientID,
-		addr:              r.addr,
 		path:              r.path,
 		par:               r.par,
 		headers:           r.headers,
-		environment:       r.environment,
 		requestsAttempted: r.requestsAttempted + 1,
-		retryPolicy:       r.retryPolicy,
 		allowRetry:        r.allowRetry,
+		origin:            r.origin,
+	}
+	if r2.origin == nil {
+		r2.origin = r
+	}
+	wait, ok := retryAfter(res, clockOrDefault(r.clock).Now())
+	if ok {
+		if r.retryPolicy.MaxWait != 0 && wait > r.retryPolicy.MaxWait {
+			wait = r.retryPolicy.MaxWait
+		}
+	} else {
+		wait = r.retryPolicy.NextWait(r2.requestsAttempted)
+	}
+	r.logRetry(r2.requestsAttempted+1, wait, err)
+	return r2, wait
+}
+
+// logRetry reports to the request's logger that the request will be sent
+// again as the given attempt after waiting for wait because of err.
+func (r *req) logRetry(attempt int, wait time.Duration, err error) {
+	if r.logger == nil {
+		return
+	}
+	code := ""
+	status := 0
+	if e, ok := err.(*Error); ok {
+		code = e.Code()
+		status = e.StatusCode
+	}
+	r.logger.Logf("bosgo: retrying %s (attempt %d) in %v after status %d, code %q", r.path, attempt, wait, status, code)
+}
+
+// retryAfter returns the wait requested by the Retry-After header of res,
+// which may be given in seconds or as an HTTP date relative to now.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if wait := t.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// RequestInfo describes a single attempt to send a request to the API. It is
+// passed to the observer set using WithObserver.
+type RequestInfo struct {
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Path is the path of the requested resource.
+	Path string
+
+	// StatusCode is the HTTP status code of the response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// Duration is the time taken to receive the response.
+	Duration time.Duration
+
+	// Attempt is the number of the attempt, starting at 1 for the original
+	// request and increasing with each retry.
+	Attempt int
+
+	// RequestID is the request id reported by the API, if any.
+	RequestID string
+
+	// Err is the error returned by the HTTP client if no response was received.
+	Err error
+}
+
+// do sends the HTTP request and reports the attempt to the request's observer.
+func (r *req) do(hreq *http.Request) (*http.Response, error) {
+	clock := clockOrDefault(r.clock)
+	if wait := r.limiter.reserve(clock.Now()); wait > 0 {
+		if err := r.sleep(wait); err != nil {
+			r.limiter.cancel()
+			return nil, err
+		}
+	}
+
+	start := clock.Now()
+	res, err := r.hc.Do(hreq)
+	r.requestID = ""
+	if res != nil {
+		r.requestID = res.Header.Get("X-Request-Id")
+		r.rateLimit.update(res.Header, clock.Now())
+	}
+	if r.origin != nil {
+		r.origin.requestID = r.requestID
+	}
+	if r.observer == nil {
+		return res, err
+	}
+
+	info := RequestInfo{
+		Method:   hreq.Method,
+		Path:     hreq.URL.Path,
+		Duration: clock.Now().Sub(start),
+		Attempt:  r.requestsAttempted + 1,
+		Err:      err,
+	}
+	if res != nil {
+		info.StatusCode = res.StatusCode
+		info.RequestID = r.requestID
+	}
+	r.observer(info)
+
+	return res, err
+}
+
+// sleep waits for the duration d according to the request's clock. It returns
+// the context's error if the request's context is cancelled or its deadline
+// expires before d has elapsed.
+func (r *req) sleep(d time.Duration) error {
+	if r.ctx == nil {
+		<-clockOrDefault(r.clock).After(d)
+		return nil
+	}
+
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-clockOrDefault(r.clock).After(d):
+		return nil
 	}
-	return r2, r.retryPolicy.NextWait(r2.requestsAttempted)
 }
 
 func (r *req) get() (*http.Response, func(), error) {
@@ -91,15 +236,17 @@ func (r *req) get() (*http.Response, func(), error) {
 		req.Header.Set(k, v)
 	}
 
-	res, err := r.hc.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, func() {}, err
 	}
 	if err, retry := responseError(res); err != nil {
 		// By default all GETs are deemed to be retryable
 		if retry && r.policyAllowsRetry() {
-			nextReq, wait := r.nextReq()
-			time.Sleep(wait)
+			nextReq, wait := r.nextReq(res, err)
+			if err := r.sleep(wait); err != nil {
+				return nil, func() {}, err
+			}
 			return nextReq.get()
 		}
 
@@ -108,6 +255,40 @@ func (r *req) get() (*http.Response, func(), error) {
 	return res, cleanup(res), nil
 }
 
+// getInto performs a GET request and decodes the JSON response into v. It
+// implements the SendInto methods described in the package documentation.
+func (r *req) getInto(v interface{}) error {
+	res, cleanup, err := r.get()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return decodeError(err, res)
+	}
+	return nil
+}
+
+// getRaw performs a GET request for a resource that is not JSON encoded,
+// such as a document or image. It returns the response body and its content
+// type without attempting to decode it. Ownership of the body passes to the
+// caller, which is responsible for closing it, even if reading it fails.
+// Error responses are still decoded as JSON and closed.
+func (r *req) getRaw(accept string) (io.ReadCloser, string, error) {
+	if accept != "" {
+		if r.headers == nil {
+			r.headers = headers{}
+		}
+		r.headers["Accept"] = accept
+	}
+
+	res, _, err := r.get()
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Body, res.Header.Get("Content-Type"), nil
+}
+
 func (r *req) postJSON(data interface{}) (*http.Response, func(), error) {
 	var body io.Reader
 	if data != nil {
@@ -137,14 +318,16 @@ func (r *req) postJSON(data interface{}) (*http.Response, func(), error) {
 		req.Header.Set(k, v)
 	}
 
-	res, err := r.hc.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, func() {}, err
 	}
 	if err, retry := responseError(res); err != nil {
 		if retry && r.allowRetry && r.policyAllowsRetry() {
-			nextReq, wait := r.nextReq()
-			time.Sleep(wait)
+			nextReq, wait := r.nextReq(res, err)
+			if err := r.sleep(wait); err != nil {
+				return nil, func() {}, err
+			}
 			return nextReq.postJSON(data)
 		}
 		return nil, func() {}, err
@@ -181,14 +364,16 @@ func (r *req) putJSON(data interface{}) (*http.Response, func(), error) {
 		req.Header.Set(k, v)
 	}
 
-	res, err := r.hc.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, func() {}, err
 	}
 	if err, retry := responseError(res); err != nil {
 		if retry && r.allowRetry && r.policyAllowsRetry() {
-			nextReq, wait := r.nextReq()
-			time.Sleep(wait)
+			nextReq, wait := r.nextReq(res, err)
+			if err := r.sleep(wait); err != nil {
+				return nil, func() {}, err
+			}
 			return nextReq.putJSON(data)
 		}
 		return nil, func() {}, err
@@ -223,14 +408,16 @@ func (r *req) delete(data interface{}) (*http.Response, func(), error) {
 		req.Header.Set(k, v)
 	}
 
-	res, err := r.hc.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, func() {}, err
 	}
 	if err, retry := responseError(res); err != nil {
 		if retry && r.allowRetry && r.policyAllowsRetry() {
-			nextReq, wait := r.nextReq()
-			time.Sleep(wait)
+			nextReq, wait := r.nextReq(res, err)
+			if err := r.sleep(wait); err != nil {
+				return nil, func() {}, err
+			}
 			return nextReq.delete(data)
 		}
 		return nil, func() {}, err
@@ -238,6 +425,18 @@ func (r *req) delete(data interface{}) (*http.Response, func(), error) {
 	return res, cleanup(res), nil
 }
 
+// deleteConfirmed sends a delete request, including confirm as the body if it
+// is not nil.
+func (r *req) deleteConfirmed(confirm *DeveloperConfirmAction) error {
+	var data interface{}
+	if confirm != nil {
+		data = confirm
+	}
+	_, cleanup, err := r.delete(data)
+	defer cleanup()
+	return err
+}
+
 func (r *req) deleteJSON(data interface{}) (*http.Response, func(), error) {
 	var body io.Reader
 	if data != nil {
@@ -267,14 +466,16 @@ func (r *req) deleteJSON(data interface{}) (*http.Response, func(), error) {
 		req.Header.Set(k, v)
 	}
 
-	res, err := r.hc.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, func() {}, err
 	}
 	if err, retry := responseError(res); err != nil {
 		if retry && r.allowRetry && r.policyAllowsRetry() {
-			nextReq, wait := r.nextReq()
-			time.Sleep(wait)
+			nextReq, wait := r.nextReq(res, err)
+			if err := r.sleep(wait); err != nil {
+				return nil, func() {}, err
+			}
 			return nextReq.deleteJSON(data)
 		}
 		return nil, func() {}, err
@@ -282,11 +483,25 @@ func (r *req) deleteJSON(data interface{}) (*http.Response, func(), error) {
 	return res, cleanup(res), nil
 }
 
+// maxDrainBytes is the maximum number of unread bytes discarded from a
+// response body before it is closed. Draining a body allows its connection to
+// be reused.
+const maxDrainBytes = 64 << 10
+
+// cleanup returns a function that closes the body of res, which must be called
+// once the caller has finished with the response. The request helpers return
+// it alongside successful responses and a no-op function otherwise, since
+// error responses are consumed and closed by responseError. Requests that
+// decode JSON defer the returned function so the body is closed on every
+// path, including decode failures. Requests that stream a body to the caller,
+// such as those built on getRaw, instead transfer ownership of the body and
+// document that the caller must close it.
 func cleanup(res *http.Response) func() {
 	return func() {
 		if res == nil || res.Body == nil {
 			return
 		}
+		io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxDrainBytes))
 		res.Body.Close()
 	}
 }
@@ -332,6 +547,104 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("request failed with status %s [request-id: %s; URL: %s]", e.Status, e.RequestID, e.URL)
 }
 
+// Sentinel errors that API errors may be compared with using errors.Is. An
+// *Error matches a sentinel when any of its error codes or its HTTP status
+// corresponds to it, the same rule used by IsNotFound and IsAuth.
+var (
+	ErrAuthenticationFailed = errors.New("authentication failed")
+	ErrResourceNotFound     = errors.New("resource not found")
+)
+
+// Error codes returned by the API that are interpreted by the client.
+const (
+	errCodeAuthenticationFailed = "authentication_failed"
+	errCodeResourceNotFound     = "resource_not_found"
+	errCodeValidationPrefix     = "validation_"
+)
+
+// sentinels lists the sentinel errors in the order Unwrap checks them.
+var sentinels = []error{
+	ErrAuthenticationFailed,
+	ErrResourceNotFound,
+	ErrConfirmationRequired,
+}
+
+// sentinelCodes maps sentinel errors to the API error codes they correspond to.
+var sentinelCodes = map[error]string{
+	ErrAuthenticationFailed: errCodeAuthenticationFailed,
+	ErrResourceNotFound:     errCodeResourceNotFound,
+	ErrConfirmationRequired: errCodeConfirmationRequired,
+}
+
+// sentinelStatuses maps sentinel errors to the HTTP statuses they correspond to.
+var sentinelStatuses = map[error]int{
+	ErrAuthenticationFailed: http.StatusUnauthorized,
+	ErrResourceNotFound:     http.StatusNotFound,
+}
+
+// Is reports whether e corresponds to the sentinel error target, allowing
+// callers to write errors.Is(err, bosgo.ErrResourceNotFound).
+func (e *Error) Is(target error) bool {
+	if status, ok := sentinelStatuses[target]; ok && e.StatusCode == status {
+		return true
+	}
+	code, ok := sentinelCodes[target]
+	return ok && e.hasCode(code)
+}
+
+// hasCode reports whether any of the errors reported by the service has the
+// given code.
+func (e *Error) hasCode(code string) bool {
+	for _, item := range e.Errors {
+		if item.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the first sentinel error, in the order they are declared,
+// that e corresponds to, or nil if there is none.
+func (e *Error) Unwrap() error {
+	for _, target := range sentinels {
+		if e.Is(target) {
+			return target
+		}
+	}
+	return nil
+}
+
+// Code returns the code of the first error reported by the service, or an
+// empty string if none was reported.
+func (e *Error) Code() string {
+	if len(e.Errors) == 0 {
+		return ""
+	}
+	return e.Errors[0].Code
+}
+
+// IsRetryable reports whether the request that caused e may succeed if it is
+// sent again, which is the case for server errors and rate limited requests.
+// Requests for endpoints that are not implemented are never retryable.
+func (e *Error) IsRetryable() bool {
+	if e.StatusCode/100 != 5 && e.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return !e.hasCode(errCodeNotImplemented)
+}
+
+// IsAuth reports whether e was caused by missing or invalid credentials, such
+// as an expired session token or a wrong password.
+func (e *Error) IsAuth() bool {
+	return e.Is(ErrAuthenticationFailed)
+}
+
+// IsValidation reports whether e was caused by invalid parameters supplied
+// with the request.
+func (e *Error) IsValidation() bool {
+	return strings.HasPrefix(e.Code(), errCodeValidationPrefix)
+}
+
 // ErrorItem is a detailed error code & message.
 type ErrorItem struct {
 	Code    string              `json:"code"`    // standard error code
@@ -363,6 +676,37 @@ func (ei *ErrorItem) Description() string {
 	return buf.String()
 }
 
+// IsNotFound reports whether err is, or wraps, an error returned by the Bankrs
+// API because the requested resource does not exist.
+func IsNotFound(err error) bool {
+	var rerr *Error
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	return rerr.Is(ErrResourceNotFound)
+}
+
+// errCodeNotImplemented is the error code returned by the test server for
+// endpoints it does not implement.
+const errCodeNotImplemented = "not_implemented_by_test_server"
+
+// IsNotImplemented reports whether err is an error returned because the
+// requested endpoint is not implemented, as is the case for some endpoints of
+// the test server. Such requests are never retried.
+func IsNotImplemented(err error) bool {
+	return isErrorCode(err, errCodeNotImplemented)
+}
+
+// isErrorCode reports whether err is, or wraps, an API error containing the
+// given code.
+func isErrorCode(err error, code string) bool {
+	var rerr *Error
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	return rerr.hasCode(code)
+}
+
 func responseError(res *http.Response) (error, bool) {
 	if res == nil {
 		return &Error{
@@ -381,13 +725,8 @@ func responseError(res *http.Response) (error, bool) {
 		URL:        res.Request.URL.String(),
 	}
 
-	var retryable bool
-	if res.StatusCode/100 == 5 {
-		retryable = true
-	}
-
 	if res.Body == nil {
-		return rerr, retryable
+		return rerr, rerr.IsRetryable()
 	}
 	defer res.Body.Close()
 
@@ -397,7 +736,7 @@ func responseError(res *http.Response) (error, bool) {
 			Code:    "unable_to_read_error_response",
 			Message: err.Error(),
 		})
-		return rerr, retryable
+		return rerr, rerr.IsRetryable()
 	}
 
 	var serr Error
@@ -414,11 +753,11 @@ func responseError(res *http.Response) (error, bool) {
 			Code:    "unable_to_unmarshal_error_response",
 			Message: fmt.Sprintf("received %s", msg),
 		})
-		return rerr, retryable
+		return rerr, rerr.IsRetryable()
 	}
 
 	rerr.Errors = append(rerr.Errors, serr.Errors...)
-	return rerr, retryable
+	return rerr, rerr.IsRetryable()
 }
 
 func decodeError(err error, res *http.Response) error {
@@ -1,6 +1,12 @@
 package bosgo
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 )
@@ -121,3 +127,299 @@ func TestRetryPolicyCanRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorIs(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{
+			name:   "authentication failed code",
+			err:    &Error{StatusCode: http.StatusUnauthorized, Errors: []ErrorItem{{Code: "authentication_failed"}}},
+			target: ErrAuthenticationFailed,
+			want:   true,
+		},
+		{
+			name:   "resource not found code",
+			err:    &Error{StatusCode: http.StatusNotFound, Errors: []ErrorItem{{Code: "resource_not_found"}}},
+			target: ErrResourceNotFound,
+			want:   true,
+		},
+		{
+			name:   "resource not found code is not authentication failed",
+			err:    &Error{StatusCode: http.StatusNotFound, Errors: []ErrorItem{{Code: "resource_not_found"}}},
+			target: ErrAuthenticationFailed,
+			want:   false,
+		},
+		{
+			name:   "any code is matched",
+			err:    &Error{StatusCode: http.StatusBadRequest, Errors: []ErrorItem{{Code: "general"}, {Code: "resource_not_found"}}},
+			target: ErrResourceNotFound,
+			want:   true,
+		},
+		{
+			name:   "status is matched whatever the codes",
+			err:    &Error{StatusCode: http.StatusNotFound, Errors: []ErrorItem{{Code: "general"}}},
+			target: ErrResourceNotFound,
+			want:   true,
+		},
+		{
+			name:   "other status and codes",
+			err:    &Error{StatusCode: http.StatusBadRequest, Errors: []ErrorItem{{Code: "general"}}},
+			target: ErrResourceNotFound,
+			want:   false,
+		},
+		{
+			name:   "unauthorized status without codes",
+			err:    &Error{StatusCode: http.StatusUnauthorized},
+			target: ErrAuthenticationFailed,
+			want:   true,
+		},
+		{
+			name:   "not found status without codes",
+			err:    &Error{StatusCode: http.StatusNotFound},
+			target: ErrResourceNotFound,
+			want:   true,
+		},
+		{
+			name:   "confirmation required code",
+			err:    &Error{StatusCode: http.StatusForbidden, Errors: []ErrorItem{{Code: "confirmation_required"}}},
+			target: ErrConfirmationRequired,
+			want:   true,
+		},
+		{
+			name:   "wrapped error",
+			err:    fmt.Errorf("listing accesses: %w", &Error{Errors: []ErrorItem{{Code: "authentication_failed"}}}),
+			target: ErrAuthenticationFailed,
+			want:   true,
+		},
+		{
+			name:   "unrelated error",
+			err:    errors.New("authentication failed"),
+			target: ErrAuthenticationFailed,
+			want:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errors.Is(tc.err, tc.target); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			// The predicates must agree with errors.Is
+			if tc.target == ErrResourceNotFound && IsNotFound(tc.err) != tc.want {
+				t.Errorf("got IsNotFound %v, wanted %v", !tc.want, tc.want)
+			}
+		})
+	}
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	err := &Error{StatusCode: http.StatusNotFound, Errors: []ErrorItem{{Code: "resource_not_found"}}}
+	if got := err.Unwrap(); got != ErrResourceNotFound {
+		t.Errorf("got %v, wanted ErrResourceNotFound", got)
+	}
+
+	// An error matching several sentinels always unwraps to the same one
+	err = &Error{StatusCode: http.StatusUnauthorized, Errors: []ErrorItem{{Code: "resource_not_found"}}}
+	for i := 0; i < 10; i++ {
+		if got := err.Unwrap(); got != ErrAuthenticationFailed {
+			t.Fatalf("got %v, wanted ErrAuthenticationFailed", got)
+		}
+	}
+
+	err = &Error{StatusCode: http.StatusBadRequest, Errors: []ErrorItem{{Code: "general"}}}
+	if got := err.Unwrap(); got != nil {
+		t.Errorf("got %v, wanted nil", got)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        *Error
+		code       string
+		retryable  bool
+		auth       bool
+		validation bool
+	}{
+		{
+			name: "no error items",
+			err:  &Error{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:      "server error without error items",
+			err:       &Error{StatusCode: http.StatusBadGateway},
+			retryable: true,
+		},
+		{
+			name:      "too many requests",
+			err:       &Error{StatusCode: http.StatusTooManyRequests, Errors: []ErrorItem{{Code: "general"}}},
+			code:      "general",
+			retryable: true,
+		},
+		{
+			name: "not implemented",
+			err:  &Error{StatusCode: http.StatusInternalServerError, Errors: []ErrorItem{{Code: errCodeNotImplemented}}},
+			code: errCodeNotImplemented,
+		},
+		{
+			name: "unauthorized without error items",
+			err:  &Error{StatusCode: http.StatusUnauthorized},
+			auth: true,
+		},
+		{
+			name:       "validation",
+			err:        &Error{StatusCode: http.StatusBadRequest, Errors: []ErrorItem{{Code: "validation_bad_parameters"}, {Code: "general"}}},
+			code:       "validation_bad_parameters",
+			validation: true,
+		},
+		{
+			name: "authentication failed after another code",
+			err:  &Error{StatusCode: http.StatusBadRequest, Errors: []ErrorItem{{Code: "general"}, {Code: "authentication_failed"}}},
+			code: "general",
+			auth: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.err.Code(); got != tc.code {
+				t.Errorf("got code %q, wanted %q", got, tc.code)
+			}
+			if got := tc.err.IsRetryable(); got != tc.retryable {
+				t.Errorf("got retryable %v, wanted %v", got, tc.retryable)
+			}
+			if got := tc.err.IsAuth(); got != tc.auth {
+				t.Errorf("got auth %v, wanted %v", got, tc.auth)
+			}
+			if got := tc.err.IsValidation(); got != tc.validation {
+				t.Errorf("got validation %v, wanted %v", got, tc.validation)
+			}
+		})
+	}
+}
+
+// countingBody records whether a response body has been read to the end and
+// closed.
+type countingBody struct {
+	io.ReadCloser
+	path   string
+	eof    bool
+	closed bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.closed = true
+	return b.ReadCloser.Close()
+}
+
+// bodyTrackingTransport wraps the bodies of responses so that the test can
+// check that every one of them is closed.
+type bodyTrackingTransport struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	bodies []*countingBody
+}
+
+func (t *bodyTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body := &countingBody{ReadCloser: res.Body, path: req.URL.Path}
+	res.Body = body
+
+	t.mu.Lock()
+	t.bodies = append(t.bodies, body)
+	t.mu.Unlock()
+	return res, nil
+}
+
+func TestResponseBodiesClosed(t *testing.T) {
+	retried := false
+	routes := routeMap{
+		"/v1/accounts/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id":1} trailing data that is never decoded`)
+			},
+		},
+		"/v1/accounts/2": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id":"not a number"}`)
+			},
+		},
+		"/v1/accounts/3": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":[{"code":"validation_bad_parameters"}]}`)
+			},
+		},
+		"/v1/accounts/4": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				if !retried {
+					retried = true
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id":4}`)
+			},
+		},
+		"/v1/accounts/5/statement": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Write([]byte("%PDF-1.4"))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	rt := &bodyTrackingTransport{next: hc.Transport}
+	userClient := NewUserClient(&http.Client{Transport: rt}, SandboxAddr, "uid", "usertoken", "appkey", WithRetryPolicy(RetryPolicy{MaxRetries: 1}), WithClock(&fakeClock{}))
+
+	if _, err := userClient.Accounts.Get("1").Send(); err != nil {
+		t.Errorf("unexpected error for account 1: %v", err)
+	}
+	if _, err := userClient.Accounts.Get("2").Send(); err == nil {
+		t.Errorf("got no error for undecodable account 2")
+	}
+	if _, err := userClient.Accounts.Get("3").Send(); err == nil {
+		t.Errorf("got no error for account 3")
+	}
+	if _, err := userClient.Accounts.Get("4").Send(); err != nil {
+		t.Errorf("unexpected error for account 4: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := userClient.Accounts.Statement(5).Send(&buf); err != nil {
+		t.Errorf("unexpected error for statement: %v", err)
+	}
+
+	if len(rt.bodies) != 6 {
+		t.Fatalf("got %d responses, wanted 6", len(rt.bodies))
+	}
+	for _, body := range rt.bodies {
+		if !body.closed {
+			t.Errorf("response body for %s was not closed", body.path)
+		}
+	}
+	if !rt.bodies[0].eof {
+		t.Errorf("response body for %s was not drained before closing", rt.bodies[0].path)
+	}
+}
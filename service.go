@@ -13,12 +13,23 @@
 // limitations under the License.
 
 // Package bosgo provides a Go client for accessing the Bankrs OS API.
+//
+// Requests that fetch resources have a Send method that decodes the response
+// into the types defined by this package. Many also have a SendInto method that
+// decodes the response into a value supplied by the caller instead, typically a
+// struct embedding the package's type, so that fields returned by the API that
+// this package does not yet model can be captured. Send should be preferred
+// otherwise.
 package bosgo
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"net"
 	"net/http"
+	"time"
 )
 
 const (
@@ -39,70 +50,161 @@ const (
 
 // Client is the base client used for interacting with services that do not
 // require authentication. Use Login to initiate a developer session.  It is
-// safe for concurrent use by multiple goroutines.
+// safe for concurrent use by multiple goroutines. Application, developer and
+// user clients derived from a Client share its HTTP client so that
+// connections, including TLS sessions, are reused across all of them.
 type Client struct {
 	// never modified once they have been set
+	clientOptions
+	tlsConfig   *tls.Config    // only used when no HTTP client is supplied to New
+	rootCAs     *x509.CertPool // only used when no HTTP client is supplied to New
+	transport   http.RoundTripper
+	cassetteDir string
+}
+
+// clientOptions holds the settings shared by every kind of client and by the
+// requests they make. Clients derived from another client copy them as a
+// whole, so a new option only needs to be added here.
+type clientOptions struct {
 	hc          *http.Client
 	addr        string
 	ua          string
 	environment string
 	retryPolicy RetryPolicy
+	clock       Clock
+	observer    func(RequestInfo)
+	logger      Logger
+	rateLimit   *rateLimitTracker
+	limiter     *tokenBucket
 }
 
 type ClientOption func(*Client)
 
 // New creates a new client that will use the supplied HTTP client and connect
-// via the specified API host address.
+// via the specified API host address. If client is nil then an HTTP client is
+// created using the TLS configuration set by the WithTLSConfig and WithRootCAs
+// options, or http.DefaultClient if neither was used.
 func New(client *http.Client, addr string, opts ...ClientOption) *Client {
 	c := &Client{
-		hc:   client,
-		addr: addr,
+		clientOptions: clientOptions{
+			hc:        client,
+			addr:      addr,
+			rateLimit: newRateLimitTracker(),
+		},
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.transport != nil {
+		// Copy the supplied client so its other settings, such as timeouts,
+		// are kept without modifying it
+		hc := &http.Client{}
+		if c.hc != nil {
+			*hc = *c.hc
+		}
+		hc.Transport = c.transport
+		c.hc = hc
+	}
+	if c.hc == nil {
+		c.hc = newHTTPClient(c.tlsConfig, c.rootCAs)
+	}
+	if c.cassetteDir != "" {
+		hc := &http.Client{}
+		*hc = *c.hc
+		next := hc.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		hc.Transport = &cassetteTransport{dir: c.cassetteDir, next: next}
+		c.hc = hc
+	}
 	return c
 }
 
-func (c *Client) newReq(path string) req {
+// newHTTPClient returns an HTTP client that uses the given TLS configuration
+// and root certificate authorities. The transport has the same settings as
+// http.DefaultTransport.
+func newHTTPClient(config *tls.Config, rootCAs *x509.CertPool) *http.Client {
+	if config == nil && rootCAs == nil {
+		return http.DefaultClient
+	}
+
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	if rootCAs != nil {
+		config.RootCAs = rootCAs
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSClientConfig:       config,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+// newReq returns a request for path that uses the options. Clients that
+// authenticate their requests add their own headers to it.
+func (o *clientOptions) newReq(path string) req {
 	return req{
-		hc:   c.hc,
-		addr: c.addr,
-		path: path,
+		clientOptions: *o,
+		path:          path,
 		headers: headers{
-			"User-Agent": c.userAgent(),
+			"User-Agent": o.userAgent(),
 		},
-		par:         params{},
-		environment: c.environment,
-		retryPolicy: c.retryPolicy,
+		par: params{},
 	}
 }
 
-func (c *Client) userAgent() string {
-	if c.ua == "" {
+func (o *clientOptions) userAgent() string {
+	if o.ua == "" {
 		return DefaultUserAgent
 	}
 
-	return DefaultUserAgent + " " + c.ua
+	return DefaultUserAgent + " " + o.ua
 }
 
 // WithApplicationKey creates an AppClient with the supplied application ID,
 // copying options set on the receiver.
 func (c *Client) WithApplicationKey(applicationKey string) *AppClient {
-	ac := NewAppClient(c.hc, c.addr, applicationKey)
-	ac.ua = c.ua
-	ac.environment = c.environment
-	ac.retryPolicy = c.retryPolicy
-	return ac
+	return newAppClient(c.clientOptions, applicationKey)
 }
 
 // WithDeveloperToken creates a DevClient with the supplied developer token,
 // copying options set on the receiver.
 func (c *Client) WithDeveloperToken(token string) *DevClient {
-	dc := NewDevClient(c.hc, c.addr, token)
-	dc.ua = c.ua
-	dc.environment = c.environment
-	dc.retryPolicy = c.retryPolicy
+	return newDevClient(c.clientOptions, token)
+}
+
+// DeveloperFromToken creates a DevClient for an existing session using a
+// session token saved from an earlier login, copying options set on the
+// receiver. It is equivalent to WithDeveloperToken.
+//
+// The token is not checked until the client is used and the client does not
+// know when the session expires, so SessionExpiresAt reports no expiry. If the
+// session has expired or been logged out then requests fail with an *Error
+// for which IsAuth reports true, and the developer must log in again.
+func (c *Client) DeveloperFromToken(token string) *DevClient {
+	return c.WithDeveloperToken(token)
+}
+
+// withDeveloperSession creates a DevClient for the session described by t,
+// copying options set on the receiver.
+func (c *Client) withDeveloperSession(t sessionToken) *DevClient {
+	dc := c.WithDeveloperToken(t.Token)
+	dc.expiresAt = t.ExpiresAt
+	dc.scopes = t.Scopes
 	return dc
 }
 
@@ -110,9 +212,12 @@ func (c *Client) WithDeveloperToken(token string) *DevClient {
 // API. Sending a successful request will return a new client that allows
 // access to services requiring a valid developer session.
 func (c *Client) Login(email, password string) *DeveloperLoginReq {
+	req := c.newReq(apiV1 + "/developers/login")
+	req.allowRetry = true
+
 	return &DeveloperLoginReq{
 		client: c,
-		req:    c.newReq(apiV1 + "/developers/login"),
+		req:    req,
 		data: DeveloperCredentials{
 			Email:    email,
 			Password: password,
@@ -126,8 +231,12 @@ type DeveloperLoginReq struct {
 	data   DeveloperCredentials
 }
 
+// sessionToken is the response to a successful developer login or creation.
+// Fields other than Token are optional and may not be sent by the API.
 type sessionToken struct {
-	Token string `json:"token"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -158,7 +267,7 @@ func (r *DeveloperLoginReq) Send() (*DevClient, error) {
 		return nil, decodeError(err, res)
 	}
 
-	return r.client.WithDeveloperToken(t.Token), nil
+	return r.client.withDeveloperSession(t), nil
 }
 
 // CreateDeveloper prepares and returns a request to create a developer account for the
@@ -209,11 +318,7 @@ func (r *DeveloperCreateReq) Send() (*DevClient, error) {
 		return nil, decodeError(err, res)
 	}
 
-	dc := NewDevClient(r.client.hc, r.client.addr, t.Token)
-	dc.ua = r.client.ua
-	dc.environment = r.client.environment
-	return dc, nil
-
+	return r.client.withDeveloperSession(t), nil
 }
 
 // LostPassword prepares and returns a request to start the lost password process.
@@ -262,8 +367,12 @@ func (r *LostPasswordReq) Send() error {
 
 // ResetPassword prepares and returns a request to reset a lost password.
 func (c *Client) ResetPassword(password string, token string) *ResetPasswordReq {
+	// Resetting with the same token is idempotent so the request may be retried
+	req := c.newReq(apiV1 + "/developers/reset_password")
+	req.allowRetry = true
+
 	return &ResetPasswordReq{
-		req: c.newReq(apiV1 + "/developers/reset_password"),
+		req: req,
 		data: developerPasswordReset{
 			Password: password,
 			Token:    token,
@@ -306,10 +415,19 @@ func (r *ResetPasswordReq) Send() error {
 	return nil
 }
 
+// WithUserAgent is a client option that may be used to add information to
+// the User-Agent header sent with every request. The information is appended to
+// DefaultUserAgent.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) { c.ua = ua }
+}
+
 // UserAgent is a client option that may be used to add information to the user agent header used by
 // the client.
+//
+// Deprecated: Use WithUserAgent instead.
 func UserAgent(ua string) ClientOption {
-	return func(c *Client) { c.ua = ua }
+	return WithUserAgent(ua)
 }
 
 // Environment is a client option that may be used to set the X-Environment header used by
@@ -324,3 +442,87 @@ func WithRetryPolicy(policy RetryPolicy) ClientOption {
 		c.retryPolicy = policy
 	}
 }
+
+// WithTLSConfig is a client option that may be used to set the TLS
+// configuration used to connect to the API, for example to present a client
+// certificate to a private deployment. It has no effect if an HTTP client is
+// supplied to New.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithRootCAs is a client option that may be used to set the root certificate
+// authorities trusted when connecting to the API, for example when the API is
+// reached through a TLS terminating proxy. It may be combined with
+// WithTLSConfig and has no effect if an HTTP client is supplied to New.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return func(c *Client) {
+		c.rootCAs = pool
+	}
+}
+
+// WithTransport is a client option that may be used to set the transport used
+// to send requests, for example to configure proxies or timeouts or to record
+// requests. If an HTTP client is supplied to New then a copy of it is used with
+// its transport replaced, otherwise WithTLSConfig and WithRootCAs have no
+// effect. Clients derived from the client, such as those returned by
+// WithApplicationKey or by logging in, use the same transport.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithRateLimit is a client option that limits the rate at which requests are
+// sent to rps requests per second, allowing bursts of up to burst requests. It
+// is intended for batch jobs that would otherwise exceed the API's rate limits.
+// Requests, including retries, wait until they may be sent or until their
+// context is cancelled. The limit is shared by the client and all clients
+// derived from it. A rate of zero or less disables the limit.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newTokenBucket(rps, burst)
+	}
+}
+
+// WithObserver is a client option that may be used to set a function that is
+// called after every attempt to send a request to the API, including retries.
+// It may be used to log requests or record metrics such as latency and status
+// codes. The function may be called concurrently by multiple goroutines.
+func WithObserver(observer func(RequestInfo)) ClientOption {
+	return func(c *Client) {
+		c.observer = observer
+	}
+}
+
+// Logger is the interface used by a client to log events, such as retries,
+// that are otherwise not visible to the caller. It is satisfied by
+// *testing.T.
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// WithLogger is a client option that may be used to set a logger that is
+// told about each retry of a failed request, including the attempt number,
+// the wait before the retry and the error that caused it. The logger may be
+// called concurrently by multiple goroutines.
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithClock is a client option that may be used to replace the clock used by
+// the client for time dependent behaviour such as waiting between retries. It
+// is intended for use in tests.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
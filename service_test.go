@@ -15,12 +15,17 @@
 package bosgo
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -121,6 +126,44 @@ func TestDeveloperLoginSuccess(t *testing.T) {
 	}
 }
 
+func TestDeveloperLoginSessionDetails(t *testing.T) {
+	routes := routeMap{
+		"/v1/developers/login": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, `{"token":"devtoken","expires_at":"2017-06-09T12:00:00Z","scopes":["applications","stats"]}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	client := New(hc, SandboxAddr)
+	devClient, err := client.Login("dev@example.com", "pwd").Send()
+
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	if devClient.SessionToken() != "devtoken" {
+		t.Errorf("got session token %q, wanted %q", devClient.SessionToken(), "devtoken")
+	}
+
+	expiresAt, ok := devClient.SessionExpiresAt()
+	if !ok {
+		t.Errorf("got no session expiry, wanted one")
+	}
+	if want := time.Date(2017, 6, 9, 12, 0, 0, 0, time.UTC); !expiresAt.Equal(want) {
+		t.Errorf("got session expiry %v, wanted %v", expiresAt, want)
+	}
+
+	if scopes := devClient.SessionScopes(); !reflect.DeepEqual(scopes, []string{"applications", "stats"}) {
+		t.Errorf("got session scopes %v, wanted [applications stats]", scopes)
+	}
+}
+
 func TestDeveloperLoginUnknown(t *testing.T) {
 	routes := routeMap{
 		"/v1/developers/login": {
@@ -241,6 +284,58 @@ func TestRetryGet(t *testing.T) {
 
 }
 
+type capturingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *capturingLogger) Logf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestRetryLogger(t *testing.T) {
+	const attempts = 4
+	handler := &transientErrorHandler{
+		retriesNeeded:   attempts,
+		successResponse: `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`,
+	}
+
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: handler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	logger := &capturingLogger{}
+	client := New(hc, SandboxAddr, WithLogger(logger), WithRetryPolicy(RetryPolicy{
+		MaxRetries: 10,
+		Wait:       100 * time.Microsecond,
+		MaxWait:    500 * time.Microsecond,
+	}))
+
+	_, err := client.WithApplicationKey("applicationkey").Providers.Search("foo").Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(logger.lines) != attempts-1 {
+		t.Fatalf("got %d log lines, wanted %d: %q", len(logger.lines), attempts-1, logger.lines)
+	}
+	for i, line := range logger.lines {
+		if want := fmt.Sprintf("(attempt %d)", i+2); !strings.Contains(line, want) {
+			t.Errorf("got log line %q, wanted it to contain %q", line, want)
+		}
+		if !strings.Contains(line, "retry_test_failure") || !strings.Contains(line, "status 500") {
+			t.Errorf("got log line %q, wanted it to contain the status and error code", line)
+		}
+	}
+}
+
 func TestRetryGetReturnsLastError(t *testing.T) {
 	handler := &transientErrorHandler{
 		retriesNeeded:   20,
@@ -336,3 +431,563 @@ func TestRetryPost(t *testing.T) {
 	}
 
 }
+
+func TestRetryDeveloperLogin(t *testing.T) {
+	handler := &transientErrorHandler{
+		retriesNeeded:   3,
+		successResponse: `{"token":"devtoken"}`,
+	}
+
+	routes := routeMap{
+		"/v1/developers/login": {
+			http.MethodPost: handler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	client := New(hc, SandboxAddr, WithRetryPolicy(RetryPolicy{
+		MaxRetries: 5,
+		Wait:       100 * time.Microsecond,
+		MaxWait:    500 * time.Microsecond,
+	}))
+
+	devClient, err := client.Login("username", "password").Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if devClient.SessionToken() != "devtoken" {
+		t.Errorf("got token %q, wanted devtoken", devClient.SessionToken())
+	}
+	if handler.retriesNeeded != 0 {
+		t.Errorf("got %d retries remaining, wanted 0", handler.retriesNeeded)
+	}
+}
+
+func TestRetryDeveloperPasswordRequests(t *testing.T) {
+	lostHandler := &transientErrorHandler{
+		retriesNeeded:   3,
+		successResponse: `{}`,
+	}
+	resetHandler := &transientErrorHandler{
+		retriesNeeded:   3,
+		successResponse: `{}`,
+	}
+
+	routes := routeMap{
+		"/v1/developers/lost_password": {
+			http.MethodPost: lostHandler.Handle,
+		},
+		"/v1/developers/reset_password": {
+			http.MethodPost: resetHandler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	client := New(hc, SandboxAddr, WithRetryPolicy(RetryPolicy{
+		MaxRetries: 5,
+		Wait:       100 * time.Microsecond,
+		MaxWait:    500 * time.Microsecond,
+	}))
+
+	// Request fails since retrying could send the lost password email more than once
+	if err := client.LostPassword("developer@example.com").Send(); err == nil {
+		t.Errorf("expected error but did not get one")
+	}
+	if lostHandler.retriesNeeded != 2 {
+		t.Errorf("got %d lost password attempts, wanted 1", 3-lostHandler.retriesNeeded)
+	}
+
+	// Request succeeds since resetting a password with a token may be retried
+	if err := client.ResetPassword("password", "token").Send(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var userAgents []string
+	recordUserAgent := func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	routes := routeMap{
+		"/v1/developers/logout": {
+			http.MethodPost: recordUserAgent,
+		},
+		"/v1/users/logout": {
+			http.MethodPost: recordUserAgent,
+		},
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				userAgents = append(userAgents, r.Header.Get("User-Agent"))
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	opt := WithUserAgent("myapp/1.0")
+
+	if err := NewDevClient(hc, SandboxAddr, "devtoken", opt).Logout().Send(); err != nil {
+		t.Fatalf("failed to send developer logout request: %v", err)
+	}
+	if err := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey", opt).Logout().Send(); err != nil {
+		t.Fatalf("failed to send user logout request: %v", err)
+	}
+	if _, err := NewAppClient(hc, SandboxAddr, "appkey", opt).Providers.Search("foo").Send(); err != nil {
+		t.Fatalf("failed to send provider search request: %v", err)
+	}
+	if err := NewDevClient(hc, SandboxAddr, "devtoken").Logout().Send(); err != nil {
+		t.Fatalf("failed to send developer logout request: %v", err)
+	}
+
+	want := []string{
+		DefaultUserAgent + " myapp/1.0",
+		DefaultUserAgent + " myapp/1.0",
+		DefaultUserAgent + " myapp/1.0",
+		DefaultUserAgent,
+	}
+	if !reflect.DeepEqual(userAgents, want) {
+		t.Errorf("got user agents %q, wanted %q", userAgents, want)
+	}
+}
+
+// pathRecordingTransport records the path of every request before passing it
+// to the next transport.
+type pathRecordingTransport struct {
+	mu    sync.Mutex
+	paths []string
+	next  http.RoundTripper
+}
+
+func (rt *pathRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.paths = append(rt.paths, req.URL.Path)
+	rt.mu.Unlock()
+	return rt.next.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	routes := routeMap{
+		"/v1/developers/login": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `{"token":"devtoken"}`)
+			},
+		},
+		"/v1/developers/logout": {
+			http.MethodPost: noContentHandler,
+		},
+		"/v1/users/login": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `{"id":"uid","token":"usertoken"}`)
+			},
+		},
+		"/v1/users/logout": {
+			http.MethodPost: noContentHandler,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	rt := &pathRecordingTransport{next: hc.Transport}
+	client := New(&http.Client{Timeout: time.Minute}, SandboxAddr, WithTransport(rt))
+
+	devClient, err := client.Login("developer@example.com", "password").Send()
+	if err != nil {
+		t.Fatalf("failed to login developer: %v", err)
+	}
+	if err := devClient.Logout().Send(); err != nil {
+		t.Fatalf("failed to logout developer: %v", err)
+	}
+
+	userClient, err := client.WithApplicationKey("appkey").Users.Login("username", "password").Send()
+	if err != nil {
+		t.Fatalf("failed to login user: %v", err)
+	}
+	if err := userClient.Logout().Send(); err != nil {
+		t.Fatalf("failed to logout user: %v", err)
+	}
+
+	want := []string{"/v1/developers/login", "/v1/developers/logout", "/v1/users/login", "/v1/users/logout"}
+	if !reflect.DeepEqual(rt.paths, want) {
+		t.Errorf("got paths %v, wanted %v", rt.paths, want)
+	}
+}
+
+// fakeClock is a Clock that never blocks and records the waits requested of it.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestRetryUsesClock(t *testing.T) {
+	handler := &transientErrorHandler{
+		retriesNeeded:   3,
+		successResponse: `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`,
+	}
+
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: handler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	policy := RetryPolicy{
+		MaxRetries: 10,
+		Wait:       time.Hour,
+		MaxWait:    10 * time.Hour,
+		Multiplier: 2,
+	}
+
+	clock := &fakeClock{}
+	client := New(hc, SandboxAddr, WithRetryPolicy(policy), WithClock(clock))
+	appClient := client.WithApplicationKey("applicationkey")
+
+	_, err := appClient.Providers.Search("foo").Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []time.Duration{time.Hour, 2 * time.Hour}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf("got waits %v, wanted %v", clock.waits, expected)
+	}
+}
+
+func TestRetryHonorsContext(t *testing.T) {
+	handler := &transientErrorHandler{
+		retriesNeeded: 100,
+	}
+
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: handler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	policy := RetryPolicy{
+		MaxRetries: 10,
+		Wait:       time.Hour,
+		MaxWait:    time.Hour,
+	}
+
+	client := New(hc, SandboxAddr, WithRetryPolicy(policy))
+	appClient := client.WithApplicationKey("applicationkey")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := appClient.Providers.Search("foo").Context(ctx).Send()
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v to return after context expired", elapsed)
+	}
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	testCases := []struct {
+		retryAfter string
+		wantWait   time.Duration
+	}{
+		{retryAfter: "1", wantWait: time.Second},
+		{retryAfter: "120", wantWait: 10 * time.Second}, // limited by MaxWait
+		{retryAfter: "", wantWait: time.Millisecond},    // falls back to the policy
+	}
+
+	for _, tc := range testCases {
+		var requests int
+		routes := routeMap{
+			"/v1/providers": {
+				http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					w.Header().Set("Content-Type", "application/json; charset=utf-8")
+					if requests == 1 {
+						if tc.retryAfter != "" {
+							w.Header().Set("Retry-After", tc.retryAfter)
+						}
+						w.WriteHeader(http.StatusTooManyRequests)
+						fmt.Fprint(w, `{"errors":[{"code":"rate_limit_exceeded"}]}`)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					fmt.Fprint(w, `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`)
+				},
+			},
+		}
+
+		hc, cleanup := startTestServer(t, routes)
+
+		policy := RetryPolicy{
+			MaxRetries: 3,
+			Wait:       time.Millisecond,
+			MaxWait:    10 * time.Second,
+		}
+
+		clock := &fakeClock{}
+		client := New(hc, SandboxAddr, WithRetryPolicy(policy), WithClock(clock))
+		appClient := client.WithApplicationKey("applicationkey")
+
+		_, err := appClient.Providers.Search("foo").Send()
+		cleanup()
+		if err != nil {
+			t.Errorf("Retry-After %q: unexpected error: %v", tc.retryAfter, err)
+			continue
+		}
+		if requests != 2 {
+			t.Errorf("Retry-After %q: got %d requests, wanted 2", tc.retryAfter, requests)
+		}
+		if want := []time.Duration{tc.wantWait}; !reflect.DeepEqual(clock.waits, want) {
+			t.Errorf("Retry-After %q: got waits %v, wanted %v", tc.retryAfter, clock.waits, want)
+		}
+	}
+}
+
+func TestWithObserver(t *testing.T) {
+	handler := &transientErrorHandler{
+		retriesNeeded:   3,
+		successResponse: `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`,
+	}
+
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: handler.Handle,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	var mu sync.Mutex
+	var infos []RequestInfo
+	observer := func(info RequestInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		infos = append(infos, info)
+	}
+
+	client := New(hc, SandboxAddr, WithRetryPolicy(RetryPolicy{MaxRetries: 5}), WithClock(&fakeClock{}), WithObserver(observer))
+	appClient := client.WithApplicationKey("applicationkey")
+
+	if _, err := appClient.Providers.Search("foo").Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantStatus := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK}
+	if len(infos) != len(wantStatus) {
+		t.Fatalf("got %d observed attempts, wanted %d", len(infos), len(wantStatus))
+	}
+	for i, info := range infos {
+		if info.Attempt != i+1 {
+			t.Errorf("attempt %d: got attempt number %d", i+1, info.Attempt)
+		}
+		if info.StatusCode != wantStatus[i] {
+			t.Errorf("attempt %d: got status code %d, wanted %d", i+1, info.StatusCode, wantStatus[i])
+		}
+		if info.Method != http.MethodGet {
+			t.Errorf("attempt %d: got method %s, wanted GET", i+1, info.Method)
+		}
+		if info.Path != "/v1/providers" {
+			t.Errorf("attempt %d: got path %s, wanted /v1/providers", i+1, info.Path)
+		}
+	}
+}
+
+func TestLastRequestID(t *testing.T) {
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-1234")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	appClient := New(hc, SandboxAddr).WithApplicationKey("applicationkey")
+
+	req := appClient.Providers.Search("foo")
+	if id := req.LastRequestID(); id != "" {
+		t.Errorf("got request id %q before sending, wanted empty", id)
+	}
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := req.LastRequestID(); id != "req-1234" {
+		t.Errorf("got request id %q, wanted req-1234", id)
+	}
+}
+
+func TestLastRequestIDAfterRetry(t *testing.T) {
+	attempts := 0
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.Header().Set("X-Request-Id", fmt.Sprintf("req-%d", attempts))
+				if attempts == 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprint(w, `[]`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	appClient := New(hc, SandboxAddr, WithRetryPolicy(RetryPolicy{MaxRetries: 1}), WithClock(&fakeClock{})).WithApplicationKey("applicationkey")
+
+	req := appClient.Providers.Search("foo")
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := req.LastRequestID(); id != "req-2" {
+		t.Errorf("got request id %q, wanted req-2", id)
+	}
+}
+
+func TestErrorPayload(t *testing.T) {
+	routes := routeMap{
+		"/v1/developers/login": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":[{"code":"validation_bad_parameters","message":"invalid fields","payload":{"field_key":["email","password"]}}]}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	client := New(hc, SandboxAddr)
+	_, err := client.Login("not an email", "").Send()
+	berr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("got error %v, wanted *Error", err)
+	}
+	if len(berr.Errors) != 1 {
+		t.Fatalf("got %d error items, wanted 1", len(berr.Errors))
+	}
+
+	want := ErrorItem{
+		Code:    "validation_bad_parameters",
+		Message: "invalid fields",
+		Payload: map[string][]string{"field_key": {"email", "password"}},
+	}
+	if !reflect.DeepEqual(berr.Errors[0], want) {
+		t.Errorf("got error item %+v, wanted %+v", berr.Errors[0], want)
+	}
+}
+
+func TestRetrySkipsNotImplemented(t *testing.T) {
+	var requests int
+	routes := routeMap{
+		"/v1/providers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"errors":[{"code":"not_implemented_by_test_server"}]}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	policy := RetryPolicy{
+		MaxRetries: 10,
+		Wait:       100 * time.Microsecond,
+		MaxWait:    500 * time.Microsecond,
+	}
+
+	client := New(hc, SandboxAddr, WithRetryPolicy(policy))
+	appClient := client.WithApplicationKey("applicationkey")
+
+	_, err := appClient.Providers.Search("foo").Send()
+	if !IsNotImplemented(err) {
+		t.Errorf("got IsNotImplemented false for %v, wanted true", err)
+	}
+	if wrapped := fmt.Errorf("searching providers: %w", err); !IsNotImplemented(wrapped) {
+		t.Errorf("got IsNotImplemented false for %v, wanted true", wrapped)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, wanted 1", requests)
+	}
+}
+
+func TestWithRootCAs(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"score":1, "provider":{"id":"DE-BIN-10001000"}}]`)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("failed to parse httptest.Server URL: %v", err)
+	}
+
+	// The server's self-signed certificate is not trusted by default
+	_, err = New(nil, u.Host, WithTLSConfig(&tls.Config{})).WithApplicationKey("applicationkey").Providers.Search("foo").Send()
+	if err == nil {
+		t.Fatalf("got no error, wanted certificate verification failure")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+
+	_, err = New(nil, u.Host, WithRootCAs(pool)).WithApplicationKey("applicationkey").Providers.Search("foo").Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Root CAs are combined with an explicit TLS configuration
+	_, err = New(nil, u.Host, WithRootCAs(pool), WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})).WithApplicationKey("applicationkey").Providers.Search("foo").Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
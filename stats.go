@@ -3,10 +3,13 @@ package bosgo
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 )
 
+// DefaultStatsEnvironment is the environment whose statistics are requested
+// when neither a stats request nor its client sets one.
+const DefaultStatsEnvironment = "sandbox"
+
 // StatsService provides access to statistic related API services.
 type StatsService struct {
 	client *DevClient
@@ -48,9 +51,17 @@ func (r *StatsMerchantsReq) ToDate(date time.Time) *StatsMerchantsReq {
 	return r
 }
 
+// Environment sets the environment, such as "sandbox" or "production", whose
+// statistics are requested. If no environment is set then the statistics
+// for the client's environment, or DefaultStatsEnvironment if it has none,
+// are requested.
+func (r *StatsMerchantsReq) Environment(env string) *StatsMerchantsReq {
+	r.req.par.Set("environment", env)
+	return r
+}
+
 func (r *StatsMerchantsReq) Send() (*MerchantsStats, error) {
-	// TODO: remove environment parameter
-	r.req.par.Set("environment", "sandbox")
+	r.req.setStatsEnvironment()
 
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -100,9 +111,17 @@ func (r *StatsProvidersReq) ToDate(date time.Time) *StatsProvidersReq {
 	return r
 }
 
+// Environment sets the environment, such as "sandbox" or "production", whose
+// statistics are requested. If no environment is set then the statistics
+// for the client's environment, or DefaultStatsEnvironment if it has none,
+// are requested.
+func (r *StatsProvidersReq) Environment(env string) *StatsProvidersReq {
+	r.req.par.Set("environment", env)
+	return r
+}
+
 func (r *StatsProvidersReq) Send() (*ProvidersStats, error) {
-	// TODO: remove environment parameter
-	r.req.par.Set("environment", "sandbox")
+	r.req.setStatsEnvironment()
 
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -152,9 +171,17 @@ func (r *StatsTransfersReq) ToDate(date time.Time) *StatsTransfersReq {
 	return r
 }
 
-func (r *StatsTransfersReq) Send() (interface{}, error) {
-	// TODO: remove environment parameter
-	r.req.par.Set("environment", "sandbox")
+// Environment sets the environment, such as "sandbox" or "production", whose
+// statistics are requested. If no environment is set then the statistics
+// for the client's environment, or DefaultStatsEnvironment if it has none,
+// are requested.
+func (r *StatsTransfersReq) Environment(env string) *StatsTransfersReq {
+	r.req.par.Set("environment", env)
+	return r
+}
+
+func (r *StatsTransfersReq) Send() (*TransfersStats, error) {
+	r.req.setStatsEnvironment()
 
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -162,14 +189,12 @@ func (r *StatsTransfersReq) Send() (interface{}, error) {
 		return nil, err
 	}
 
-	var stats interface{}
+	var stats TransfersStats
 	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
 		return nil, decodeError(err, res)
 	}
 
-	fmt.Printf("%+v\n", stats)
-
-	return stats, nil
+	return &stats, nil
 }
 
 func (d *StatsService) Users() *StatsUsersReq {
@@ -206,9 +231,17 @@ func (r *StatsUsersReq) ToDate(date time.Time) *StatsUsersReq {
 	return r
 }
 
+// Environment sets the environment, such as "sandbox" or "production", whose
+// statistics are requested. If no environment is set then the statistics
+// for the client's environment, or DefaultStatsEnvironment if it has none,
+// are requested.
+func (r *StatsUsersReq) Environment(env string) *StatsUsersReq {
+	r.req.par.Set("environment", env)
+	return r
+}
+
 func (r *StatsUsersReq) Send() (*UsersStats, error) {
-	// TODO: remove environment parameter
-	r.req.par.Set("environment", "sandbox")
+	r.req.setStatsEnvironment()
 
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -258,9 +291,17 @@ func (r *StatsRequestsReq) ToDate(date time.Time) *StatsRequestsReq {
 	return r
 }
 
+// Environment sets the environment, such as "sandbox" or "production", whose
+// statistics are requested. If no environment is set then the statistics
+// for the client's environment, or DefaultStatsEnvironment if it has none,
+// are requested.
+func (r *StatsRequestsReq) Environment(env string) *StatsRequestsReq {
+	r.req.par.Set("environment", env)
+	return r
+}
+
 func (r *StatsRequestsReq) Send() (*RequestsStats, error) {
-	// TODO: remove environment parameter
-	r.req.par.Set("environment", "sandbox")
+	r.req.setStatsEnvironment()
 
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -275,3 +316,17 @@ func (r *StatsRequestsReq) Send() (*RequestsStats, error) {
 
 	return &stats, nil
 }
+
+// setStatsEnvironment sets the environment parameter to the client's
+// environment, or DefaultStatsEnvironment if the client has none, unless the
+// request has already set one.
+func (r *req) setStatsEnvironment() {
+	if r.par.Get("environment") != "" {
+		return
+	}
+	if r.environment != "" {
+		r.par.Set("environment", r.environment)
+		return
+	}
+	r.par.Set("environment", DefaultStatsEnvironment)
+}
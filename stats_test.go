@@ -0,0 +1,100 @@
+package bosgo
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStatsTransfers(t *testing.T) {
+	routes := routeMap{
+		"/v1/stats/transfers": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("from_date"); got != "2017-07-01" {
+					t.Errorf("got from_date %q, wanted 2017-07-01", got)
+				}
+				if got := r.URL.Query().Get("to_date"); got != "2017-07-02" {
+					t.Errorf("got to_date %q, wanted 2017-07-02", got)
+				}
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, `{"from_date":"2017-07-01","to_date":"2017-07-02","domain":"transfers",`+
+					`"total_out":[{"value":150.5,"currency":"EUR"}],"today_out":[{"value":0,"currency":"EUR"}],`+
+					`"stats":[{"date":"2017-07-01","out":[{"value":100,"currency":"EUR"}]},{"date":"2017-07-02","out":[{"value":50.5,"currency":"EUR"}]}]}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	devClient := NewDevClient(hc, SandboxAddr, "devtoken")
+	stats, err := devClient.Stats.Transfers().
+		FromDate(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)).
+		ToDate(time.Date(2017, 7, 2, 0, 0, 0, 0, time.UTC)).
+		Send()
+	if err != nil {
+		t.Fatalf("failed to send transfer stats request: %v", err)
+	}
+
+	if len(stats.TotalOut) != 1 || stats.TotalOut[0] != (StatsMoneyAmount{Value: 150.5, Currency: "EUR"}) {
+		t.Errorf("got total out %+v, wanted 150.5 EUR", stats.TotalOut)
+	}
+	if len(stats.Stats) != 2 {
+		t.Fatalf("got %d daily stats, wanted 2", len(stats.Stats))
+	}
+	if stats.Stats[1].Date != "2017-07-02" {
+		t.Errorf("got date %q, wanted 2017-07-02", stats.Stats[1].Date)
+	}
+	if len(stats.Stats[1].Out) != 1 || stats.Stats[1].Out[0].Value != 50.5 {
+		t.Errorf("got daily out %+v, wanted 50.5 EUR", stats.Stats[1].Out)
+	}
+}
+
+func TestStatsEnvironment(t *testing.T) {
+	var got string
+	routes := routeMap{
+		"/v1/stats/users": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Query().Get("environment")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, `{}`)
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	devClient := NewDevClient(hc, SandboxAddr, "devtoken")
+
+	if _, err := devClient.Stats.Users().Send(); err != nil {
+		t.Fatalf("failed to send user stats request: %v", err)
+	}
+	if got != DefaultStatsEnvironment {
+		t.Errorf("got environment %q, wanted %q", got, DefaultStatsEnvironment)
+	}
+
+	if _, err := devClient.Stats.Users().Environment("production").Send(); err != nil {
+		t.Fatalf("failed to send user stats request: %v", err)
+	}
+	if got != "production" {
+		t.Errorf("got environment %q, wanted production", got)
+	}
+	devClient = NewDevClient(hc, SandboxAddr, "devtoken", Environment("staging"))
+	if _, err := devClient.Stats.Users().Send(); err != nil {
+		t.Fatalf("failed to send user stats request: %v", err)
+	}
+	if got != "staging" {
+		t.Errorf("got environment %q, wanted client environment staging", got)
+	}
+
+	if _, err := devClient.Stats.Users().Environment("production").Send(); err != nil {
+		t.Fatalf("failed to send user stats request: %v", err)
+	}
+	if got != "production" {
+		t.Errorf("got environment %q, wanted production", got)
+	}
+}
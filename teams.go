@@ -0,0 +1,267 @@
+package bosgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// TeamsService provides access to team related API services that also
+// require an authenticated developer session.
+type TeamsService struct {
+	client *DevClient
+}
+
+func NewTeamsService(c *DevClient) *TeamsService {
+	return &TeamsService{client: c}
+}
+
+// List returns a request that may be used to list the teams the developer belongs to.
+func (d *TeamsService) List() *ListTeamsReq {
+	return &ListTeamsReq{
+		req: d.client.newReq(apiV1 + "/developers/teams"),
+	}
+}
+
+type ListTeamsReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ListTeamsReq) Context(ctx context.Context) *ListTeamsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListTeamsReq) ClientID(id string) *ListTeamsReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to list teams.
+func (r *ListTeamsReq) Send() ([]Team, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []Team
+	if err := json.NewDecoder(res.Body).Decode(&teams); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return teams, nil
+}
+
+// Create returns a request that may be used to create a new team owned by the developer.
+func (d *TeamsService) Create(team TeamNew) *CreateTeamReq {
+	return &CreateTeamReq{
+		req:  d.client.newReq(apiV1 + "/developers/teams"),
+		data: team,
+	}
+}
+
+type CreateTeamReq struct {
+	req
+	data TeamNew
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *CreateTeamReq) Context(ctx context.Context) *CreateTeamReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *CreateTeamReq) ClientID(id string) *CreateTeamReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to create the team.
+func (r *CreateTeamReq) Send() (*Team, error) {
+	res, cleanup, err := r.req.postJSON(&r.data)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var team Team
+	if err := json.NewDecoder(res.Body).Decode(&team); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &team, nil
+}
+
+// Invite returns a request that may be used to invite a developer to join a team.
+// The invited developer is sent an invite token by email which they must accept
+// using AcceptInvite.
+func (d *TeamsService) Invite(teamID string, email string) *InviteTeamMemberReq {
+	return &InviteTeamMemberReq{
+		req:  d.client.newReq(apiV1 + "/developers/teams/" + url.PathEscape(teamID) + "/invite"),
+		data: TeamInvite{Email: email},
+	}
+}
+
+type InviteTeamMemberReq struct {
+	req
+	data TeamInvite
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *InviteTeamMemberReq) Context(ctx context.Context) *InviteTeamMemberReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *InviteTeamMemberReq) ClientID(id string) *InviteTeamMemberReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to invite the developer.
+func (r *InviteTeamMemberReq) Send() error {
+	_, cleanup, err := r.req.postJSON(&r.data)
+	defer cleanup()
+	return err
+}
+
+// AcceptInvite returns a request that may be used to accept an invite to join a team.
+func (d *TeamsService) AcceptInvite(token string) *AcceptTeamInviteReq {
+	return &AcceptTeamInviteReq{
+		req:  d.client.newReq(apiV1 + "/developers/teams/invite/accept"),
+		data: TeamInviteToken{Token: token},
+	}
+}
+
+type AcceptTeamInviteReq struct {
+	req
+	data TeamInviteToken
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *AcceptTeamInviteReq) Context(ctx context.Context) *AcceptTeamInviteReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *AcceptTeamInviteReq) ClientID(id string) *AcceptTeamInviteReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to accept the invite. If the response is not
+// successful then its Step describes what the developer must do next.
+func (r *AcceptTeamInviteReq) Send() (*TeamInviteResponse, error) {
+	res, cleanup, err := r.req.postJSON(&r.data)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var ir TeamInviteResponse
+	if err := json.NewDecoder(res.Body).Decode(&ir); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &ir, nil
+}
+
+// ListMembers returns a request that may be used to list the members of a team.
+func (d *TeamsService) ListMembers(teamID string) *ListTeamMembersReq {
+	return &ListTeamMembersReq{
+		req: d.client.newReq(apiV1 + "/developers/teams/" + url.PathEscape(teamID) + "/members"),
+	}
+}
+
+type ListTeamMembersReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ListTeamMembersReq) Context(ctx context.Context) *ListTeamMembersReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListTeamMembersReq) ClientID(id string) *ListTeamMembersReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to list team members.
+func (r *ListTeamMembersReq) Send() ([]TeamMember, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var members []TeamMember
+	if err := json.NewDecoder(res.Body).Decode(&members); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return members, nil
+}
+
+// UpdateMemberAccess returns a request that may be used to change the access
+// levels a team member has to the team's resources.
+func (d *TeamsService) UpdateMemberAccess(teamID string, memberID string, access []TeamMemberNewAccess) *UpdateTeamMemberAccessReq {
+	return &UpdateTeamMemberAccessReq{
+		req:  d.client.newReq(apiV1 + "/developers/teams/" + url.PathEscape(teamID) + "/members/" + url.PathEscape(memberID) + "/access"),
+		data: TeamMemberUpdateAccess{Accesses: access},
+	}
+}
+
+type UpdateTeamMemberAccessReq struct {
+	req
+	data TeamMemberUpdateAccess
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *UpdateTeamMemberAccessReq) Context(ctx context.Context) *UpdateTeamMemberAccessReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *UpdateTeamMemberAccessReq) ClientID(id string) *UpdateTeamMemberAccessReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to update the member's access and returns the
+// member's resulting access levels.
+func (r *UpdateTeamMemberAccessReq) Send() ([]TeamMemberAccess, error) {
+	res, cleanup, err := r.req.putJSON(&r.data)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var accesses []TeamMemberAccess
+	if err := json.NewDecoder(res.Body).Decode(&accesses); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return accesses, nil
+}
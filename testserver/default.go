@@ -13,23 +13,63 @@ const (
 	ChallengePassword   = "password"
 	ChallengeTAN        = "tan"
 
-	DefaultDeveloperID    = "default-dev"
-	DefaultApplicationKey = "default-app"
-	DefaultUserID         = "default-user"
-	DefaultUsername       = "username@example.com"
-	DefaultPassword       = "password"
-	DefaultProviderID     = "def-provider-id"
-	DefaultAccessLogin    = "user"
-	DefaultAccessPIN      = "1234"
-	DefaultAuthMethod     = "901"
-	DefaultAuthMessage    = "tan challenge - (enter 4321 as tan)"
-	DefaultAuthAnswer     = "4321"
+	DefaultDeveloperID       = "default-dev"
+	DefaultDeveloperEmail    = "developer@example.com"
+	DefaultDeveloperPassword = "password"
+	DefaultApplicationKey    = "default-app"
+	DefaultUserID            = "default-user"
+	DefaultUsername          = "username@example.com"
+	DefaultPassword          = "password"
+	DefaultProviderID        = "def-provider-id"
+	EmptyProviderID          = "empty-provider-id" // provider whose access imports no accounts
+	DefaultAccessLogin       = "user"
+	DefaultAccessPIN         = "1234"
+	DefaultAuthMethod        = "901"
+	DefaultAuthMessage       = "tan challenge - (enter 4321 as tan)"
+	DefaultAuthAnswer        = "4321"
 )
 
+// StatementPDF is the document served by the test server for every account
+// statement request. It is a minimal but well formed PDF.
+var StatementPDF = []byte("%PDF-1.4\n1 0 obj<</Type/Catalog/Pages 2 0 R>>endobj\n2 0 obj<</Type/Pages/Kids[]/Count 0>>endobj\ntrailer<</Root 1 0 R>>\n%%EOF\n")
+
+// KnownBanks holds the bank details returned by IBAN validation, indexed by
+// the German bank code (BLZ) embedded in the IBAN.
+var KnownBanks = map[string]bosgo.IBANBank{
+	"20041111": {
+		ID:             "COBADEHDXXX",
+		Label:          "comdirect bank",
+		Country:        "DE",
+		Provider:       "BIC",
+		ServiceContext: "SEPA",
+	},
+	"37040044": {
+		ID:             "COBADEFFXXX",
+		Label:          "Commerzbank",
+		Country:        "DE",
+		Provider:       "BIC",
+		ServiceContext: "SEPA",
+	},
+}
+
+// DefaultCategories holds the transaction categories known to a server
+// created by NewWithDefaults.
+var DefaultCategories = []bosgo.Category{
+	{ID: 1, Names: map[string]string{"de": "Bargeld", "en": "Cash"}, Group: "SPENDING"},
+	{ID: 2, Names: map[string]string{"de": "Lebensmittel", "en": "Groceries"}, Group: "SPENDING"},
+	{ID: 3, Names: map[string]string{"de": "Gehalt", "en": "Salary"}, Group: "INCOME"},
+}
+
 // NewWithDefaults creates a new test server with a default developer, application and user account
 func NewWithDefaults() *Server {
 	s := New()
 
+	s.setDev(Dev{
+		ID:       DefaultDeveloperID,
+		Email:    DefaultDeveloperEmail,
+		Password: DefaultDeveloperPassword,
+	})
+
 	app := App{
 		ID:          DefaultApplicationKey,
 		DeveloperID: DefaultDeveloperID,
@@ -201,12 +241,99 @@ func NewWithDefaults() *Server {
 	}
 	s.AddAccess(ad)
 
+	s.AddProvider(bosgo.Provider{
+		ID:          DefaultProviderID,
+		Name:        "Default Bank",
+		Description: "Provider of the default access",
+		Country:     "DE",
+		Operations: bosgo.ProviderOperations{
+			AllowedOperations: bosgo.ProviderAllowedOperations{
+				PaymentTransfer:  true,
+				AccountStatement: true,
+				AccountBalance:   true,
+			},
+		},
+		Challenges: []bosgo.ChallengeSpec{
+			{
+				ID:          ChallengeLogin,
+				Description: "Login",
+				Type:        bosgo.ChallengeTypeAlphaNumeric,
+			},
+			{
+				ID:          ChallengePIN,
+				Description: "PIN",
+				Type:        bosgo.ChallengeTypeNumeric,
+				Secure:      true,
+			},
+		},
+	})
+
+	for _, c := range DefaultCategories {
+		s.AddCategory(c)
+	}
+
+	empty := s.MakeAccess(EmptyProviderID, "access without accounts")
+	empty.Accounts = nil
+	s.AddAccess(AccessDetails{
+		Access: *empty,
+		ChallengeMap: map[string]string{
+			ChallengeLogin: DefaultAccessLogin,
+			ChallengePIN:   DefaultAccessPIN,
+		},
+	})
+
 	return s
 }
 
+// AddUserWithAccess adds a user of the default application with the given
+// credentials. The user is given an access to the provider, made by MakeAccess
+// and refreshed as though the user had added it, together with a transaction
+// on each of its accounts. The username must not already be in use. It
+// returns the IDs of the new user and access.
+func (s *Server) AddUserWithAccess(username, password, providerID string) (userID string, accessID int64) {
+	access := s.MakeAccess(providerID, "access of "+username)
+	access.LastRefreshedAt = time.Now()
+	access.RefreshStatus = bosgo.RefreshStatusOK
+
+	var txs []bosgo.Transaction
+	for i, account := range access.Accounts {
+		date := time.Date(2017, 7, 31-i, 0, 0, 0, 0, time.UTC)
+		txs = append(txs, bosgo.Transaction{
+			ID:            s.nextID(),
+			AccessID:      access.ID,
+			UserAccountID: account.ID,
+			UserAccount: bosgo.AccountRef{
+				ProviderID: providerID,
+				IBAN:       account.IBAN,
+			},
+			Amount: &bosgo.MoneyAmount{
+				Currency: account.Currency,
+				Value:    "-10.00",
+			},
+			EntryDate:      date,
+			SettlementDate: date,
+			Usage:          "Goods bought",
+			Counterparty:   bosgo.Counterparty{},
+		})
+	}
+
+	user := User{
+		ID:            s.nextSpaceIDStr(IDSpaceUsers),
+		Username:      username,
+		Password:      password,
+		ApplicationID: DefaultApplicationKey,
+		Accesses:      []bosgo.Access{*access},
+		Transactions:  txs,
+		StoredAnswers: map[string][]bosgo.ChallengeAnswer{},
+	}
+	s.SetUser(user)
+
+	return user.ID, access.ID
+}
+
 // MakeAccess makes an access with an account
 func (s *Server) MakeAccess(providerID, name string) *bosgo.Access {
-	accID := s.nextID()
+	accID := s.nextSpaceID(IDSpaceAccesses)
 	acc := bosgo.Access{
 		ID:         accID,
 		ProviderID: providerID,
@@ -215,7 +342,7 @@ func (s *Server) MakeAccess(providerID, name string) *bosgo.Access {
 
 		Accounts: []bosgo.Account{
 			{
-				ID:               s.nextID(),
+				ID:               s.nextSpaceID(IDSpaceAccounts),
 				ProviderID:       providerID,
 				BankAccessID:     accID,
 				Name:             "Account 1",
@@ -234,7 +361,7 @@ func (s *Server) MakeAccess(providerID, name string) *bosgo.Access {
 				},
 			},
 			{
-				ID:               s.nextID(),
+				ID:               s.nextSpaceID(IDSpaceAccounts),
 				ProviderID:       providerID,
 				BankAccessID:     accID,
 				Name:             "Account 2",
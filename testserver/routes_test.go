@@ -0,0 +1,292 @@
+package testserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"code.bankrs.com/bosgo"
+)
+
+// routeExclusions lists the client requests that the test server does not
+// implement yet, indexed by the client method that creates the request.
+var routeExclusions = map[string]bool{
+	"Client.LostPassword":                     true,
+	"Client.ResetPassword":                    true,
+	"DevClient.Applications.CreateCredential": true,
+	"DevClient.Applications.ListCredentials":  true,
+	"DevClient.Applications.ResetUsers":       true,
+	"DevClient.Applications.Settings":         true,
+	"DevClient.Applications.UpdateSettings":   true,
+	"DevClient.Applications.UserInfo":         true,
+	"DevClient.ChangePassword":                true,
+	"DevClient.Credentials.Delete":            true,
+	"DevClient.Credentials.Get":               true,
+	"DevClient.Credentials.ListProviders":     true,
+	"DevClient.Credentials.Update":            true,
+	"DevClient.LinkedTeams":                   true,
+	"DevClient.Profile":                       true,
+	"DevClient.SetProfile":                    true,
+	"DevClient.Stats.Merchants":               true,
+	"DevClient.Stats.Providers":               true,
+	"DevClient.Stats.Requests":                true,
+	"DevClient.Stats.Transfers":               true,
+	"DevClient.Stats.Users":                   true,
+	"DevClient.Webhooks.Create":               true,
+	"DevClient.Webhooks.Delete":               true,
+	"DevClient.Webhooks.Get":                  true,
+	"DevClient.Webhooks.List":                 true,
+	"DevClient.Webhooks.Test":                 true,
+	"DevClient.Webhooks.Update":               true,
+	"UserClient.Consents.Get":                 true,
+	"UserClient.ScheduledTransactions.Get":    true,
+	"UserClient.Transactions.Get":             true,
+}
+
+// routeArgs holds arguments for client methods whose parameters cannot be
+// satisfied by placeholder values, indexed by client method.
+var routeArgs = map[string][]interface{}{
+	"DevClient.Applications.Update":           {DefaultApplicationKey},
+	"DevClient.Applications.Delete":           {DefaultApplicationKey},
+	"DevClient.Applications.ListKeys":         {DefaultApplicationKey},
+	"DevClient.Applications.CreateKey":        {DefaultApplicationKey},
+	"DevClient.Applications.DeleteKey":        {DefaultApplicationKey},
+	"DevClient.Applications.ListUsers":        {DefaultApplicationKey},
+	"DevClient.Applications.UserInfo":         {DefaultApplicationKey, DefaultUserID},
+	"DevClient.Applications.ResetUsers":       {DefaultApplicationKey},
+	"DevClient.Applications.Settings":         {DefaultApplicationKey},
+	"DevClient.Applications.UpdateSettings":   {DefaultApplicationKey},
+	"DevClient.Applications.CreateCredential": {DefaultApplicationKey},
+	"DevClient.Applications.ListCredentials":  {DefaultApplicationKey},
+	"DevClient.ApplicationKeys.Delete":        {DefaultApplicationKey},
+	"UserClient.Jobs.Get":                     {"/jobs/1"},
+	"UserClient.Jobs.Answer":                  {"/jobs/1"},
+	"UserClient.Jobs.Cancel":                  {"/jobs/1"},
+}
+
+// recordingTransport records the requests made through it and responds to
+// every request with 404 Not Found.
+type recordingTransport struct {
+	mu   sync.Mutex
+	reqs []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	rec := httptest.NewRequest(req.Method, req.URL.String(), bytes.NewReader(body))
+	for k, v := range req.Header {
+		rec.Header[k] = v
+	}
+
+	rt.mu.Lock()
+	rt.reqs = append(rt.reqs, rec)
+	rt.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 Not Found",
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func (rt *recordingTransport) take() []*http.Request {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	reqs := rt.reqs
+	rt.reqs = nil
+	return reqs
+}
+
+func TestRoutesCoverClient(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+
+	devToken := s.setDevLoggedIn(DefaultDeveloperID)
+	userToken := s.setUserLoggedIn(DefaultUserID)
+
+	// Every request is handled by a server in the same state so that
+	// requests that change it do not affect the others
+	var state bytes.Buffer
+	if err := s.WriteState(&state); err != nil {
+		t.Fatalf("unexpected error writing state: %v", err)
+	}
+
+	rt := &recordingTransport{}
+	hc := &http.Client{Transport: rt}
+
+	client := bosgo.New(hc, s.Addr())
+	clients := map[string]interface{}{
+		"Client":     client,
+		"DevClient":  client.WithDeveloperToken(devToken),
+		"AppClient":  client.WithApplicationKey(DefaultApplicationKey),
+		"UserClient": bosgo.NewUserClient(hc, s.Addr(), DefaultUserID, userToken, DefaultApplicationKey),
+	}
+
+	requests := map[string][]*http.Request{}
+	for name, c := range clients {
+		collectRequests(t, name, reflect.ValueOf(c), rt, requests)
+	}
+
+	if len(requests) == 0 {
+		t.Fatalf("found no client requests")
+	}
+
+	for name, reqs := range requests {
+		if len(reqs) == 0 {
+			t.Errorf("%s: no request was sent", name)
+			continue
+		}
+		covered := true
+		for _, req := range reqs {
+			if err := s.ReadState(bytes.NewReader(state.Bytes())); err != nil {
+				t.Fatalf("unexpected error reading state: %v", err)
+			}
+			if !handled(s, req) {
+				covered = false
+				if !routeExclusions[name] {
+					t.Errorf("%s: test server does not handle %s %s", name, req.Method, req.URL.Path)
+				}
+			}
+		}
+		if covered && routeExclusions[name] {
+			t.Errorf("%s: test server now handles the request, remove it from routeExclusions", name)
+		}
+	}
+
+	for name := range routeExclusions {
+		if _, exists := requests[name]; !exists {
+			t.Errorf("%s: unknown client request in routeExclusions", name)
+		}
+	}
+}
+
+// handled reports whether the server handles req. A request is not handled
+// when no route matches its path, when the route does not accept its method
+// or when the handler reports that it is not implemented.
+func handled(s *Server, req *http.Request) bool {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	switch rec.Code {
+	case http.StatusMethodNotAllowed:
+		return false
+	case http.StatusMovedPermanently:
+		// The mux redirects a path to the route with a trailing slash
+		return false
+	case http.StatusNotFound, http.StatusInternalServerError:
+		var resp errorResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			// Not an error sent by a handler, so no route matched
+			return false
+		}
+		for _, item := range resp.Errors {
+			if item.Code == "not_implemented_by_test_server" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// collectRequests sends every request that can be built by the methods of v
+// and the services it holds, recording the requests made by each method.
+func collectRequests(t *testing.T, name string, v reflect.Value, rt *recordingTransport, requests map[string][]*http.Request) {
+	typ := v.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		mt := m.Type
+		if mt.NumOut() != 1 {
+			continue
+		}
+		send, ok := mt.Out(0).MethodByName("Send")
+		if !ok {
+			continue
+		}
+
+		methodName := name + "." + m.Name
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: panic while sending request: %v", methodName, r)
+				}
+			}()
+
+			builder := callWithArgs(v.Method(i), mt, 1, routeArgs[methodName])
+			callWithArgs(builder.MethodByName("Send"), send.Type, 1, nil)
+		}()
+		requests[methodName] = rt.take()
+	}
+
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	elem := v.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		f := elem.Type().Field(i)
+		if f.PkgPath != "" || f.Type.Kind() != reflect.Ptr || !strings.HasSuffix(f.Type.Elem().Name(), "Service") {
+			continue
+		}
+		collectRequests(t, name+"."+f.Name, elem.Field(i), rt, requests)
+	}
+}
+
+// callWithArgs calls fn with the supplied arguments, using placeholder values
+// for any that are missing. The first skip inputs of ft are ignored since they
+// describe the method receiver.
+func callWithArgs(fn reflect.Value, ft reflect.Type, skip int, supplied []interface{}) reflect.Value {
+	var args []reflect.Value
+	for i := skip; i < ft.NumIn(); i++ {
+		if n := i - skip; n < len(supplied) {
+			args = append(args, reflect.ValueOf(supplied[n]))
+			continue
+		}
+		args = append(args, placeholder(ft.In(i)))
+	}
+
+	var out []reflect.Value
+	if ft.IsVariadic() {
+		out = fn.CallSlice(args)
+	} else {
+		out = fn.Call(args)
+	}
+	if len(out) == 0 {
+		return reflect.Value{}
+	}
+	return out[0]
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+func placeholder(typ reflect.Type) reflect.Value {
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf("1").Convert(typ)
+	case reflect.Int, reflect.Int64:
+		return reflect.ValueOf(int64(1)).Convert(typ)
+	case reflect.Interface:
+		if typ == contextType {
+			return reflect.ValueOf(context.Background())
+		}
+	case reflect.Ptr:
+		return reflect.New(typ.Elem())
+	}
+	return reflect.Zero(typ)
+}
@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,12 +22,31 @@ import (
 )
 
 type Dev struct {
-	ID string
+	ID       string
+	Email    string
+	Password string
+}
+
+type Team struct {
+	ID        string
+	Name      string
+	OwnerID   string
+	CreatedAt time.Time
+	Members   []TeamMember
+	Invites   map[string]string // map of invited emails indexed by invite token
+}
+
+type TeamMember struct {
+	DevID     string
+	CreatedAt time.Time
+	Accesses  []bosgo.TeamMemberAccess
 }
 
 type App struct {
 	ID          string
 	DeveloperID string
+	Label       string
+	Keys        []bosgo.ApplicationKey
 }
 
 type User struct {
@@ -37,7 +58,9 @@ type User struct {
 	Transactions          []bosgo.Transaction
 	ScheduledTransactions []bosgo.Transaction
 	RepeatedTransactions  []bosgo.RepeatedTransaction
+	Beneficiaries         []bosgo.Beneficiary
 	StoredAnswers         map[string][]bosgo.ChallengeAnswer // map of challenge answers indexed by provider ID
+	DeactivatedAt         time.Time                          // zero unless the user has been deactivated
 }
 
 type Job struct {
@@ -84,8 +107,12 @@ type AccessDetails struct {
 	ScheduledTransactions []bosgo.Transaction
 	RepeatedTransactions  []bosgo.RepeatedTransaction
 	ChallengeMap          map[string]string
+	OptionalChallenges    map[string]bool // IDs of challenges in ChallengeMap that need not be answered
 	TransferAuths         []TransferAuth
 	StageProblems         map[bosgo.JobStage][]bosgo.Problem
+	RefreshWarnings       bosgo.Warnings // warnings reported by jobs that refresh the access
+	PartialRefresh        bool           // refresh jobs import only part of the data
+	RefreshFails          bool           // refresh jobs end with a problem and import no data
 }
 
 type TransferAuth struct {
@@ -107,31 +134,75 @@ func (j *Job) isAnswered(id, val string) bool {
 	return false
 }
 
+// challengeRemaining reports whether a challenge has yet to be answered
+// correctly. A challenge answered wrongly, such as a PIN that has been reset,
+// remains to be answered. Optional challenges remain only once an answer has
+// been supplied for them.
+func (j *Job) challengeRemaining() bool {
+	for id, val := range j.AccessDetails.ChallengeMap {
+		if j.isAnswered(id, val) {
+			continue
+		}
+		if j.AccessDetails.OptionalChallenges[id] && !j.hasAnswer(id) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// hasAnswer reports whether any answer has been supplied for the challenge.
+func (j *Job) hasAnswer(id string) bool {
+	for _, ans := range j.SuppliedAnswers {
+		if ans.ID == id && ans.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
 type Logger interface {
 	Logf(format string, args ...interface{})
 }
 
 type Server struct {
-	Svr *httptest.Server
-	mux *http.ServeMux
+	Svr    *httptest.Server
+	mux    *http.ServeMux
+	routes []string // patterns registered with mux
 
 	mu                 sync.Mutex // guards following fields
 	id                 int64
+	ids                map[IDSpace]int64 // last ID generated in each space
 	logger             Logger
-	Devs               map[string]Dev           // map of developers indexed by ID
-	Apps               map[string]App           // map of applications indexed by ID
-	Users              map[string]User          // map of users indexed by ID
-	UserTokens         map[string]string        // map of user IDs indexed by token
-	Jobs               map[string]Job           // map of jobs indexed by ID
-	Accesses           map[string]AccessDetails // map of access details indexed by provider ID
-	Transfers          map[string]TransferOrder // map of transfer orders indexed by ID
-	RecurringTransfers map[string]TransferOrder // map of recurrings transfers orders indexed by ID
+	Devs               map[string]Dev            // map of developers indexed by ID
+	DevTokens          map[string]string         // map of developer IDs indexed by token
+	Teams              map[string]Team           // map of teams indexed by ID
+	Apps               map[string]App            // map of applications indexed by ID
+	Users              map[string]User           // map of users indexed by ID
+	UserTokens         map[string]string         // map of user IDs indexed by token
+	Jobs               map[string]Job            // map of jobs indexed by ID
+	Accesses           map[string]AccessDetails  // map of access details indexed by provider ID
+	Transfers          map[string]TransferOrder  // map of transfer orders indexed by ID
+	RecurringTransfers map[string]TransferOrder  // map of recurrings transfers orders indexed by ID
+	Providers          map[string]bosgo.Provider // map of providers indexed by ID
+	Categories         map[int64]bosgo.Category  // map of transaction categories indexed by ID
 	confirmSimilar     bool
+	faults             map[string]*fault // injected errors indexed by URL path
+	latency            time.Duration     // injected delay before each request is handled
+}
+
+// fault is an error injected into responses to requests for a path.
+type fault struct {
+	status    int
+	code      string
+	remaining int
 }
 
 func New() *Server {
 	s := Server{
 		Devs:               make(map[string]Dev),
+		DevTokens:          make(map[string]string),
+		Teams:              make(map[string]Team),
 		Apps:               make(map[string]App),
 		Users:              make(map[string]User),
 		UserTokens:         make(map[string]string),
@@ -139,25 +210,50 @@ func New() *Server {
 		Accesses:           make(map[string]AccessDetails),
 		Transfers:          make(map[string]TransferOrder),
 		RecurringTransfers: make(map[string]TransferOrder),
+		Providers:          make(map[string]bosgo.Provider),
+		Categories:         make(map[int64]bosgo.Category),
 	}
 	s.Svr = httptest.NewTLSServer(&s)
 
 	s.mux = http.NewServeMux()
-	s.mux.HandleFunc("/v1/users", s.handleUsers)
-	s.mux.HandleFunc("/v1/users/login", s.handleUsersLogin)
-	s.mux.HandleFunc("/v1/users/logout", s.handleUsersLogout)
-	s.mux.HandleFunc("/v1/users/reset_password", s.handleUsersResetPassword)
-
-	s.mux.HandleFunc("/v1/accesses", s.handleAccesses)
-	s.mux.HandleFunc("/v1/accesses/", s.handleAccess)
-	s.mux.HandleFunc("/v1/accounts", s.handleAccounts)
-	s.mux.HandleFunc("/v1/jobs/", s.handleJobs)
-	s.mux.HandleFunc("/v1/transactions", s.handleTransactions)
-	s.mux.HandleFunc("/v1/scheduled_transactions", s.handleScheduledTransactions)
-	s.mux.HandleFunc("/v1/repeated_transactions", s.handleRepeatedTransactions)
-	s.mux.HandleFunc("/v1/repeated_transactions/", s.handleRepeatedTransactions)
-	s.mux.HandleFunc("/v1/transfers", s.handleTransfers)
-	s.mux.HandleFunc("/v1/transfers/", s.handleTransfer)
+	s.handle("/v1/developers", s.handleDeveloper)
+	s.handle("/v1/developers/login", s.handleDeveloperLogin)
+	s.handle("/v1/developers/logout", s.handleDeveloperLogout)
+	s.handle("/v1/developers/applications", s.handleApplications)
+	s.handle("/v1/developers/users", s.handleDevUsers)
+	s.handle("/v1/developers/applications/", s.handleApplication)
+	s.handle("/v1/developers/application_keys/", s.handleApplicationKey)
+	s.handle("/v1/developers/teams", s.handleTeams)
+	s.handle("/v1/developers/teams/", s.handleTeam)
+
+	s.handle("/v1/users", s.handleUsers)
+	s.handle("/v1/users/login", s.handleUsersLogin)
+	s.handle("/v1/users/logout", s.handleUsersLogout)
+	s.handle("/v1/users/password", s.handleUsersPassword)
+	s.handle("/v1/users/reset_password", s.handleUsersResetPassword)
+
+	s.handle("/v1/iban/", s.handleIBAN)
+
+	s.handle("/v1/providers", s.handleProviders)
+	s.handle("/v1/providers/", s.handleProvider)
+
+	s.handle("/v1/categories", s.handleCategories)
+	s.handle("/v1/categories/", s.handleCategory)
+
+	s.handle("/v1/accesses", s.handleAccesses)
+	s.handle("/v1/accesses/", s.handleAccess)
+	s.handle("/v1/accounts", s.handleAccounts)
+	s.handle("/v1/accounts/", s.handleAccount)
+	s.handle("/v1/jobs", s.handleJobsList)
+	s.handle("/v1/jobs/", s.handleJobs)
+	s.handle("/v1/transactions", s.handleTransactions)
+	s.handle("/v1/scheduled_transactions", s.handleScheduledTransactions)
+	s.handle("/v1/repeated_transactions", s.handleRepeatedTransactions)
+	s.handle("/v1/repeated_transactions/", s.handleRepeatedTransactions)
+	s.handle("/v1/beneficiaries", s.handleBeneficiaries)
+	s.handle("/v1/beneficiaries/", s.handleBeneficiary)
+	s.handle("/v1/transfers", s.handleTransfers)
+	s.handle("/v1/transfers/", s.handleTransfer)
 
 	return &s
 }
@@ -212,26 +308,108 @@ func (s *Server) Close() {
 	s.Svr = nil
 }
 
+func (s *Server) handle(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+	s.routes = append(s.routes, pattern)
+}
+
+// Routes returns the URL path patterns handled by the server, sorted alphabetically.
+func (s *Server) Routes() []string {
+	routes := append([]string(nil), s.routes...)
+	sort.Strings(routes)
+	return routes
+}
+
+// HasRoute reports whether the server has a handler for the given URL path.
+// Only the route patterns are consulted, so a path below a pattern ending in a
+// slash is reported even when the handler does not implement it.
+func (s *Server) HasRoute(path string) bool {
+	_, pattern := s.mux.Handler(&http.Request{URL: &url.URL{Path: path}})
+	return pattern != ""
+}
+
+// InjectError makes the next times requests for path fail with the given
+// HTTP status and error code. Requests are matched by their exact URL path,
+// for example "/v1/accounts". Injecting an error for a path replaces any error
+// previously injected for it and a times of zero removes it.
+func (s *Server) InjectError(path string, status int, code string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if times <= 0 {
+		delete(s.faults, path)
+		return
+	}
+	if s.faults == nil {
+		s.faults = make(map[string]*fault)
+	}
+	s.faults[path] = &fault{status: status, code: code, remaining: times}
+}
+
+// InjectLatency makes the server wait for d before handling each request. A
+// duration of zero removes the delay.
+func (s *Server) InjectLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// takeFault returns the error injected for path, if any, and counts it as
+// used.
+func (s *Server) takeFault(path string) (fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, exists := s.faults[path]
+	if !exists {
+		return fault{}, false
+	}
+	f.remaining--
+	if f.remaining <= 0 {
+		delete(s.faults, path)
+	}
+	return *f, true
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	s.Logf("received request: %s %s", req.Method, req.URL.Path)
+
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	if f, found := s.takeFault(req.URL.Path); found {
+		s.Logf("injecting error %d %s", f.status, f.code)
+		s.sendError(w, f.status, f.code)
+		return
+	}
+
 	s.mux.ServeHTTP(w, req)
 }
 
+// errorResp is the body of an error response. Its items use the client's
+// definition so that everything the test server sends can be decoded.
 type errorResp struct {
-	Errors []apiError `json:"errors"`
+	Errors []bosgo.ErrorItem `json:"errors"`
 }
 
-// APIError represents an error that API may deliver to the customer
-type apiError struct {
-	Code    string                 `json:"code"`
-	Payload map[string]interface{} `json:"payload,omitempty"`
+func (s *Server) sendError(w http.ResponseWriter, status int, errcode string) {
+	s.sendErrorPayload(w, status, errcode, nil)
 }
 
-func (s *Server) sendError(w http.ResponseWriter, status int, errcode string) {
+// sendErrorPayload sends an error response whose error carries additional
+// information, such as the keys of fields that failed validation.
+func (s *Server) sendErrorPayload(w http.ResponseWriter, status int, errcode string, payload map[string][]string) {
 	resp := errorResp{
-		Errors: []apiError{
+		Errors: []bosgo.ErrorItem{
 			{
-				Code: errcode,
+				Code:    errcode,
+				Payload: payload,
 			},
 		},
 	}
@@ -275,11 +453,56 @@ func (s *Server) readJSON(w http.ResponseWriter, req *http.Request, v interface{
 	return true
 }
 
+// IDSpace identifies a sequence of IDs generated by the server. Each space has
+// its own counter so that creating a resource of one kind does not change the
+// IDs given to resources of another kind.
+type IDSpace string
+
+const (
+	IDSpaceUsers     IDSpace = "users"
+	IDSpaceAccesses  IDSpace = "accesses"
+	IDSpaceAccounts  IDSpace = "accounts"
+	IDSpaceJobs      IDSpace = "jobs"
+	IDSpaceTransfers IDSpace = "transfers"
+)
+
+// SetIDSeed restarts ID generation so that the next ID generated in every
+// space, and for resources such as tokens that have no space of their own, is
+// seed+1. Tests may use it to pin the IDs of resources they create. IDs that
+// are already in use are not checked, so the seed should be chosen above them.
+func (s *Server) SetIDSeed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id = seed
+	s.ids = map[IDSpace]int64{
+		IDSpaceUsers:     seed,
+		IDSpaceAccesses:  seed,
+		IDSpaceAccounts:  seed,
+		IDSpaceJobs:      seed,
+		IDSpaceTransfers: seed,
+	}
+}
+
 func (s *Server) nextIDStr() string {
 	id := s.nextID()
 	return fmt.Sprintf("%08x", id)
 }
 
+// nextSpaceID returns the next ID in space.
+func (s *Server) nextSpaceID(space IDSpace) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids == nil {
+		s.ids = make(map[IDSpace]int64)
+	}
+	s.ids[space]++
+	return s.ids[space]
+}
+
+func (s *Server) nextSpaceIDStr(space IDSpace) string {
+	return fmt.Sprintf("%08x", s.nextSpaceID(space))
+}
+
 func (s *Server) nextID() int64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -389,9 +612,110 @@ func (s *Server) setUserLoggedOut(token string) {
 	delete(s.UserTokens, token)
 }
 
+func (s *Server) getDev(id string) (Dev, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dev, exists := s.Devs[id]
+	return dev, exists
+}
+
+func (s *Server) setDev(dev Dev) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Devs[dev.ID] = dev
+}
+
+func (s *Server) requireDev(w http.ResponseWriter, req *http.Request) (Dev, bool) {
+	token := req.Header.Get("X-Token")
+
+	s.mu.Lock()
+	id, exists := s.DevTokens[token]
+	s.mu.Unlock()
+
+	if !exists {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return Dev{}, false
+	}
+	dev, found := s.getDev(id)
+	if !found {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return Dev{}, false
+	}
+
+	return dev, true
+}
+
+// requireConfirmation reads the confirmation of a destructive operation from
+// the request body and checks it against the developer's credentials.
+func (s *Server) requireConfirmation(w http.ResponseWriter, req *http.Request, dev Dev) bool {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.Logf("failed to read body: %v", err)
+		s.sendError(w, http.StatusBadRequest, "general")
+		return false
+	}
+
+	var confirm bosgo.DeveloperConfirmAction
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &confirm); err != nil {
+			s.Logf("failed to unmarshal body: %v", err)
+			s.sendError(w, http.StatusBadRequest, "general")
+			return false
+		}
+	}
+
+	switch {
+	case confirm.Password == "" && confirm.Email == "":
+		s.sendError(w, http.StatusForbidden, "confirmation_required")
+		return false
+	case confirm.Password != "" && confirm.Password != dev.Password:
+		s.sendError(w, http.StatusForbidden, "confirmation_failed")
+		return false
+	case confirm.Password == "" && (dev.Password != "" || confirm.Email != dev.Email):
+		s.sendError(w, http.StatusForbidden, "confirmation_failed")
+		return false
+	}
+	return true
+}
+
+func (s *Server) deleteDev(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Devs, id)
+	for token, devID := range s.DevTokens {
+		if devID == id {
+			delete(s.DevTokens, token)
+		}
+	}
+	for appID, app := range s.Apps {
+		if app.DeveloperID == id {
+			delete(s.Apps, appID)
+		}
+	}
+}
+
+func (s *Server) setDevLoggedIn(id string) string {
+	token := s.nextIDStr()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DevTokens[token] = id
+	return token
+}
+
+func (s *Server) setDevLoggedOut(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.DevTokens, token)
+}
+
+// devToken is the response to a successful developer login or creation.
+type devToken struct {
+	Token string `json:"token"`
+}
+
 func (s *Server) newJob(userID string, providerID string, answers []bosgo.ChallengeAnswer, action JobAction) *bosgo.Job {
 	job := Job{
-		ID:         s.nextIDStr(),
+		ID:         s.nextSpaceIDStr(IDSpaceJobs),
 		UserID:     userID,
 		ProviderID: providerID,
 		Stage:      bosgo.JobStageUnauthenticated,
@@ -479,6 +803,9 @@ func (s *Server) progressJob(j *Job, answers []bosgo.ChallengeAnswer) {
 
 	for id, val := range j.AccessDetails.ChallengeMap {
 		if !j.isAnswered(id, val) {
+			if j.AccessDetails.OptionalChallenges[id] && !j.hasAnswer(id) {
+				continue
+			}
 			j.NeedsAnswers = true
 			if id == ChallengePIN {
 				j.Problems = append(j.Problems, bosgo.Problem{
@@ -508,6 +835,20 @@ func (s *Server) progressJob(j *Job, answers []bosgo.ChallengeAnswer) {
 	}
 
 	if j.JobAction == JobActionRefresh {
+		status := bosgo.RefreshStatusOK
+		if j.NeedsAnswers {
+			status = bosgo.RefreshStatusNeedsCredentials
+		} else if j.AccessDetails.RefreshFails {
+			status = bosgo.RefreshStatusError
+			j.Stage = bosgo.JobStageProblem
+			j.Problems = append(j.Problems, bosgo.Problem{
+				Domain: "provider",
+				Code:   "provider_unavailable",
+			})
+		} else if j.AccessDetails.PartialRefresh {
+			status = bosgo.RefreshStatusPartial
+		}
+		s.setAccessRefreshed(j.UserID, j.AccessDetails.Access.ID, status)
 		return
 	}
 
@@ -515,7 +856,12 @@ func (s *Server) progressJob(j *Job, answers []bosgo.ChallengeAnswer) {
 	if !found {
 		return
 	}
-	user.Accesses = append(user.Accesses, j.AccessDetails.Access)
+	access := j.AccessDetails.Access
+	if j.Finished {
+		access.LastRefreshedAt = time.Now()
+		access.RefreshStatus = bosgo.RefreshStatusOK
+	}
+	user.Accesses = append(user.Accesses, access)
 	user.Transactions = append(user.Transactions, j.AccessDetails.Transactions...)
 	user.RepeatedTransactions = append(user.RepeatedTransactions, j.AccessDetails.RepeatedTransactions...)
 	user.ScheduledTransactions = append(user.ScheduledTransactions, j.AccessDetails.ScheduledTransactions...)
@@ -523,6 +869,28 @@ func (s *Server) progressJob(j *Job, answers []bosgo.ChallengeAnswer) {
 	s.SetUser(user)
 }
 
+// setAccessRefreshed records the time and outcome of a refresh of the user's access.
+func (s *Server) setAccessRefreshed(userID string, accessID int64, status bosgo.RefreshStatus) {
+	user, found := s.GetUser(userID)
+	if !found {
+		return
+	}
+	accesses := make([]bosgo.Access, len(user.Accesses))
+	copy(accesses, user.Accesses)
+	for i := range accesses {
+		if accesses[i].ID == accessID {
+			// A partial or failed refresh leaves the data as of the last
+			// complete refresh
+			if status != bosgo.RefreshStatusPartial && status != bosgo.RefreshStatusError {
+				accesses[i].LastRefreshedAt = time.Now()
+			}
+			accesses[i].RefreshStatus = status
+		}
+	}
+	user.Accesses = accesses
+	s.SetUser(user)
+}
+
 func (s *Server) updateStoredAnswers(userID string, providerID string, answers []bosgo.ChallengeAnswer) {
 	user, found := s.GetUser(userID)
 	if !found {
@@ -582,19 +950,64 @@ func (s *Server) requireAccess(w http.ResponseWriter, req *http.Request) (bosgo.
 	return bosgo.Access{}, false
 }
 
+func (s *Server) requireAccount(w http.ResponseWriter, req *http.Request) (bosgo.Account, bool) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return bosgo.Account{}, false
+	}
+
+	path := req.URL.Path
+	if !strings.HasPrefix(path, "/v1/accounts/") {
+		s.sendError(w, http.StatusBadRequest, "general")
+		return bosgo.Account{}, false
+	}
+	path = path[13:]
+
+	trailingSlash := strings.IndexByte(path, '/')
+	if trailingSlash != -1 {
+		path = path[:trailingSlash]
+	}
+
+	accountID, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		s.Logf("failed to parse accountID: %v", err)
+		s.sendError(w, http.StatusBadRequest, "general")
+		return bosgo.Account{}, false
+	}
+
+	for _, acc := range user.Accesses {
+		for _, account := range acc.Accounts {
+			if account.ID == accountID {
+				return account, true
+			}
+		}
+	}
+	s.sendError(w, http.StatusNotFound, "resource_not_found")
+	return bosgo.Account{}, false
+}
+
 // SetConfirmSimilar sets the server to respond with the confirm_similar state for subsequent transfers
 func (s *Server) SetConfirmSimilar(v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.confirmSimilar = v
 }
 
 func (s *Server) newTransfer(userID string, providerID string, trp *transferParams) TransferOrder {
+	s.mu.Lock()
+	confirmSimilar := s.confirmSimilar
+	s.mu.Unlock()
+
+	now := time.Now()
 	tr := TransferOrder{
 		Transfer: bosgo.Transfer{
-			ID: s.nextIDStr(),
+			ID:      s.nextSpaceIDStr(IDSpaceTransfers),
+			Created: now,
+			Updated: now,
 		},
 		UserID:         userID,
 		Type:           trp.Type,
-		ConfirmSimilar: s.confirmSimilar,
+		ConfirmSimilar: confirmSimilar,
 	}
 
 	s.mu.Lock()
@@ -607,7 +1020,15 @@ func (s *Server) newTransfer(userID string, providerID string, trp *transferPara
 		return tr
 	}
 
+	amount := trp.Amount
 	tr.AccessDetails = ad
+	tr.Transfer.From = bosgo.TransferAddress{
+		AccessID:  ad.Access.ID,
+		AccountID: trp.From,
+	}
+	tr.Transfer.To = trp.To
+	tr.Transfer.Amount = &amount
+	tr.Transfer.Usage = trp.Usage
 	tr.Transfer.State = bosgo.TransferStateOngoing
 	tr.Transfer.Step = bosgo.TransferStep{
 		Intent: transferInit,
@@ -674,6 +1095,8 @@ func (s *Server) progressTransfer(tr *TransferOrder, answers []bosgo.ChallengeAn
 	combinedAnswers := append([]bosgo.ChallengeAnswer{}, answers...)
 	u, _ := s.GetUser(tr.UserID)
 	combinedAnswers = append(combinedAnswers, u.StoredAnswers[tr.AccessDetails.Access.ProviderID]...)
+	s.updateStoredAnswers(tr.UserID, tr.AccessDetails.Access.ProviderID, answers)
+	tr.Transfer.Updated = time.Now()
 	switch tr.Transfer.Step.Intent {
 	case transferInit:
 		tr.Transfer.State = bosgo.TransferStateOngoing
@@ -761,6 +1184,9 @@ func (s *Server) progressTransfer(tr *TransferOrder, answers []bosgo.ChallengeAn
 						now := time.Now()
 						tr.Transfer.EntryDate = now
 						tr.Transfer.SettlementDate = now
+						if tr.Type == bosgo.TransferTypeRegular {
+							s.bookTransfer(tr)
+						}
 						return
 					}
 				}
@@ -777,6 +1203,45 @@ func (s *Server) progressTransfer(tr *TransferOrder, answers []bosgo.ChallengeAn
 	}
 }
 
+// bookTransfer adds the transaction resulting from a succeeded transfer to the
+// user's transactions.
+func (s *Server) bookTransfer(tr *TransferOrder) {
+	user, found := s.GetUser(tr.UserID)
+	if !found {
+		return
+	}
+
+	tx := bosgo.Transaction{
+		ID:            s.nextID(),
+		AccessID:      tr.Transfer.From.AccessID,
+		UserAccountID: tr.Transfer.From.AccountID,
+		UserAccount: bosgo.AccountRef{
+			ProviderID: tr.AccessDetails.Access.ProviderID,
+		},
+		Amount: &bosgo.MoneyAmount{
+			Currency: tr.Transfer.Amount.Currency,
+			Value:    "-" + tr.Transfer.Amount.Value,
+		},
+		EntryDate:      tr.Transfer.EntryDate,
+		SettlementDate: tr.Transfer.SettlementDate,
+		Usage:          tr.Transfer.Usage,
+		Counterparty: bosgo.Counterparty{
+			Name: tr.Transfer.To.Name,
+			Account: bosgo.AccountRef{
+				IBAN: tr.Transfer.To.IBAN,
+			},
+		},
+	}
+	for _, ac := range tr.AccessDetails.Access.Accounts {
+		if ac.ID == tr.Transfer.From.AccountID {
+			tx.UserAccount.IBAN = ac.IBAN
+		}
+	}
+
+	user.Transactions = append(user.Transactions, tx)
+	s.SetUser(user)
+}
+
 // AddAccess adds configuration for an access with its transactions so it can be added to a user via the server API
 func (s *Server) AddAccess(ad AccessDetails) {
 	s.mu.Lock()
@@ -883,7 +1348,7 @@ func (s *Server) handleUserCreate(w http.ResponseWriter, req *http.Request) {
 	s.mu.Unlock()
 
 	user := User{
-		ID:            s.nextIDStr(),
+		ID:            s.nextSpaceIDStr(IDSpaceUsers),
 		Username:      creds.Username,
 		Password:      creds.Password,
 		ApplicationID: app.ID,
@@ -901,35 +1366,82 @@ func (s *Server) handleUserCreate(w http.ResponseWriter, req *http.Request) {
 }
 
 func (s *Server) handleUserDelete(w http.ResponseWriter, req *http.Request) {
-	user, token, found := s.requireUser(w, req)
+	user, _, found := s.requireUser(w, req)
 	if !found {
 		return
 	}
 
-	var pwd struct {
-		Password string `json:"password"`
+	var data struct {
+		Password string           `json:"password"`
+		Mode     bosgo.DeleteMode `json:"mode"`
 	}
-	if !s.readJSON(w, req, &pwd) {
+	if !s.readJSON(w, req, &data) {
 		return
 	}
 
-	if user.Password != pwd.Password {
+	if user.Password != data.Password {
 		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
 		return
 	}
 
-	s.mu.Lock()
-	delete(s.Users, user.ID)
-	delete(s.UserTokens, token)
-	s.mu.Unlock()
+	switch data.Mode {
+	case "":
+		data.Mode = bosgo.DeleteModeErase
+	case bosgo.DeleteModeDeactivate, bosgo.DeleteModeErase:
+	default:
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"mode"}})
+		return
+	}
+
+	s.deleteUser(user.ID, data.Mode)
 
 	resp := bosgo.DeletedUser{
 		DeletedUserID: user.ID,
+		Mode:          data.Mode,
 	}
 
 	s.sendJSON(w, http.StatusOK, &resp)
 }
 
+// deleteUser logs out all of the user's sessions. If mode is
+// DeleteModeDeactivate then the user and their data are retained but the user
+// may no longer log in, otherwise the user is removed together with their
+// jobs and transfers.
+func (s *Server) deleteUser(userID string, mode bosgo.DeleteMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, id := range s.UserTokens {
+		if id == userID {
+			delete(s.UserTokens, token)
+		}
+	}
+
+	if mode == bosgo.DeleteModeDeactivate {
+		if u, exists := s.Users[userID]; exists {
+			u.DeactivatedAt = time.Now()
+			s.Users[userID] = u
+		}
+		return
+	}
+
+	delete(s.Users, userID)
+	for id, j := range s.Jobs {
+		if j.UserID == userID {
+			delete(s.Jobs, id)
+		}
+	}
+	for id, t := range s.Transfers {
+		if t.UserID == userID {
+			delete(s.Transfers, id)
+		}
+	}
+	for id, t := range s.RecurringTransfers {
+		if t.UserID == userID {
+			delete(s.RecurringTransfers, id)
+		}
+	}
+}
+
 func (s *Server) handleUsersLogin(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
@@ -951,7 +1463,7 @@ func (s *Server) handleUsersLogin(w http.ResponseWriter, req *http.Request) {
 		if u.Username != creds.Username {
 			continue
 		}
-		if u.Password != creds.Password {
+		if u.Password != creds.Password || !u.DeactivatedAt.IsZero() {
 			break
 		}
 		user = u
@@ -986,46 +1498,134 @@ func (s *Server) handleUsersLogout(w http.ResponseWriter, req *http.Request) {
 	s.sendNoContent(w)
 }
 
-func (s *Server) handleUsersResetPassword(w http.ResponseWriter, req *http.Request) {
+func (s *Server) handleUsersPassword(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
-	s.sendError(w, http.StatusInternalServerError, "not_implemented_by_test_server")
-}
-
-func (s *Server) handleAccesses(w http.ResponseWriter, req *http.Request) {
-	switch req.Method {
-	case http.MethodPost:
-		s.handleAccessCreate(w, req)
-		return
-	case http.MethodGet:
-		s.handleAccessesList(w, req)
-		return
-	}
-
-	http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
-	return
-}
-
-func (s *Server) handleAccessCreate(w http.ResponseWriter, req *http.Request) {
-	user, _, found := s.requireUser(w, req)
+	user, token, found := s.requireUser(w, req)
 	if !found {
 		return
 	}
 
 	var data struct {
-		ProviderID string                  `json:"provider_id"`
-		Answers    []bosgo.ChallengeAnswer `json:"challenge_answers"`
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
 	}
 	if !s.readJSON(w, req, &data) {
 		return
 	}
 
-	job := s.newJob(user.ID, data.ProviderID, data.Answers, JobActionCreate)
+	if user.Password != data.OldPassword {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+	if data.NewPassword == "" {
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"new_password"}})
+		return
+	}
 
-	s.sendJSON(w, http.StatusAccepted, &job)
+	s.changeUserPassword(user.ID, token, data.NewPassword)
+	s.sendNoContent(w)
+}
+
+// changeUserPassword sets the password of the user and logs out all of the
+// user's sessions other than the one identified by token.
+func (s *Server) changeUserPassword(userID, token, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, exists := s.Users[userID]
+	if !exists {
+		return
+	}
+	u.Password = password
+	s.Users[userID] = u
+	for t, id := range s.UserTokens {
+		if id == userID && t != token {
+			delete(s.UserTokens, t)
+		}
+	}
+}
+
+func (s *Server) handleUsersResetPassword(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	app, proceed := s.requireApp(w, req)
+	if !proceed {
+		return
+	}
+
+	var creds bosgo.UserCredentials
+	if !s.readJSON(w, req, &creds) {
+		return
+	}
+	if creds.Password == "" {
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"password"}})
+		return
+	}
+
+	if !s.resetUserPassword(app.ID, creds.Username, creds.Password) {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	s.sendNoContent(w)
+}
+
+// resetUserPassword sets the password of the application's user with the
+// given username and logs out all of the user's sessions. It reports whether
+// the user was found.
+func (s *Server) resetUserPassword(appID, username, password string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, u := range s.Users {
+		if u.ApplicationID != appID || u.Username != username {
+			continue
+		}
+		u.Password = password
+		s.Users[id] = u
+		for token, userID := range s.UserTokens {
+			if userID == id {
+				delete(s.UserTokens, token)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (s *Server) handleAccesses(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		s.handleAccessCreate(w, req)
+		return
+	case http.MethodGet:
+		s.handleAccessesList(w, req)
+		return
+	}
+
+	http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+	return
+}
+
+func (s *Server) handleAccessCreate(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	var data struct {
+		ProviderID string                  `json:"provider_id"`
+		Answers    []bosgo.ChallengeAnswer `json:"challenge_answers"`
+	}
+	if !s.readJSON(w, req, &data) {
+		return
+	}
+
+	job := s.newJob(user.ID, data.ProviderID, data.Answers, JobActionCreate)
+
+	s.sendJSON(w, http.StatusAccepted, &job)
 }
 
 func (s *Server) handleAccessesList(w http.ResponseWriter, req *http.Request) {
@@ -1034,7 +1634,61 @@ func (s *Server) handleAccessesList(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	s.sendJSON(w, http.StatusOK, user.Accesses)
+	if req.URL.Query().Get("include_accounts") == "true" {
+		s.sendJSON(w, http.StatusOK, user.Accesses)
+		return
+	}
+
+	accesses := make([]bosgo.Access, 0, len(user.Accesses))
+	for _, acc := range user.Accesses {
+		acc.Accounts = nil
+		accesses = append(accesses, acc)
+	}
+	s.sendJSON(w, http.StatusOK, accesses)
+}
+
+func (s *Server) handleJobsList(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	var filter *bool
+	if v := req.URL.Query().Get("finished"); v != "" {
+		finished, err := strconv.ParseBool(v)
+		if err != nil {
+			s.sendError(w, http.StatusBadRequest, "validation_bad_parameters")
+			return
+		}
+		filter = &finished
+	}
+
+	var jobs []Job
+	s.mu.Lock()
+	for _, job := range s.Jobs {
+		if job.UserID != user.ID {
+			continue
+		}
+		if filter != nil && job.Finished != *filter {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	// IDs are allocated sequentially so this orders jobs by creation
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	statuses := []bosgo.JobStatus{}
+	for i := range jobs {
+		statuses = append(statuses, *s.jobStatus(&jobs[i]))
+	}
+	s.sendJSON(w, http.StatusOK, statuses)
 }
 
 func (s *Server) handleJobs(w http.ResponseWriter, req *http.Request) {
@@ -1103,28 +1757,45 @@ func (s *Server) jobStatus(job *Job) *bosgo.JobStatus {
 	}
 
 	if job.NeedsAnswers {
-		status.Challenge = &bosgo.Challenge{}
+		status.Challenge = &bosgo.Challenge{
+			MaxSteps: len(job.AccessDetails.ChallengeMap),
+		}
 
 		for id := range job.AccessDetails.ChallengeMap {
 			previous := ""
+			answered := false
 			for _, ans := range job.SuppliedAnswers {
 				if ans.ID == id {
 					previous = ans.Value
+					answered = true
 					break
 				}
 			}
+			if answered {
+				status.Challenge.CurStep++
+			}
 
 			status.Challenge.NextChallenges = append(status.Challenge.NextChallenges, bosgo.ChallengeField{
 				ID:       id,
 				Previous: previous,
+				Optional: job.AccessDetails.OptionalChallenges[id],
 			})
 		}
-
+		status.Challenge.CanContinue = job.Error == "" && job.challengeRemaining()
 	}
 
 	for _, p := range job.Problems {
 		status.Errors = append(status.Errors, p)
 	}
+	if job.JobAction == JobActionRefresh && job.Finished {
+		status.Warnings = append(status.Warnings, job.AccessDetails.RefreshWarnings...)
+		if job.AccessDetails.PartialRefresh && !job.AccessDetails.RefreshFails {
+			status.Warnings = append(status.Warnings, bosgo.Problem{
+				Domain: "provider",
+				Code:   bosgo.WarningPartialData,
+			})
+		}
+	}
 
 	if job.Stage == bosgo.JobStageImported {
 		status.Access = &bosgo.JobAccess{
@@ -1164,125 +1835,426 @@ func (s *Server) handleAccounts(w http.ResponseWriter, req *http.Request) {
 	s.sendJSON(w, http.StatusOK, accounts)
 }
 
-func (s *Server) handleAccess(w http.ResponseWriter, req *http.Request) {
-	switch req.Method {
-	case http.MethodGet:
-		s.handleAccessGet(w, req)
+func (s *Server) handleAccount(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
-	case http.MethodDelete:
-		s.handleAccessDelete(w, req)
+	}
+
+	if strings.HasSuffix(req.URL.Path, "/statement") {
+		s.handleAccountStatement(w, req)
 		return
-	case http.MethodPost:
-		if strings.HasSuffix(req.URL.Path, "/refresh") {
-			s.handleAccessRefresh(w, req)
-			return
-		}
-		s.handleAccessUpdate(w, req)
+	}
+
+	if strings.Contains(strings.TrimPrefix(req.URL.Path, "/v1/accounts/"), "/") {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
 		return
 	}
 
-	http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
-	return
+	account, found := s.requireAccount(w, req)
+	if !found {
+		return
+	}
+	s.sendJSON(w, http.StatusOK, account)
 }
 
-func (s *Server) handleAccessGet(w http.ResponseWriter, req *http.Request) {
-	access, found := s.requireAccess(w, req)
-	if !found {
+func (s *Server) handleAccountStatement(w http.ResponseWriter, req *http.Request) {
+	if _, found := s.requireAccount(w, req); !found {
 		return
 	}
 
-	s.sendJSON(w, http.StatusOK, access)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(StatementPDF)
 }
 
-func (s *Server) handleAccessDelete(w http.ResponseWriter, req *http.Request) {
-	user, _, found := s.requireUser(w, req)
-	if !found {
+// AddProvider adds a provider that may be found by searching providers.
+func (s *Server) AddProvider(p bosgo.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Providers[p.ID] = p
+}
+
+func (s *Server) handleProviders(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	access, found := s.requireAccess(w, req)
-	if !found {
+	if _, proceed := s.requireApp(w, req); !proceed {
 		return
 	}
 
-	updatedUser, _ := deleteAccess(user, access)
+	query := req.URL.Query()
+	limit, ok := s.readQueryInt(w, query, "limit")
+	if !ok {
+		return
+	}
+	offset, ok := s.readQueryInt(w, query, "offset")
+	if !ok {
+		return
+	}
 
+	q := strings.ToLower(query.Get("q"))
+	country := query.Get("country")
+	capabilities := query["capability"]
+	results := bosgo.ProviderSearchResults{}
 	s.mu.Lock()
-	s.Users[user.ID] = updatedUser
+	for _, p := range s.Providers {
+		if q != "" && !strings.Contains(strings.ToLower(p.ID), q) && !strings.Contains(strings.ToLower(p.Name), q) {
+			continue
+		}
+		if country != "" && !strings.EqualFold(p.Country, country) {
+			continue
+		}
+		if !providerAllows(p, capabilities) {
+			continue
+		}
+		results = append(results, bosgo.ProviderSearchResult{Score: 1, Provider: p})
+	}
 	s.mu.Unlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Provider.ID < results[j].Provider.ID })
 
-	deleted := struct {
-		AccessID int64 `json:"deleted_access_id"`
-	}{
-		AccessID: access.ID,
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(results)))
+	if offset > len(results) {
+		offset = len(results)
 	}
-
-	s.sendJSON(w, http.StatusOK, deleted)
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	s.sendJSON(w, http.StatusOK, results)
 }
 
-func (s *Server) handleAccessRefresh(w http.ResponseWriter, req *http.Request) {
-	user, _, found := s.requireUser(w, req)
-	if !found {
+func (s *Server) handleProvider(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, proceed := s.requireApp(w, req); !proceed {
 		return
 	}
 
-	access, found := s.requireAccess(w, req)
-	if !found {
+	id := strings.TrimPrefix(req.URL.Path, "/v1/providers/")
+	s.mu.Lock()
+	p, exists := s.Providers[id]
+	ad, hasAccess := s.Accesses[id]
+	s.mu.Unlock()
+	if !exists && !hasAccess {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
 		return
 	}
+	if !exists {
+		p.ID = id
+	}
+	if len(p.Challenges) == 0 && hasAccess {
+		p.Challenges = accessChallenges(ad)
+	}
+	s.sendJSON(w, http.StatusOK, p)
+}
 
-	job := s.newJob(user.ID, access.ProviderID, []bosgo.ChallengeAnswer{}, JobActionRefresh)
+// accessChallenges describes the challenges that must be answered to add the
+// access, for providers that were not added with challenges of their own.
+func accessChallenges(ad AccessDetails) []bosgo.ChallengeSpec {
+	var ids []string
+	for id := range ad.ChallengeMap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
 
-	s.sendJSON(w, http.StatusAccepted, &job)
+	specs := []bosgo.ChallengeSpec{}
+	for _, id := range ids {
+		spec := bosgo.ChallengeSpec{
+			ID:       id,
+			Type:     bosgo.ChallengeTypeAlphaNumeric,
+			Optional: ad.OptionalChallenges[id],
+		}
+		if id == ChallengePIN || id == ChallengePassword {
+			spec.Secure = true
+		}
+		specs = append(specs, spec)
+	}
+	return specs
 }
 
-func (s *Server) handleAccessUpdate(w http.ResponseWriter, req *http.Request) {
-	user, _, found := s.requireUser(w, req)
-	if !found {
+// AddCategory adds a transaction category.
+func (s *Server) AddCategory(c bosgo.Category) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Categories[c.ID] = c
+}
+
+func (s *Server) handleCategories(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
-	access, found := s.requireAccess(w, req)
-	if !found {
+	if _, proceed := s.requireApp(w, req); !proceed {
 		return
 	}
 
-	var answers struct {
-		Answers []bosgo.ChallengeAnswer `json:"challenge_answers"`
+	locale := req.URL.Query().Get("locale")
+	list := bosgo.CategoryList{}
+	s.mu.Lock()
+	for _, c := range s.Categories {
+		list = append(list, localizeCategory(c, locale))
 	}
-	if !s.readJSON(w, req, &answers) {
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	s.sendJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleCategory(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, proceed := s.requireApp(w, req); !proceed {
 		return
 	}
 
-	s.updateStoredAnswers(user.ID, access.ProviderID, answers.Answers)
-
-	s.sendJSON(w, http.StatusOK, &access)
+	id, err := strconv.ParseInt(strings.TrimPrefix(req.URL.Path, "/v1/categories/"), 10, 64)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	s.mu.Lock()
+	c, exists := s.Categories[id]
+	s.mu.Unlock()
+	if !exists {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	s.sendJSON(w, http.StatusOK, localizeCategory(c, req.URL.Query().Get("locale")))
 }
 
-type txParams struct {
-	accessID  int64
-	accountID int64
-	limit     int64
-	offset    int64
-	since     time.Time
+// localizeCategory returns a copy of c holding only the name in the requested
+// locale, or in bosgo.DefaultCategoryLocale if there is none. If locale is
+// empty then all names are kept.
+func localizeCategory(c bosgo.Category, locale string) bosgo.Category {
+	if locale == "" {
+		return c
+	}
+	names := c.Names
+	c.Names = map[string]string{}
+	if name, ok := names[locale]; ok {
+		c.Names[locale] = name
+		return c
+	}
+	// Fall back to the base language, as Category.Name does, so that "de-AT"
+	// is served the "de" name
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		if name, ok := names[locale[:i]]; ok {
+			c.Names[locale[:i]] = name
+			return c
+		}
+	}
+	if name, ok := names[bosgo.DefaultCategoryLocale]; ok {
+		c.Names[bosgo.DefaultCategoryLocale] = name
+	}
+	return c
 }
 
-func (s *Server) parseTransactionParams(w http.ResponseWriter, req *http.Request) (txParams, bool) {
-	var params txParams
-	var err error
-
-	accessIDStr := req.URL.Query().Get("access_id")
-	if accessIDStr != "" {
-		params.accessID, err = strconv.ParseInt(accessIDStr, 10, 64)
-		if err != nil {
-			s.Logf("failed to parse access_id: %v", err)
-			s.sendError(w, http.StatusBadRequest, "general")
-			return txParams{}, false
+// providerAllows reports whether the provider allows all of the named operations.
+func providerAllows(p bosgo.Provider, capabilities []string) bool {
+	for _, c := range capabilities {
+		if !p.Operations.AllowedOperations.Allows(c) {
+			return false
 		}
 	}
+	return true
+}
 
-	accountIDStr := req.URL.Query().Get("account_id")
-	if accountIDStr != "" {
-		params.accountID, err = strconv.ParseInt(accountIDStr, 10, 64)
+// readQueryInt parses the named query parameter as a non-negative integer,
+// returning zero if it is absent. It sends a validation error and returns
+// false if the parameter is invalid.
+func (s *Server) readQueryInt(w http.ResponseWriter, query url.Values, name string) (int, bool) {
+	str := query.Get(name)
+	if str == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil || n < 0 {
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {name}})
+		return 0, false
+	}
+	return n, true
+}
+
+func (s *Server) handleIBAN(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, found := s.requireApp(w, req); !found {
+		return
+	}
+
+	iban := bosgo.NormalizeIBAN(req.URL.Path[len("/v1/iban/"):])
+	if bosgo.ValidateIBAN(iban) != nil {
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"iban"}})
+		return
+	}
+
+	details := bosgo.IBANDetails{
+		Account: bosgo.IBANAccount{
+			IBAN:     iban,
+			Provider: "IBO",
+		},
+		Banks: []bosgo.IBANBank{},
+	}
+	if strings.HasPrefix(iban, "DE") && len(iban) == 22 {
+		if bank, exists := KnownBanks[iban[4:12]]; exists {
+			details.Banks = append(details.Banks, bank)
+		}
+	}
+
+	s.sendJSON(w, http.StatusOK, details)
+}
+
+func (s *Server) handleAccess(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		s.handleAccessGet(w, req)
+		return
+	case http.MethodDelete:
+		s.handleAccessDelete(w, req)
+		return
+	case http.MethodPost:
+		if req.URL.Path == "/v1/accesses/refresh" {
+			s.handleAccessesRefresh(w, req)
+			return
+		}
+		if strings.HasSuffix(req.URL.Path, "/refresh") {
+			s.handleAccessRefresh(w, req)
+			return
+		}
+		s.handleAccessUpdate(w, req)
+		return
+	}
+
+	http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+	return
+}
+
+func (s *Server) handleAccessGet(w http.ResponseWriter, req *http.Request) {
+	access, found := s.requireAccess(w, req)
+	if !found {
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, access)
+}
+
+func (s *Server) handleAccessDelete(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+	access, found := s.requireAccess(w, req)
+	if !found {
+		return
+	}
+
+	updatedUser, _ := deleteAccess(user, access)
+
+	s.mu.Lock()
+	s.Users[user.ID] = updatedUser
+	s.mu.Unlock()
+
+	deleted := struct {
+		AccessID int64 `json:"deleted_access_id"`
+	}{
+		AccessID: access.ID,
+	}
+
+	s.sendJSON(w, http.StatusOK, deleted)
+}
+
+func (s *Server) handleAccessRefresh(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	access, found := s.requireAccess(w, req)
+	if !found {
+		return
+	}
+
+	job := s.newJob(user.ID, access.ProviderID, []bosgo.ChallengeAnswer{}, JobActionRefresh)
+
+	s.sendJSON(w, http.StatusAccepted, &job)
+}
+
+func (s *Server) handleAccessesRefresh(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	jobs := []bosgo.Job{}
+	for _, access := range user.Accesses {
+		job := s.newJob(user.ID, access.ProviderID, []bosgo.ChallengeAnswer{}, JobActionRefresh)
+		jobs = append(jobs, *job)
+	}
+
+	s.sendJSON(w, http.StatusAccepted, jobs)
+}
+
+func (s *Server) handleAccessUpdate(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	access, found := s.requireAccess(w, req)
+	if !found {
+		return
+	}
+
+	var answers struct {
+		Answers []bosgo.ChallengeAnswer `json:"challenge_answers"`
+	}
+	if !s.readJSON(w, req, &answers) {
+		return
+	}
+
+	s.updateStoredAnswers(user.ID, access.ProviderID, answers.Answers)
+
+	s.sendJSON(w, http.StatusOK, &access)
+}
+
+type txParams struct {
+	accessID   int64
+	accountID  int64
+	categoryID int64
+	limit      int64
+	offset     int64
+	since      time.Time
+	until      time.Time
+	minAmount  *big.Rat
+	order      bosgo.TransactionOrder
+}
+
+func (s *Server) parseTransactionParams(w http.ResponseWriter, req *http.Request) (txParams, bool) {
+	var params txParams
+	var err error
+
+	accessIDStr := req.URL.Query().Get("access_id")
+	if accessIDStr != "" {
+		params.accessID, err = strconv.ParseInt(accessIDStr, 10, 64)
+		if err != nil {
+			s.Logf("failed to parse access_id: %v", err)
+			s.sendError(w, http.StatusBadRequest, "general")
+			return txParams{}, false
+		}
+	}
+
+	accountIDStr := req.URL.Query().Get("account_id")
+	if accountIDStr != "" {
+		params.accountID, err = strconv.ParseInt(accountIDStr, 10, 64)
 		if err != nil {
 			s.Logf("failed to parse account_id: %v", err)
 			s.sendError(w, http.StatusBadRequest, "general")
@@ -1320,6 +2292,47 @@ func (s *Server) parseTransactionParams(w http.ResponseWriter, req *http.Request
 		}
 	}
 
+	untilStr := req.URL.Query().Get("until")
+	if untilStr != "" {
+		params.until, err = time.Parse(time.RFC3339, untilStr)
+		if err != nil {
+			s.Logf("failed to parse until: %v", err)
+			s.sendError(w, http.StatusBadRequest, "general")
+			return txParams{}, false
+		}
+	}
+
+	categoryIDStr := req.URL.Query().Get("category_id")
+	if categoryIDStr != "" {
+		params.categoryID, err = strconv.ParseInt(categoryIDStr, 10, 64)
+		if err != nil {
+			s.Logf("failed to parse category_id: %v", err)
+			s.sendError(w, http.StatusBadRequest, "general")
+			return txParams{}, false
+		}
+	}
+
+	minAmountStr := req.URL.Query().Get("min_amount")
+	if minAmountStr != "" {
+		var ok bool
+		params.minAmount, ok = new(big.Rat).SetString(minAmountStr)
+		if !ok {
+			s.Logf("failed to parse min_amount: %q", minAmountStr)
+			s.sendError(w, http.StatusBadRequest, "general")
+			return txParams{}, false
+		}
+		params.minAmount.Abs(params.minAmount)
+	}
+
+	params.order = bosgo.TransactionOrder(req.URL.Query().Get("sort"))
+	switch params.order {
+	case "", bosgo.TransactionOrderEntryDate, bosgo.TransactionOrderEntryDateDesc, bosgo.TransactionOrderAmount, bosgo.TransactionOrderAmountDesc:
+	default:
+		s.Logf("unknown sort order: %q", params.order)
+		s.sendError(w, http.StatusBadRequest, "general")
+		return txParams{}, false
+	}
+
 	if params.limit == 0 {
 		params.limit = 50
 	} else if params.limit > 300 {
@@ -1349,32 +2362,88 @@ func (s *Server) handleTransactions(w http.ResponseWriter, req *http.Request) {
 		Offset: int(params.offset),
 		Limit:  int(params.limit),
 	}
+	page.Transactions, page.Total = pageTransactions(user.Transactions, params)
 
-	if params.accessID == 0 && params.accountID == 0 && params.since.IsZero() {
-		page.Transactions = user.Transactions
-	} else {
-		page.Transactions = make([]bosgo.Transaction, 0, len(user.Transactions))
-		for _, tx := range user.Transactions {
-			if (params.accessID == 0 || tx.AccessID == params.accessID) &&
-				(params.accountID == 0 || tx.UserAccountID == params.accountID) &&
-				(params.since.IsZero() || params.since.Before(tx.EntryDate)) {
-				page.Transactions = append(page.Transactions, tx)
-			}
+	s.sendJSON(w, http.StatusOK, page)
+}
+
+// pageTransactions filters txs according to params and returns the requested
+// page of transactions together with the total number that matched the filter.
+func pageTransactions(txs []bosgo.Transaction, params txParams) ([]bosgo.Transaction, int) {
+	filtered := make([]bosgo.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if params.matches(tx) {
+			filtered = append(filtered, tx)
 		}
 	}
-	page.Total = len(page.Transactions)
+	txs = filtered
+	total := len(txs)
+
+	if params.order != "" {
+		sorted := make([]bosgo.Transaction, len(txs))
+		copy(sorted, txs)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			switch params.order {
+			case bosgo.TransactionOrderEntryDate:
+				return sorted[i].EntryDate.Before(sorted[j].EntryDate)
+			case bosgo.TransactionOrderEntryDateDesc:
+				return sorted[i].EntryDate.After(sorted[j].EntryDate)
+			case bosgo.TransactionOrderAmount:
+				return transactionAmount(sorted[i]).Cmp(transactionAmount(sorted[j])) < 0
+			default:
+				return transactionAmount(sorted[i]).Cmp(transactionAmount(sorted[j])) > 0
+			}
+		})
+		txs = sorted
+	}
 
 	start := int(params.offset)
+	if start > len(txs) {
+		start = len(txs)
+	}
 	end := int(params.offset + params.limit)
-	if end > len(page.Transactions) {
-		end = len(page.Transactions)
+	if end > len(txs) {
+		end = len(txs)
 	}
 
-	if start > 0 || end < len(page.Transactions) {
-		page.Transactions = page.Transactions[start:end]
+	if start > 0 || end < len(txs) {
+		txs = txs[start:end]
 	}
 
-	s.sendJSON(w, http.StatusOK, page)
+	return txs, total
+}
+
+// matches reports whether tx satisfies all of the filters in params.
+func (params txParams) matches(tx bosgo.Transaction) bool {
+	switch {
+	case params.accessID != 0 && tx.AccessID != params.accessID:
+		return false
+	case params.accountID != 0 && tx.UserAccountID != params.accountID:
+		return false
+	case params.categoryID != 0 && tx.CategoryID != params.categoryID:
+		return false
+	case !params.since.IsZero() && !params.since.Before(tx.EntryDate):
+		return false
+	case !params.until.IsZero() && tx.EntryDate.After(params.until):
+		return false
+	}
+	if params.minAmount != nil {
+		amount := transactionAmount(tx)
+		if amount.Abs(amount).Cmp(params.minAmount) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// transactionAmount returns the signed amount of tx, or zero if it has none.
+func transactionAmount(tx bosgo.Transaction) *big.Rat {
+	if tx.Amount != nil {
+		if r, ok := new(big.Rat).SetString(tx.Amount.Value); ok {
+			return r
+		}
+	}
+	return new(big.Rat)
 }
 
 func (s *Server) handleScheduledTransactions(w http.ResponseWriter, req *http.Request) {
@@ -1388,7 +2457,91 @@ func (s *Server) handleScheduledTransactions(w http.ResponseWriter, req *http.Re
 		return
 	}
 
-	s.sendJSON(w, http.StatusOK, user.ScheduledTransactions)
+	params, ok := s.parseTransactionParams(w, req)
+	if !ok {
+		return
+	}
+
+	txs, _ := pageTransactions(user.ScheduledTransactions, params)
+	if txs == nil {
+		txs = []bosgo.Transaction{}
+	}
+
+	s.sendJSON(w, http.StatusOK, txs)
+}
+
+func (s *Server) handleBeneficiaries(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		beneficiaries := user.Beneficiaries
+		if beneficiaries == nil {
+			beneficiaries = []bosgo.Beneficiary{}
+		}
+		s.sendJSON(w, http.StatusOK, beneficiaries)
+	case http.MethodPost:
+		var addr bosgo.TransferAddress
+		if !s.readJSON(w, req, &addr) {
+			return
+		}
+		if addr.IBAN == "" {
+			s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"iban"}})
+			return
+		}
+
+		b := bosgo.Beneficiary{
+			ID: s.nextID(),
+			RemoteAcc: bosgo.AccountRef{
+				IBAN:  addr.IBAN,
+				Label: addr.Name,
+			},
+		}
+		user.Beneficiaries = append(user.Beneficiaries, b)
+		s.SetUser(user)
+		s.sendJSON(w, http.StatusCreated, b)
+	default:
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleBeneficiary(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	id, err := strconv.ParseInt(req.URL.Path[len("/v1/beneficiaries/"):], 10, 64)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "validation_bad_parameters")
+		return
+	}
+
+	idx := -1
+	for i, b := range user.Beneficiaries {
+		if b.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		s.sendJSON(w, http.StatusOK, user.Beneficiaries[idx])
+	case http.MethodDelete:
+		user.Beneficiaries = append(user.Beneficiaries[:idx:idx], user.Beneficiaries[idx+1:]...)
+		s.SetUser(user)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+	}
 }
 
 func (s *Server) handleRepeatedTransactions(w http.ResponseWriter, req *http.Request) {
@@ -1407,6 +2560,13 @@ func (s *Server) handleRepeatedTransactions(w http.ResponseWriter, req *http.Req
 	case http.MethodPut:
 		s.updateRepeatedTransaction(w, req, user)
 		return
+	case http.MethodPost:
+		if strings.HasSuffix(req.URL.Path, "/skip") {
+			s.skipRepeatedTransaction(w, req, user)
+			return
+		}
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
 	default:
 		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
@@ -1510,6 +2670,46 @@ accessloop:
 	s.sendJSON(w, http.StatusCreated, &tr.Transfer)
 }
 
+// skipRepeatedTransaction skips the next occurrence of a repeated transaction.
+// The test server treats the start of the schedule as the next occurrence.
+func (s *Server) skipRepeatedTransaction(w http.ResponseWriter, req *http.Request, user User) {
+	id := strings.TrimSuffix(req.URL.Path[26:], "/skip")
+	rtxID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "general")
+		return
+	}
+
+	for i, rtx := range user.RepeatedTransactions {
+		if rtx.ID != rtxID {
+			continue
+		}
+
+		// The next payment is the first occurrence on or after Start, which
+		// differs from Start when the rule has a ByDay
+		due := rtx.Schedule.Next(rtx.Schedule.Start.Add(-time.Nanosecond))
+		next := rtx.Schedule.Next(due)
+		if due.IsZero() || next.IsZero() {
+			s.sendError(w, http.StatusBadRequest, "validation_bad_parameters")
+			return
+		}
+		// Keep monthly payments on the original day of the month once Start
+		// moves, so that a rule starting on the 31st is not pinned to the 28th
+		// after skipping February
+		if rtx.Schedule.Frequency == bosgo.FrequencyMonthly && rtx.Schedule.ByDay == 0 {
+			rtx.Schedule.ByDay = rtx.Schedule.Start.Day()
+		}
+		rtx.Schedule.Start = next
+		user.RepeatedTransactions[i] = rtx
+		s.SetUser(user)
+
+		s.sendJSON(w, http.StatusOK, rtx)
+		return
+	}
+
+	s.sendError(w, http.StatusNotFound, "resource_not_found")
+}
+
 func (s *Server) requireRepeatedTransactions(user User, id int64) (bosgo.RepeatedTransaction, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1571,7 +2771,12 @@ type transferParams struct {
 }
 
 func (s *Server) handleTransfers(w http.ResponseWriter, req *http.Request) {
-	if req.Method != http.MethodPost {
+	switch req.Method {
+	case http.MethodGet:
+		s.handleTransfersList(w, req)
+		return
+	case http.MethodPost:
+	default:
 		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -1610,13 +2815,46 @@ accessloop:
 	s.sendJSON(w, http.StatusCreated, &tr.Transfer)
 }
 
+func (s *Server) handleTransfersList(w http.ResponseWriter, req *http.Request) {
+	user, _, found := s.requireUser(w, req)
+	if !found {
+		return
+	}
+
+	state := bosgo.TransferState(req.URL.Query().Get("state"))
+
+	s.mu.Lock()
+	list := []bosgo.Transfer{}
+	for _, tr := range s.Transfers {
+		if tr.UserID != user.ID {
+			continue
+		}
+		if state != "" && tr.Transfer.State != state {
+			continue
+		}
+		list = append(list, tr.Transfer)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.Before(list[j].Created)
+		}
+		return list[i].ID < list[j].ID
+	})
+	s.sendJSON(w, http.StatusOK, list)
+}
+
 func (s *Server) handleTransfer(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
+	case http.MethodGet:
+		s.handleTransferGet(w, req)
+		return
 	case http.MethodPost:
 		s.handleTransferProcess(w, req)
 		return
 	case http.MethodPut:
-		s.sendError(w, http.StatusInternalServerError, "not_implemented_by_test_server")
+		s.handleTransferUpdate(w, req)
 		return
 	case http.MethodDelete:
 		s.handleTransferDelete(w, req)
@@ -1631,6 +2869,7 @@ type transferProcessParams struct {
 	Intent           bosgo.TransferIntent      `json:"intent"`
 	Version          int                       `json:"version,omitempty"`
 	Type             bosgo.TransferType        `json:"type"`
+	Confirm          bool                      `json:"confirm,omitempty"`
 	ChallengeAnswers bosgo.ChallengeAnswerList `json:"challenge_answers,omitempty"`
 }
 
@@ -1668,12 +2907,85 @@ func (s *Server) handleTransferProcess(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if tr.Transfer.Step.Intent == bosgo.TransferIntentConfirmSimilarTransfer && !data.Confirm {
+		tr.Transfer.Errors = append(tr.Transfer.Errors, bosgo.Problem{Code: "confirmation_required"})
+		s.sendJSON(w, http.StatusOK, &tr.Transfer)
+		return
+	}
+
 	s.progressTransfer(&tr, data.ChallengeAnswers)
 	s.setTransfer(tr)
 
 	s.sendJSON(w, http.StatusOK, &tr.Transfer)
 }
 
+type transferUpdateParams struct {
+	Version int                    `json:"version,omitempty"`
+	Type    bosgo.TransferType     `json:"type"`
+	From    int64                  `json:"from,omitempty"`
+	To      *bosgo.TransferAddress `json:"to,omitempty"`
+	Amount  *bosgo.MoneyAmount     `json:"amount,omitempty"`
+	Usage   *string                `json:"usage,omitempty"`
+}
+
+func (s *Server) handleTransferUpdate(w http.ResponseWriter, req *http.Request) {
+	var data transferUpdateParams
+
+	if !s.readJSON(w, req, &data) {
+		return
+	}
+
+	tr, found := s.requireTransfer(w, req, data.Type)
+	if !found {
+		return
+	}
+
+	if data.From != 0 && data.From != tr.Transfer.From.AccountID {
+		s.sendError(w, http.StatusBadRequest, "transfer_source_immutable")
+		return
+	}
+
+	if data.Version != tr.Transfer.Version {
+		tr.Transfer.Errors = append(tr.Transfer.Errors, bosgo.Problem{Code: "versions_mismatch"})
+		s.sendJSON(w, http.StatusOK, &tr.Transfer)
+		return
+	}
+
+	if tr.Transfer.State != bosgo.TransferStateOngoing {
+		tr.Transfer.Errors = append(tr.Transfer.Errors, bosgo.Problem{Code: "state_" + string(tr.Transfer.State) + "_unprocessable"})
+		s.sendJSON(w, http.StatusOK, &tr.Transfer)
+		return
+	}
+
+	if data.To != nil {
+		tr.Transfer.To = *data.To
+	}
+	if data.Amount != nil {
+		tr.Transfer.Amount = data.Amount
+	}
+	if data.Usage != nil {
+		tr.Transfer.Usage = *data.Usage
+	}
+	tr.Transfer.Version++
+	s.setTransfer(tr)
+
+	s.sendJSON(w, http.StatusOK, &tr.Transfer)
+}
+
+func (s *Server) handleTransferGet(w http.ResponseWriter, req *http.Request) {
+	transferType := bosgo.TransferType(req.URL.Query().Get("type"))
+	if transferType == "" {
+		transferType = bosgo.TransferTypeRegular
+	}
+
+	tr, found := s.requireTransfer(w, req, transferType)
+	if !found {
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, &tr.Transfer)
+}
+
 func (s *Server) handleTransferDelete(w http.ResponseWriter, req *http.Request) {
 	var data transferParams
 
@@ -1685,7 +2997,17 @@ func (s *Server) handleTransferDelete(w http.ResponseWriter, req *http.Request)
 	if !found {
 		return
 	}
-	_ = tr
+
+	if tr.Transfer.State == bosgo.TransferStateSucceeded {
+		s.sendError(w, http.StatusConflict, "transfer_already_succeeded")
+		return
+	}
+
+	tr.Transfer.State = bosgo.TransferStateCancelled
+	tr.Transfer.Step = bosgo.TransferStep{}
+	s.setTransfer(tr)
+
+	s.sendJSON(w, http.StatusOK, &tr.Transfer)
 }
 
 func deleteAccess(user User, access bosgo.Access) (User, bosgo.Access) {
@@ -1729,10 +3051,600 @@ func deleteAccess(user User, access bosgo.Access) (User, bosgo.Access) {
 	return user, access
 }
 
+func (s *Server) handleDeveloper(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		s.handleDeveloperCreate(w, req)
+		return
+	case http.MethodDelete:
+	default:
+		s.sendError(w, http.StatusInternalServerError, "not_implemented_by_test_server")
+		return
+	}
+
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+	if !s.requireConfirmation(w, req, dev) {
+		return
+	}
+
+	s.deleteDev(dev.ID)
+	s.sendNoContent(w)
+}
+
+func (s *Server) handleDeveloperCreate(w http.ResponseWriter, req *http.Request) {
+	var creds bosgo.DeveloperCredentials
+	if !s.readJSON(w, req, &creds) {
+		return
+	}
+
+	if creds.Email == "" {
+		s.sendError(w, http.StatusBadRequest, "authentication_email_invalid")
+		return
+	}
+
+	if creds.Password == "" {
+		s.sendError(w, http.StatusBadRequest, "authentication_secret_blank")
+		return
+	}
+
+	dev := Dev{
+		ID:       s.nextIDStr(),
+		Email:    creds.Email,
+		Password: creds.Password,
+	}
+
+	// Check the email is unique and insert the developer under one lock so
+	// that concurrent requests cannot both create the same developer
+	s.mu.Lock()
+	for _, d := range s.Devs {
+		if d.Email == creds.Email {
+			s.mu.Unlock()
+			s.sendError(w, http.StatusBadRequest, "authentication_email_not_unique")
+			return
+		}
+	}
+	s.Devs[dev.ID] = dev
+	s.mu.Unlock()
+
+	s.sendJSON(w, http.StatusCreated, devToken{Token: s.setDevLoggedIn(dev.ID)})
+}
+
+func (s *Server) handleDeveloperLogin(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var creds bosgo.DeveloperCredentials
+	if !s.readJSON(w, req, &creds) {
+		return
+	}
+
+	s.mu.Lock()
+	var dev Dev
+	for _, d := range s.Devs {
+		if d.Email == creds.Email && d.Password == creds.Password {
+			dev = d
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if dev.ID == "" {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, devToken{Token: s.setDevLoggedIn(dev.ID)})
+}
+
+func (s *Server) handleDeveloperLogout(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, found := s.requireDev(w, req); !found {
+		return
+	}
+	s.setDevLoggedOut(req.Header.Get("X-Token"))
+	s.sendNoContent(w)
+}
+
+func (s *Server) handleApplications(w http.ResponseWriter, req *http.Request) {
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		list := []bosgo.ApplicationMetadata{}
+		for _, app := range s.Apps {
+			if app.DeveloperID == dev.ID {
+				list = append(list, bosgo.ApplicationMetadata{ApplicationID: app.ID, Label: app.Label})
+			}
+		}
+		s.mu.Unlock()
+
+		sort.Slice(list, func(i, j int) bool { return list[i].ApplicationID < list[j].ApplicationID })
+		s.sendJSON(w, http.StatusOK, list)
+
+	case http.MethodPost:
+		var data bosgo.ApplicationMetadata
+		if !s.readJSON(w, req, &data) {
+			return
+		}
+		if data.Label == "" {
+			s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"label"}})
+			return
+		}
+
+		app := App{
+			ID:          s.nextIDStr(),
+			DeveloperID: dev.ID,
+			Label:       data.Label,
+		}
+		s.setApp(app)
+		s.sendJSON(w, http.StatusCreated, bosgo.ApplicationMetadata{ApplicationID: app.ID, Label: app.Label})
+
+	default:
+		s.sendError(w, http.StatusInternalServerError, "not_implemented_by_test_server")
+	}
+}
+
+func (s *Server) handleDevUsers(w http.ResponseWriter, req *http.Request) {
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+	app, found := s.requireApp(w, req)
+	if !found {
+		return
+	}
+	if app.DeveloperID != dev.ID {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	var params bosgo.PageParams
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if !s.readJSON(w, req, &params) {
+			return
+		}
+		if params.Limit < 0 {
+			s.sendError(w, http.StatusBadRequest, "general")
+			return
+		}
+	default:
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	ids := []string{}
+	for _, user := range s.Users {
+		if user.ApplicationID == app.ID {
+			ids = append(ids, user.ID)
+		}
+	}
+	s.mu.Unlock()
+
+	// The cursor is the ID of the last user on the previous page, so paging
+	// is stable while users are added or removed.
+	sort.Strings(ids)
+	if params.Cursor != "" {
+		ids = ids[sort.SearchStrings(ids, params.Cursor):]
+		if len(ids) > 0 && ids[0] == params.Cursor {
+			ids = ids[1:]
+		}
+	}
+
+	var page bosgo.UserListPage
+	if params.Limit > 0 && len(ids) > params.Limit {
+		ids = ids[:params.Limit]
+		page.NextCursor = ids[len(ids)-1]
+	}
+	page.Users = ids
+	s.sendJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleApplication(w http.ResponseWriter, req *http.Request) {
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+
+	path := strings.Split(req.URL.Path[len("/v1/developers/applications/"):], "/")
+
+	app, exists := s.getApp(path[0])
+	if !exists {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	if app.DeveloperID != dev.ID {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	switch {
+	case len(path) == 1 && req.Method == http.MethodPut:
+		var data bosgo.ApplicationMetadata
+		if !s.readJSON(w, req, &data) {
+			return
+		}
+		if data.Label == "" {
+			s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"label"}})
+			return
+		}
+		app.Label = data.Label
+		s.setApp(app)
+		s.sendNoContent(w)
+	case len(path) == 1 && req.Method == http.MethodDelete:
+		if !s.requireConfirmation(w, req, dev) {
+			return
+		}
+		s.mu.Lock()
+		delete(s.Apps, app.ID)
+		s.mu.Unlock()
+		s.sendNoContent(w)
+	case len(path) == 2 && path[1] == "keys" && req.Method == http.MethodGet:
+		keys := app.Keys
+		if keys == nil {
+			keys = []bosgo.ApplicationKey{}
+		}
+		s.sendJSON(w, http.StatusOK, keys)
+	case len(path) == 2 && path[1] == "keys" && req.Method == http.MethodPost:
+		key := bosgo.ApplicationKey{
+			Key:       s.nextIDStr(),
+			CreatedAt: time.Now(),
+		}
+		app.Keys = append(app.Keys, key)
+		s.setApp(app)
+		s.sendJSON(w, http.StatusCreated, key)
+	case len(path) == 3 && path[1] == "keys" && req.Method == http.MethodDelete:
+		if !s.requireConfirmation(w, req, dev) {
+			return
+		}
+		for i, key := range app.Keys {
+			if key.Key == path[2] {
+				app.Keys = append(app.Keys[:i:i], app.Keys[i+1:]...)
+				s.setApp(app)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+	default:
+		s.sendError(w, http.StatusInternalServerError, "not_implemented_by_test_server")
+	}
+}
+
+func (s *Server) handleApplicationKey(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+
+	keyID := req.URL.Path[len("/v1/developers/application_keys/"):]
+	s.mu.Lock()
+	var app App
+	found = false
+	for _, a := range s.Apps {
+		if a.DeveloperID != dev.ID {
+			continue
+		}
+		for _, key := range a.Keys {
+			if key.Key == keyID {
+				app, found = a, true
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	if !s.requireConfirmation(w, req, dev) {
+		return
+	}
+
+	for i, key := range app.Keys {
+		if key.Key == keyID {
+			app.Keys = append(app.Keys[:i:i], app.Keys[i+1:]...)
+			break
+		}
+	}
+	s.setApp(app)
+	s.sendNoContent(w)
+}
+
+func (s *Server) getTeam(id string) (Team, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team, exists := s.Teams[id]
+	return team, exists
+}
+
+func (s *Server) setTeam(team Team) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Teams[team.ID] = team
+}
+
+// InviteToken returns the token of an outstanding invite for email to join the team.
+func (s *Server) InviteToken(teamID string, email string) (string, bool) {
+	team, exists := s.getTeam(teamID)
+	if !exists {
+		return "", false
+	}
+	for token, invited := range team.Invites {
+		if invited == email {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+func (t *Team) member(devID string) (int, bool) {
+	for i, m := range t.Members {
+		if m.DevID == devID {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (s *Server) teamResponse(team Team, devID string) bosgo.Team {
+	return bosgo.Team{
+		ID:        team.ID,
+		Name:      team.Name,
+		Owner:     team.OwnerID == devID,
+		Members:   s.teamMembers(team),
+		CreatedAt: team.CreatedAt,
+	}
+}
+
+func (s *Server) teamMembers(team Team) []bosgo.TeamMember {
+	members := []bosgo.TeamMember{}
+	for _, m := range team.Members {
+		dev, _ := s.getDev(m.DevID)
+		members = append(members, bosgo.TeamMember{
+			ID:        m.DevID,
+			Email:     dev.Email,
+			Owner:     m.DevID == team.OwnerID,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return members
+}
+
+func (s *Server) handleTeams(w http.ResponseWriter, req *http.Request) {
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		var teams []Team
+		for _, team := range s.Teams {
+			if _, isMember := team.member(dev.ID); isMember {
+				teams = append(teams, team)
+			}
+		}
+		s.mu.Unlock()
+
+		sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+		list := []bosgo.Team{}
+		for _, team := range teams {
+			list = append(list, s.teamResponse(team, dev.ID))
+		}
+		s.sendJSON(w, http.StatusOK, list)
+
+	case http.MethodPost:
+		var data bosgo.TeamNew
+		if !s.readJSON(w, req, &data) {
+			return
+		}
+		if data.Name == "" {
+			s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"name"}})
+			return
+		}
+
+		now := time.Now()
+		team := Team{
+			ID:        s.nextIDStr(),
+			Name:      data.Name,
+			OwnerID:   dev.ID,
+			CreatedAt: now,
+			Members:   []TeamMember{{DevID: dev.ID, CreatedAt: now}},
+			Invites:   map[string]string{},
+		}
+		s.setTeam(team)
+		s.sendJSON(w, http.StatusCreated, s.teamResponse(team, dev.ID))
+
+	default:
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleTeam(w http.ResponseWriter, req *http.Request) {
+	dev, found := s.requireDev(w, req)
+	if !found {
+		return
+	}
+
+	path := strings.Split(req.URL.Path[len("/v1/developers/teams/"):], "/")
+
+	if len(path) == 2 && path[0] == "invite" && path[1] == "accept" {
+		if req.Method != http.MethodPost {
+			http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleTeamInviteAccept(w, req, dev)
+		return
+	}
+
+	team, exists := s.getTeam(path[0])
+	if !exists {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+	if _, isMember := team.member(dev.ID); !isMember {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	switch {
+	case len(path) == 2 && path[1] == "invite" && req.Method == http.MethodPost:
+		s.handleTeamInvite(w, req, dev, team)
+	case len(path) == 2 && path[1] == "members" && req.Method == http.MethodGet:
+		s.sendJSON(w, http.StatusOK, s.teamMembers(team))
+	case len(path) == 4 && path[1] == "members" && path[3] == "access" && req.Method == http.MethodPut:
+		s.handleTeamMemberAccess(w, req, dev, team, path[2])
+	default:
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+	}
+}
+
+func (s *Server) handleTeamInvite(w http.ResponseWriter, req *http.Request, dev Dev, team Team) {
+	if team.OwnerID != dev.ID {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	var data bosgo.TeamInvite
+	if !s.readJSON(w, req, &data) {
+		return
+	}
+	if data.Email == "" {
+		s.sendErrorPayload(w, http.StatusBadRequest, "validation_bad_parameters", map[string][]string{"field_key": {"email"}})
+		return
+	}
+
+	token := s.nextIDStr()
+
+	s.mu.Lock()
+	team = s.Teams[team.ID]
+	if team.Invites == nil {
+		team.Invites = map[string]string{}
+	}
+	team.Invites[token] = data.Email
+	s.Teams[team.ID] = team
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTeamInviteAccept(w http.ResponseWriter, req *http.Request, dev Dev) {
+	var data bosgo.TeamInviteToken
+	if !s.readJSON(w, req, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	var team Team
+	var exists bool
+	for _, t := range s.Teams {
+		if email, invited := t.Invites[data.Token]; invited && email == dev.Email {
+			team, exists = t, true
+			break
+		}
+	}
+	if exists {
+		delete(team.Invites, data.Token)
+		if _, isMember := team.member(dev.ID); !isMember {
+			team.Members = append(team.Members, TeamMember{DevID: dev.ID, CreatedAt: time.Now()})
+		}
+		s.Teams[team.ID] = team
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, bosgo.TeamInviteResponse{
+		TeamName: team.Name,
+		Success:  true,
+	})
+}
+
+func (s *Server) handleTeamMemberAccess(w http.ResponseWriter, req *http.Request, dev Dev, team Team, memberID string) {
+	if team.OwnerID != dev.ID {
+		s.sendError(w, http.StatusUnauthorized, "authentication_failed")
+		return
+	}
+
+	var data bosgo.TeamMemberUpdateAccess
+	if !s.readJSON(w, req, &data) {
+		return
+	}
+
+	now := time.Now()
+	accesses := []bosgo.TeamMemberAccess{}
+	for _, a := range data.Accesses {
+		accesses = append(accesses, bosgo.TeamMemberAccess{
+			ResourceName: a.ResourceName,
+			AccessLevel:  a.AccessLevel,
+			UpdatedAt:    now,
+		})
+	}
+	s.mu.Lock()
+	team = s.Teams[team.ID]
+	idx, isMember := team.member(memberID)
+	if isMember {
+		members := make([]TeamMember, len(team.Members))
+		copy(members, team.Members)
+		members[idx].Accesses = accesses
+		team.Members = members
+		s.Teams[team.ID] = team
+	}
+	s.mu.Unlock()
+
+	if !isMember {
+		s.sendError(w, http.StatusNotFound, "resource_not_found")
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, accesses)
+}
+
 // WriteState writes the current state of the server to w as a series of JSON documents.
+// It may be called while the server is handling requests; the state written
+// is a consistent snapshot taken before any of it is written to w.
 func (s *Server) WriteState(w io.Writer) error {
 	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
+	if err := s.encodeState(&buf); err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// encodeState encodes the state of the server while holding the lock so that
+// handlers cannot change it part way through.
+func (s *Server) encodeState(buf *bytes.Buffer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(buf)
 	if err := enc.Encode(s.Devs); err != nil {
 		return err
 	}
@@ -1757,14 +3669,28 @@ func (s *Server) WriteState(w io.Writer) error {
 	if err := enc.Encode(s.RecurringTransfers); err != nil {
 		return err
 	}
-
-	if _, err := buf.WriteTo(w); err != nil {
+	if err := enc.Encode(s.DevTokens); err != nil {
+		return err
+	}
+	if err := enc.Encode(s.Teams); err != nil {
+		return err
+	}
+	if err := enc.Encode(s.Providers); err != nil {
+		return err
+	}
+	if err := enc.Encode(s.Categories); err != nil {
+		return err
+	}
+	if err := enc.Encode(s.confirmSimilar); err != nil {
 		return err
 	}
 	return nil
 }
 
 // ReadState reads a series of JSON documents from r and replaces the state of the server with the read data.
+// The state is replaced in a single step once it has all been read, so
+// requests handled concurrently see either the old state or the new one.
+// Settings that are not part of the state, such as the logger, are kept.
 func (s *Server) ReadState(r io.Reader) error {
 	dec := json.NewDecoder(r)
 
@@ -1793,7 +3719,37 @@ func (s *Server) ReadState(r io.Reader) error {
 	if err := dec.Decode(&tmp.RecurringTransfers); err != nil {
 		return err
 	}
+	// State written before developer sessions and teams were recorded ends here
+	if err := dec.Decode(&tmp.DevTokens); err != nil && err != io.EOF {
+		return err
+	}
+	if err := dec.Decode(&tmp.Teams); err != nil && err != io.EOF {
+		return err
+	}
+	if err := dec.Decode(&tmp.Providers); err != nil && err != io.EOF {
+		return err
+	}
+	if err := dec.Decode(&tmp.Categories); err != nil && err != io.EOF {
+		return err
+	}
+	if err := dec.Decode(&tmp.confirmSimilar); err != nil && err != io.EOF {
+		return err
+	}
+	if tmp.DevTokens == nil {
+		tmp.DevTokens = make(map[string]string)
+	}
+	if tmp.Teams == nil {
+		tmp.Teams = make(map[string]Team)
+	}
+	if tmp.Providers == nil {
+		tmp.Providers = make(map[string]bosgo.Provider)
+	}
+	if tmp.Categories == nil {
+		tmp.Categories = make(map[int64]bosgo.Category)
+	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.Devs = tmp.Devs
 	s.Apps = tmp.Apps
 	s.Users = tmp.Users
@@ -1802,6 +3758,101 @@ func (s *Server) ReadState(r io.Reader) error {
 	s.Accesses = tmp.Accesses
 	s.Transfers = tmp.Transfers
 	s.RecurringTransfers = tmp.RecurringTransfers
+	s.DevTokens = tmp.DevTokens
+	s.Teams = tmp.Teams
+	s.Providers = tmp.Providers
+	s.Categories = tmp.Categories
+	s.confirmSimilar = tmp.confirmSimilar
+	s.restoreIDs()
 
 	return nil
 }
+
+// restoreIDs moves the ID counters past the largest ID found in the state so
+// that resources created after the state has been read do not reuse the IDs
+// of restored ones. IDs that were not generated by the server, such as those
+// of the default resources, are ignored. The caller must hold s.mu.
+func (s *Server) restoreIDs() {
+	ids := make(map[IDSpace]int64)
+	var max int64
+	see := func(space IDSpace, id int64) {
+		if space != "" && id > ids[space] {
+			ids[space] = id
+		}
+		if id > max {
+			max = id
+		}
+	}
+	seeStr := func(space IDSpace, id string) {
+		if n, err := strconv.ParseInt(id, 16, 64); err == nil {
+			see(space, n)
+		}
+	}
+	seeAccess := func(ac bosgo.Access) {
+		see(IDSpaceAccesses, ac.ID)
+		for _, account := range ac.Accounts {
+			see(IDSpaceAccounts, account.ID)
+		}
+	}
+	seeTransactions := func(txs []bosgo.Transaction) {
+		for _, tx := range txs {
+			see("", tx.ID)
+		}
+	}
+
+	for id := range s.Devs {
+		seeStr("", id)
+	}
+	for token := range s.DevTokens {
+		seeStr("", token)
+	}
+	for id, app := range s.Apps {
+		seeStr("", id)
+		for _, key := range app.Keys {
+			seeStr("", key.Key)
+		}
+	}
+	for id := range s.Teams {
+		seeStr("", id)
+	}
+	for id, user := range s.Users {
+		seeStr(IDSpaceUsers, id)
+		for _, ac := range user.Accesses {
+			seeAccess(ac)
+		}
+		seeTransactions(user.Transactions)
+		seeTransactions(user.ScheduledTransactions)
+		for _, b := range user.Beneficiaries {
+			see("", b.ID)
+		}
+	}
+	for token := range s.UserTokens {
+		seeStr("", token)
+	}
+	for id := range s.Jobs {
+		seeStr(IDSpaceJobs, id)
+	}
+	for _, ad := range s.Accesses {
+		seeAccess(ad.Access)
+		seeTransactions(ad.Transactions)
+		seeTransactions(ad.ScheduledTransactions)
+	}
+	for id := range s.Transfers {
+		seeStr(IDSpaceTransfers, id)
+	}
+	for id := range s.RecurringTransfers {
+		seeStr(IDSpaceTransfers, id)
+	}
+
+	if s.id < max {
+		s.id = max
+	}
+	if s.ids == nil {
+		s.ids = make(map[IDSpace]int64)
+	}
+	for space, id := range ids {
+		if s.ids[space] < id {
+			s.ids[space] = id
+		}
+	}
+}
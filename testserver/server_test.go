@@ -2,9 +2,17 @@ package testserver
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,10 +27,128 @@ func TestUserLogin(t *testing.T) {
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	_, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+	if userClient.UserID != DefaultUserID {
+		t.Errorf("got user id %q, wanted %q", userClient.UserID, DefaultUserID)
+	}
+}
+
+func TestUserResetPassword(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	oldSession, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if err := appClient.Users.ResetPassword(DefaultUsername, "new password").Send(); err != nil {
+		t.Fatalf("failed to reset password: %v", err)
+	}
+
+	if _, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send(); err == nil {
+		t.Errorf("got no error logging in with old password, wanted one")
+	}
+	userClient, err := appClient.Users.Login(DefaultUsername, "new password").Send()
+	if err != nil {
+		t.Fatalf("failed to login with new password: %v", err)
+	}
+	if userClient.UserID != DefaultUserID {
+		t.Errorf("got user id %q, wanted %q", userClient.UserID, DefaultUserID)
+	}
+
+	_, err = oldSession.Accesses.List().Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q using session from before reset, wanted authentication_failed", code)
+	}
+
+	err = appClient.Users.ResetPassword("unknown@example.com", "new password").Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v resetting password of unknown user, wanted not found", err)
+	}
+}
+
+func TestUserChangePassword(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	otherSession, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	err = userClient.ChangePassword("wrong password", "new password").Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q changing password with wrong old password, wanted authentication_failed", code)
+	}
+	if _, err := otherSession.Accesses.List().Send(); err != nil {
+		t.Errorf("got error %v using other session after failed change, wanted none", err)
+	}
+
+	if err := userClient.ChangePassword(DefaultPassword, "new password").Send(); err != nil {
+		t.Fatalf("failed to change password: %v", err)
+	}
+
+	if _, err := userClient.Accesses.List().Send(); err != nil {
+		t.Errorf("got error %v using current session after change, wanted none", err)
+	}
+	_, err = otherSession.Accesses.List().Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q using other session after change, wanted authentication_failed", code)
+	}
+
+	if _, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send(); err == nil {
+		t.Errorf("got no error logging in with old password, wanted one")
+	}
+	if _, err := appClient.Users.Login(DefaultUsername, "new password").Send(); err != nil {
+		t.Errorf("failed to login with new password: %v", err)
+	}
+}
+
+func TestUserFromToken(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
+	token := userClient.SessionToken()
+
+	restored := appClient.UserFromToken(token)
+	if restored.SessionToken() != token {
+		t.Errorf("got session token %q, wanted %q", restored.SessionToken(), token)
+	}
+	if _, err := restored.Accesses.List().Send(); err != nil {
+		t.Fatalf("failed to list accesses with restored session: %v", err)
+	}
+
+	if err := userClient.Logout().Send(); err != nil {
+		t.Fatalf("failed to logout: %v", err)
+	}
+	_, err = restored.Accesses.List().Send()
+	if e, ok := err.(*bosgo.Error); !ok || !e.IsAuth() {
+		t.Errorf("got error %v using restored session after logout, wanted authentication error", err)
+	}
 }
 
 func TestUserLoginFail(t *testing.T) {
@@ -89,6 +215,14 @@ func TestUserCreate(t *testing.T) {
 		t.Fatalf("failed to create user: %v", err)
 	}
 
+	user, exists := s.GetUser(userClient.UserID)
+	if !exists {
+		t.Fatalf("no user found with id %q", userClient.UserID)
+	}
+	if user.Username != "scooby@example.com" {
+		t.Errorf("got username %q, wanted %q", user.Username, "scooby@example.com")
+	}
+
 	// Test user is authorised to see their accesses (even though there are none for the new user)
 	ac, err := userClient.Accesses.List().Send()
 	if err != nil {
@@ -177,6 +311,31 @@ func TestUserCreateDuplicateUsername(t *testing.T) {
 	}
 }
 
+func TestUserCreateOrLogin(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	for i := 0; i < 2; i++ {
+		userClient, err := appClient.Users.Create(DefaultUsername, DefaultPassword).OrLogin().Send()
+		if err != nil {
+			t.Fatalf("failed to create or login user: %v", err)
+		}
+		if userClient.UserID != DefaultUserID {
+			t.Errorf("got user id %q, wanted %q", userClient.UserID, DefaultUserID)
+		}
+	}
+
+	// A different password still fails since the login is rejected
+	_, err := appClient.Users.Create(DefaultUsername, "milkshake").OrLogin().Send()
+	if err == nil {
+		t.Fatalf("no error received, user was able to login")
+	}
+}
+
 func TestUserDelete(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
@@ -190,10 +349,13 @@ func TestUserDelete(t *testing.T) {
 		t.Fatalf("failed to create user: %v", err)
 	}
 
-	_, err = userClient.Delete("sandwich").Send()
+	deleted, err := userClient.Delete("sandwich").Send()
 	if err != nil {
 		t.Fatalf("failed to delete user: %v", err)
 	}
+	if deleted.DeletedUserID != userClient.UserID {
+		t.Errorf("got deleted user id %q, wanted %q", deleted.DeletedUserID, userClient.UserID)
+	}
 
 	// Confirm user cannot login
 	_, err = appClient.Users.Login("scooby@example.com", "sandwich").Send()
@@ -201,6 +363,11 @@ func TestUserDelete(t *testing.T) {
 		t.Fatalf("no error received, user was able to login")
 	}
 
+	// Confirm the session is no longer valid
+	_, err = userClient.Accesses.List().Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q using session of deleted user, wanted authentication_failed", code)
+	}
 }
 
 func TestUserDeleteWrongPassword(t *testing.T) {
@@ -233,40 +400,54 @@ func TestUserDeleteWrongPassword(t *testing.T) {
 
 }
 
-func TestAccessCreateNoLogin(t *testing.T) {
+func TestUserDeleteDeactivate(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
+	if err := s.AssignAccess(DefaultUsername, s.MakeAccess(DefaultProviderID, "retained access")); err != nil {
+		t.Fatalf("failed to assign access: %v", err)
+	}
+
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
-	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+	_, err = userClient.Delete(DefaultPassword).Mode("suspend").Send()
+	if code := errCode(err); code != "validation_bad_parameters" {
+		t.Errorf("got error code %q deleting with unknown mode, wanted validation_bad_parameters", code)
 	}
-	t.Logf("job URI: %s", job.URI)
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
+	deleted, err := userClient.Delete(DefaultPassword).Mode(bosgo.DeleteModeDeactivate).Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to delete user: %v", err)
+	}
+	if deleted.Mode != bosgo.DeleteModeDeactivate {
+		t.Errorf("got mode %q, wanted %q", deleted.Mode, bosgo.DeleteModeDeactivate)
 	}
 
-	if status.Finished != false {
-		t.Errorf("got finished %v, wanted false", status.Finished)
+	user, exists := s.GetUser(DefaultUserID)
+	if !exists {
+		t.Fatalf("deactivated user was removed from server")
+	}
+	if user.DeactivatedAt.IsZero() {
+		t.Errorf("got zero deactivation time, wanted it to be set")
+	}
+	if len(user.Accesses) == 0 {
+		t.Errorf("got no accesses for deactivated user, wanted them to be retained")
 	}
 
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	_, err = appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q logging in as deactivated user, wanted authentication_failed", code)
 	}
 }
 
-func TestAccessCreateWithLogin(t *testing.T) {
+func TestUserDeleteErase(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -275,49 +456,29 @@ func TestAccessCreateWithLogin(t *testing.T) {
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	req := userClient.Accesses.Add(DefaultProviderID)
-
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: DefaultAccessLogin,
-	})
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: DefaultAccessPIN,
-	})
-
-	job, err := req.Send()
+	deleted, err := userClient.Delete(DefaultPassword).Mode(bosgo.DeleteModeErase).Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to delete user: %v", err)
 	}
-	t.Logf("job URI: %s", job.URI)
-
-	status, err := userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+	if deleted.Mode != bosgo.DeleteModeErase {
+		t.Errorf("got mode %q, wanted %q", deleted.Mode, bosgo.DeleteModeErase)
 	}
 
-	if status.Finished != true {
-		t.Errorf("got finished %v, wanted true", status.Finished)
-	}
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
-	}
-	if status.Access == nil {
-		t.Fatalf("got nil access, wanted non-nil")
+	if _, exists := s.GetUser(DefaultUserID); exists {
+		t.Errorf("erased user still exists on server")
 	}
 
-	if status.Access.ProviderID != DefaultProviderID {
-		t.Errorf("got provider id %s, wanted %s", status.Access.ProviderID, DefaultProviderID)
+	_, err = appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q logging in as erased user, wanted authentication_failed", code)
 	}
 }
 
-func TestAccessCreateUnknownProvider(t *testing.T) {
+func TestCategories(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -325,37 +486,31 @@ func TestAccessCreateUnknownProvider(t *testing.T) {
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
-	}
 
-	providerID := "bogus_provider"
-	job, err := userClient.Accesses.Add(providerID).Send()
+	list, err := appClient.Categories.List().Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to list categories: %v", err)
 	}
-	t.Logf("job URI: %s", job.URI)
-
-	status, err := userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+	if len(list) != len(DefaultCategories) {
+		t.Errorf("got %d categories, wanted %d", len(list), len(DefaultCategories))
 	}
 
-	if !status.Finished {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+	want := DefaultCategories[1]
+	cat, err := appClient.Categories.Get(want.ID).Send()
+	if err != nil {
+		t.Fatalf("failed to get category: %v", err)
 	}
-
-	if status.Stage != bosgo.JobStageProblem {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageProblem)
+	if !reflect.DeepEqual(*cat, want) {
+		t.Errorf("got category %+v, wanted %+v", *cat, want)
 	}
 
-	if len(status.Errors) == 0 {
-		t.Errorf("got no errors, wanted at least one")
+	_, err = appClient.Categories.Get(9999).Send()
+	if code := errCode(err); code != "resource_not_found" {
+		t.Errorf("got error code %q for unknown category, wanted resource_not_found", code)
 	}
 }
 
-func TestAccessCreateMultiStep(t *testing.T) {
+func TestCategoriesLocale(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -363,94 +518,85 @@ func TestAccessCreateMultiStep(t *testing.T) {
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-
-	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
-	}
 
-	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
-	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+	defaults := map[int64]bosgo.Category{}
+	for _, cat := range DefaultCategories {
+		defaults[cat.ID] = cat
 	}
-	t.Logf("job URI: %s", job.URI)
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
+	list, err := appClient.Categories.List().Locale("de").Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to list categories: %v", err)
 	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	for _, cat := range list {
+		if want := map[string]string{"de": defaults[cat.ID].Names["de"]}; !reflect.DeepEqual(cat.Names, want) {
+			t.Errorf("got names %v for category %d, wanted %v", cat.Names, cat.ID, want)
+		}
 	}
 
-	req := userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: DefaultAccessLogin,
-	})
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer first challenge: %v", err)
-	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
+	cat, err := appClient.Categories.Get(1).Locale("de-AT").Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to get category: %v", err)
 	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	if want := map[string]string{"de": defaults[1].Names["de"]}; !reflect.DeepEqual(cat.Names, want) {
+		t.Errorf("got names %v for regional locale, wanted base language %v", cat.Names, want)
 	}
-
-	req = userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: DefaultAccessPIN,
-	})
-
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer second challenge: %v", err)
+	if name := cat.Name("de-AT"); name != defaults[1].Names["de"] {
+		t.Errorf("got name %q for regional locale, wanted %q", name, defaults[1].Names["de"])
 	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
+
+	cat, err = appClient.Categories.Get(1).Locale("fr").Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to get category: %v", err)
 	}
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	if want := map[string]string{"en": "Cash"}; !reflect.DeepEqual(cat.Names, want) {
+		t.Errorf("got names %v for missing locale, wanted fallback %v", cat.Names, want)
 	}
-	if status.Finished != true {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+	if name := cat.Name("fr"); name != "Cash" {
+		t.Errorf("got name %q for missing locale, wanted %q", name, "Cash")
 	}
-
 }
 
-func addDefaultAccess(userClient *bosgo.UserClient, store bool) (int64, int64, error) {
-	req := userClient.Accesses.Add(DefaultProviderID)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: DefaultAccessLogin,
-		Store: store,
-	})
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: DefaultAccessPIN,
-		Store: store,
-	})
+func TestProvidersSearchAndGet(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
 
-	job, err := req.Send()
+	provider := bosgo.Provider{
+		ID:      "DE-BIN-37040044",
+		Name:    "Commerzbank",
+		Country: "DE",
+		URL:     "https://www.commerzbank.de",
+	}
+	s.AddProvider(provider)
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	results, err := appClient.Providers.Search("commerz").Send()
 	if err != nil {
-		return 0, 0, err
+		t.Fatalf("failed to search providers: %v", err)
+	}
+	if len(*results) != 1 || (*results)[0].Provider.ID != provider.ID {
+		t.Fatalf("got results %+v, wanted provider %s", *results, provider.ID)
 	}
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
+	got, err := appClient.Providers.Get((*results)[0].Provider.ID).Send()
 	if err != nil {
-		return 0, 0, err
+		t.Fatalf("failed to get provider: %v", err)
 	}
-	if status.Access == nil {
-		return 0, 0, fmt.Errorf("no access found")
+	if !reflect.DeepEqual(*got, provider) {
+		t.Errorf("got provider %+v, wanted %+v", *got, provider)
 	}
 
-	return status.Access.ID, status.Access.Accounts[0].ID, nil
+	_, err = appClient.Providers.Get("unknown").Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v getting unknown provider, wanted not found", err)
+	}
 }
 
-func TestAccessCreateAddsAccessToList(t *testing.T) {
+func TestProvidersGetDefault(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -458,57 +604,156 @@ func TestAccessCreateAddsAccessToList(t *testing.T) {
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	provider, err := appClient.Providers.Get(DefaultProviderID).Send()
 	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
+		t.Fatalf("failed to get provider: %v", err)
 	}
 
-	accessID, _, err := addDefaultAccess(userClient, false)
-	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+	var ids []string
+	for _, ch := range provider.Challenges {
+		ids = append(ids, ch.ID)
+		if ch.ID == ChallengePIN && !ch.Secure {
+			t.Errorf("got insecure pin challenge, wanted secure")
+		}
 	}
+	sort.Strings(ids)
 
-	ac, err := userClient.Accesses.List().Send()
-	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
-	}
-	if len(ac.Accesses) != 1 {
-		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	var want []string
+	for id := range s.Accesses[DefaultProviderID].ChallengeMap {
+		want = append(want, id)
 	}
+	sort.Strings(want)
 
-	if ac.Accesses[0].ID != accessID {
-		t.Errorf("got access id %d, wanted %d", ac.Accesses[0].ID, accessID)
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got challenge ids %v, wanted %v", ids, want)
 	}
 }
 
-func TestGetAccess(t *testing.T) {
+func TestProvidersSearchLimit(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
+	for i := 1; i <= 5; i++ {
+		s.AddProvider(bosgo.Provider{
+			ID:      fmt.Sprintf("DE-BIN-1000000%d", i),
+			Name:    fmt.Sprintf("Sparkasse %d", i),
+			Country: "DE",
+		})
+	}
+	s.AddProvider(bosgo.Provider{ID: "FR-BIN-20000000", Name: "Banque", Country: "FR"})
+
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	results, err := appClient.Providers.Search("sparkasse").Send()
 	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
+		t.Fatalf("failed to search providers: %v", err)
+	}
+	if len(*results) != 5 {
+		t.Errorf("got %d results without limit, wanted 5", len(*results))
 	}
 
-	accessID, _, err := addDefaultAccess(userClient, false)
+	page, err := appClient.Providers.Search("sparkasse").Limit(2).Offset(1).SendPage()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to search providers: %v", err)
+	}
+	if page.Total != 5 {
+		t.Errorf("got total %d, wanted 5", page.Total)
+	}
+	if page.Limit != 2 || page.Offset != 1 {
+		t.Errorf("got limit %d and offset %d, wanted 2 and 1", page.Limit, page.Offset)
+	}
+	var ids []string
+	for _, r := range page.Results {
+		ids = append(ids, r.Provider.ID)
+	}
+	if want := []string{"DE-BIN-10000002", "DE-BIN-10000003"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got providers %v, wanted %v", ids, want)
 	}
 
-	acc, err := userClient.Accesses.Get(accessID).Send()
-	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
+	_, err = appClient.Providers.Search("sparkasse").Limit(-1).Send()
+	if code := errCode(err); code != "validation_bad_parameters" {
+		t.Errorf("got error code %q with negative limit, wanted validation_bad_parameters", code)
 	}
-	if acc.Name != "default access" {
-		t.Errorf("got access %s, wanted %s", acc.Name, "default access")
+}
+
+func TestProvidersSearchFilters(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	s.AddProvider(bosgo.Provider{
+		ID:      "DE-BIN-10000000",
+		Name:    "Bankrs Bank",
+		Country: "DE",
+		Operations: bosgo.ProviderOperations{
+			AllowedOperations: bosgo.ProviderAllowedOperations{AccountBalance: true, PaymentTransfer: true},
+		},
+	})
+	s.AddProvider(bosgo.Provider{
+		ID:      "AT-BIN-20000000",
+		Name:    "Bankrs Bank Austria",
+		Country: "AT",
+		Operations: bosgo.ProviderOperations{
+			AllowedOperations: bosgo.ProviderAllowedOperations{AccountBalance: true},
+		},
+	})
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	testCases := []struct {
+		name string
+		req  *bosgo.ProvidersSearchReq
+		want []string
+	}{
+		{
+			name: "query",
+			req:  appClient.Providers.Search("bankrs"),
+			want: []string{"AT-BIN-20000000", "DE-BIN-10000000"},
+		},
+		{
+			name: "country",
+			req:  appClient.Providers.Search("bankrs").Country("DE"),
+			want: []string{"DE-BIN-10000000"},
+		},
+		{
+			name: "capability",
+			req:  appClient.Providers.Search("bankrs").Capability("transfer"),
+			want: []string{"DE-BIN-10000000"},
+		},
+		{
+			name: "country and capability",
+			req:  appClient.Providers.Search("bankrs").Country("AT").Capability("balance").Capability("transfer"),
+			want: nil,
+		},
+		{
+			name: "query and country",
+			req:  appClient.Providers.Search("austria").Country("AT"),
+			want: []string{"AT-BIN-20000000"},
+		},
+	}
+
+	for _, tc := range testCases {
+		results, err := tc.req.Send()
+		if err != nil {
+			t.Errorf("%s: failed to search providers: %v", tc.name, err)
+			continue
+		}
+		var ids []string
+		for _, r := range *results {
+			ids = append(ids, r.Provider.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.want) {
+			t.Errorf("%s: got providers %v, wanted %v", tc.name, ids, tc.want)
+		}
 	}
 }
 
-func TestUpdateAccess(t *testing.T) {
+func TestAccessCreateNoLogin(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -521,17 +766,10 @@ func TestUpdateAccess(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	// Store details
-	accessID, _, err := addDefaultAccess(userClient, true)
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
-
-	// Confirm refresh works
-	job, err := userClient.Accesses.Refresh(accessID).Send()
-	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
-	}
 	t.Logf("job URI: %s", job.URI)
 
 	status, err := userClient.Jobs.Get(job.URI).Send()
@@ -539,64 +777,67 @@ func TestUpdateAccess(t *testing.T) {
 		t.Fatalf("failed to get job status: %v", err)
 	}
 
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
-	}
-	if status.Finished != true {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+	if status.Finished != false {
+		t.Errorf("got finished %v, wanted false", status.Finished)
 	}
 
-	// Change the access details
-	s.mu.Lock()
-	s.Accesses[DefaultProviderID].ChallengeMap[ChallengePIN] = "4567"
-	s.mu.Unlock()
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+}
 
-	// Confirm refresh is challenged
-	job, err = userClient.Accesses.Refresh(accessID).Send()
-	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+func TestAccessCreateWithLogin(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
-	t.Logf("job URI: %s", job.URI)
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 
-	status, err = userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
-	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	// Update stored answers
-	req := userClient.Accesses.Update(accessID)
+	req := userClient.Accesses.Add(DefaultProviderID)
+
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
 	req.ChallengeAnswer(bosgo.ChallengeAnswer{
 		ID:    ChallengePIN,
-		Value: "4567",
-		Store: true,
+		Value: DefaultAccessPIN,
 	})
 
-	_, err = req.Send()
-	if err != nil {
-		t.Fatalf("failed to update access: %v", err)
-	}
-
-	// Confirm refresh is not challenged
-	job, err = userClient.Accesses.Refresh(accessID).Send()
+	job, err := req.Send()
 	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+		t.Fatalf("failed to add access: %v", err)
 	}
 	t.Logf("job URI: %s", job.URI)
 
-	status, err = userClient.Jobs.Get(job.URI).Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
 		t.Fatalf("failed to get job status: %v", err)
 	}
+
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
 	if status.Stage != bosgo.JobStageImported {
 		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
 	}
+	if status.Access == nil {
+		t.Fatalf("got nil access, wanted non-nil")
+	}
 
+	if status.Access.ProviderID != DefaultProviderID {
+		t.Errorf("got provider id %s, wanted %s", status.Access.ProviderID, DefaultProviderID)
+	}
 }
 
-func TestDeleteAccess(t *testing.T) {
+func TestAccessCreateWithAnswers(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -609,30 +850,27 @@ func TestDeleteAccess(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	accessID, _, err := addDefaultAccess(userClient, false)
+	job, err := userClient.Accesses.AddWithAnswers(DefaultProviderID, bosgo.ChallengeAnswerList{
+		{ID: ChallengeLogin, Value: DefaultAccessLogin},
+		{ID: ChallengePIN, Value: DefaultAccessPIN},
+	}).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	delAccessID, err := userClient.Accesses.Delete(accessID).Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
-	}
-	if delAccessID == 0 {
-		t.Fatal("got 0 deleted Access ID")
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	access, _ := userClient.Accesses.Get(accessID).Send()
-	if access != nil {
-		t.Fatal("got some access, while it should have been deleted")
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
 	}
-
-	txsResp, _ := userClient.Transactions.List().Send()
-	if len(txsResp.Transactions) != 0 {
-		t.Fatal("got some txs, while they should have been deleted with the access")
+	if !status.Finished {
+		t.Errorf("got finished false, wanted true")
 	}
 }
 
-func TestListScheduledTransactions(t *testing.T) {
+func TestAccessCreateUnknownProvider(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -645,21 +883,32 @@ func TestListScheduledTransactions(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	providerID := "bogus_provider"
+	job, err := userClient.Accesses.Add(providerID).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
+	t.Logf("job URI: %s", job.URI)
 
-	txs, err := userClient.ScheduledTransactions.List().Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve scheduled  transactions: %v", err)
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	if len(txs) != 2 {
-		t.Errorf("got %d transactions, wanted 2", len(txs))
+
+	if !status.Finished {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+	if status.Stage != bosgo.JobStageProblem {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageProblem)
+	}
+
+	if len(status.Errors) == 0 {
+		t.Errorf("got no errors, wanted at least one")
 	}
 }
 
-func TestListTransactions(t *testing.T) {
+func TestAccessCreateWrongPINCanContinue(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -672,80 +921,158 @@ func TestListTransactions(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	req := userClient.Accesses.Add(DefaultProviderID)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengeLogin, Value: DefaultAccessLogin})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengePIN, Value: "wrong"})
+	job, err := req.Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	txs, err := userClient.Transactions.List().Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve transactions: %v", err)
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	if len(txs.Transactions) != 3 {
-		t.Errorf("got %d transactions, wanted 3", len(txs.Transactions))
+	if status.Stage != bosgo.JobStageChallenge || status.Challenge == nil {
+		t.Fatalf("got stage %v, wanted %v with a challenge", status.Stage, bosgo.JobStageChallenge)
+	}
+	if !status.Challenge.CanContinue {
+		t.Errorf("got can continue false after wrong pin, wanted true")
 	}
 }
 
-func TestListTransactionsLimit(t *testing.T) {
+func TestAccessCreateOptionalChallengeCanContinue(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
+	providerID := DefaultProviderID + "_optional"
+	access := s.MakeAccess(providerID, "access with optional challenge")
+	s.AddAccess(AccessDetails{
+		Access: *access,
+		ChallengeMap: map[string]string{
+			ChallengeLogin:     DefaultAccessLogin,
+			ChallengePIN:       DefaultAccessPIN,
+			"account_selector": "savings",
+		},
+		OptionalChallenges: map[string]bool{
+			"account_selector": true,
+		},
+	})
+
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	// Only the login is answered, so the pin remains to be answered
+	req := userClient.Accesses.Add(providerID)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengeLogin, Value: DefaultAccessLogin})
+	job, err := req.Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Challenge == nil || !status.Challenge.CanContinue {
+		t.Fatalf("got challenge %+v, wanted one that can continue", status.Challenge)
+	}
 
-	txs, err := userClient.Transactions.List().Limit(2).Send()
+	// Every challenge has been answered but the optional one was answered
+	// wrongly, so it must be answered again
+	answer := userClient.Jobs.Answer(job.URI)
+	answer.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengePIN, Value: DefaultAccessPIN})
+	answer.ChallengeAnswer(bosgo.ChallengeAnswer{ID: "account_selector", Value: "checking"})
+	if _, err := answer.Send(); err != nil {
+		t.Fatalf("failed to answer challenges: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve transactions: %v", err)
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	if len(txs.Transactions) != 2 {
-		t.Errorf("got %d transactions, wanted 3", len(txs.Transactions))
+	if status.Stage != bosgo.JobStageChallenge || status.Challenge == nil {
+		t.Fatalf("got stage %v, wanted %v with a challenge", status.Stage, bosgo.JobStageChallenge)
+	}
+	if status.Challenge.CurStep != status.Challenge.MaxSteps {
+		t.Errorf("got current step %d, wanted %d", status.Challenge.CurStep, status.Challenge.MaxSteps)
+	}
+	if !status.Challenge.CanContinue {
+		t.Errorf("got can continue false with optional challenge to correct, wanted true")
 	}
 }
 
-func TestListTransactionsOffset(t *testing.T) {
+func TestAccessCreateOptionalChallenge(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
+	providerID := DefaultProviderID + "_optional"
+	access := s.MakeAccess(providerID, "access with optional challenge")
+	s.AddAccess(AccessDetails{
+		Access: *access,
+		ChallengeMap: map[string]string{
+			ChallengeLogin:     DefaultAccessLogin,
+			ChallengePIN:       DefaultAccessPIN,
+			"account_selector": "savings",
+		},
+		OptionalChallenges: map[string]bool{
+			"account_selector": true,
+		},
+	})
+
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	job, err := userClient.Accesses.Add(providerID).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
-
-	txs, err := userClient.Transactions.List().Offset(2).Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve transactions: %v", err)
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	if len(txs.Transactions) != 1 {
-		t.Fatalf("got %d transactions, wanted 1", len(txs.Transactions))
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Fatalf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
 	}
 
-	if txs.Transactions[0].Amount.Value != "60.00" {
-		t.Errorf("got value %s, wanted %s", txs.Transactions[0].Amount.Value, "60.00")
+	required := status.RequiredAnswerIDs()
+	sort.Strings(required)
+	if want := []string{ChallengeLogin, ChallengePIN}; !reflect.DeepEqual(required, want) {
+		t.Errorf("got required answers %v, wanted %v", required, want)
+	}
+	if optional, want := status.OptionalAnswerIDs(), []string{"account_selector"}; !reflect.DeepEqual(optional, want) {
+		t.Errorf("got optional answers %v, wanted %v", optional, want)
 	}
 
+	req := userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengeLogin, Value: DefaultAccessLogin})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{ID: ChallengePIN, Value: DefaultAccessPIN})
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer challenges: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if !status.Finished {
+		t.Errorf("got unfinished job, wanted it to finish without the optional answer")
+	}
 }
 
-func TestListTransactionsSince(t *testing.T) {
+func TestAccessCreateNoAccounts(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -758,26 +1085,32 @@ func TestListTransactionsSince(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
-	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+	answers := bosgo.ChallengeAnswerList{
+		{ID: ChallengeLogin, Value: DefaultAccessLogin},
+		{ID: ChallengePIN, Value: DefaultAccessPIN},
 	}
 
-	txs, err := userClient.Transactions.List().Since(time.Date(2017, 7, 28, 0, 0, 0, 0, time.UTC)).Send()
-	if err != nil {
-		t.Fatalf("failed to retrieve transactions: %v", err)
+	status, err := userClient.Accesses.AddWithAnswers(EmptyProviderID, answers).SendAndWait(context.Background())
+	if !errors.Is(err, bosgo.ErrNoAccountsImported) {
+		t.Fatalf("got error %v, wanted ErrNoAccountsImported", err)
 	}
-	if len(txs.Transactions) != 2 {
-		t.Fatalf("got %d transactions, wanted 2", len(txs.Transactions))
+	if status == nil || status.Stage != bosgo.JobStageImported {
+		t.Fatalf("got status %+v, wanted imported job", status)
 	}
-
-	if txs.Transactions[0].Amount.Value != "-24.34" {
-		t.Errorf("got value %s, wanted %s", txs.Transactions[0].Amount.Value, "-24.34")
+	if status.Access.HasAccounts() {
+		t.Errorf("got accounts %+v, wanted none", status.Access.Accounts)
 	}
 
+	status, err = userClient.Accesses.AddWithAnswers(DefaultProviderID, answers).SendAndWait(context.Background())
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	if !status.Access.HasAccounts() {
+		t.Errorf("got no accounts for default provider, wanted some")
+	}
 }
 
-func TestListRepeatedTransactions(t *testing.T) {
+func TestAccessCreateMultiStep(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -786,30 +1119,81 @@ func TestListRepeatedTransactions(t *testing.T) {
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
+	t.Logf("job URI: %s", job.URI)
 
-	txs, err := userClient.RepeatedTransactions.List().Send()
+	status, err := userClient.Jobs.Get(job.URI).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
+		t.Fatalf("failed to get job status: %v", err)
 	}
-	if len(txs.Transactions) != 1 {
-		t.Errorf("got %d transactions, wanted 1", len(txs.Transactions))
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
 	}
-}
 
-func TestCreateTransfer(t *testing.T) {
-	s := NewWithDefaults()
-	if testing.Verbose() {
-		s.SetLogger(t)
-	}
-	defer s.Close()
+	req := userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer first challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+	if status.Challenge == nil {
+		t.Fatalf("got nil challenge, wanted non-nil")
+	}
+	if status.Challenge.CurStep != 1 {
+		t.Errorf("got current step %d, wanted 1", status.Challenge.CurStep)
+	}
+	if status.Challenge.MaxSteps != 2 {
+		t.Errorf("got max steps %d, wanted 2", status.Challenge.MaxSteps)
+	}
+	if !status.Challenge.CanContinue {
+		t.Errorf("got can continue false, wanted true")
+	}
+
+	req = userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: DefaultAccessPIN,
+	})
+
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer second challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+}
+
+func TestAccessCreateMultiStepAnswerStatus(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
@@ -817,97 +1201,3194 @@ func TestCreateTransfer(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, accountID, err := addDefaultAccess(userClient, false)
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	amount := bosgo.MoneyAmount{
-		Currency: "EUR",
-		Value:    "12.50",
+	req := userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
+	status, err := req.Send()
+	if err != nil {
+		t.Fatalf("failed to answer first challenge: %v", err)
+	}
+	if status == nil {
+		t.Fatalf("got nil status, wanted non-nil")
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+	if status.Challenge == nil {
+		t.Fatalf("got nil challenge, wanted non-nil")
+	}
+	if status.Challenge.CurStep != 1 {
+		t.Errorf("got current step %d, wanted 1", status.Challenge.CurStep)
 	}
 
-	addr := bosgo.TransferAddress{
-		Name: "Jane Doe",
-		IBAN: "DE28500105175552834822",
+	var remaining []string
+	for _, c := range status.Challenge.NextChallenges {
+		if c.Previous == "" {
+			remaining = append(remaining, c.ID)
+		}
+	}
+	if len(remaining) != 1 || remaining[0] != ChallengePIN {
+		t.Fatalf("got remaining challenges %v, wanted [%s]", remaining, ChallengePIN)
 	}
 
-	// Create the transfer
-	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	req = userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    remaining[0],
+		Value: DefaultAccessPIN,
+	})
+	status, err = req.Send()
 	if err != nil {
-		t.Fatalf("failed to create transfer: %v", err)
+		t.Fatalf("failed to answer second challenge: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if !status.Finished {
+		t.Errorf("got finished false, wanted true")
+	}
+	if status.Access == nil {
+		t.Errorf("got nil access, wanted non-nil")
 	}
+}
 
-	if len(transfer.Errors) > 0 {
-		for _, pr := range transfer.Errors {
-			t.Logf("got unexpected error: %s", pr.Code)
+// fastClock is a bosgo.Clock that shortens every wait so polling tests run quickly.
+type fastClock struct{}
+
+func (fastClock) Now() time.Time { return time.Now() }
+
+func (fastClock) After(d time.Duration) <-chan time.Time { return time.After(10 * time.Millisecond) }
+
+func TestJobWatch(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	client := bosgo.New(s.Client(), s.Addr(), bosgo.WithClock(fastClock{}))
+	appClient := client.WithApplicationKey(DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	statuses, cancel := userClient.Jobs.Watch(job.URI)
+	defer cancel()
+
+	next := func() bosgo.JobStatus {
+		select {
+		case status, ok := <-statuses:
+			if !ok {
+				t.Fatalf("status channel closed unexpectedly")
+			}
+			return status
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for job status")
 		}
-		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
+		return bosgo.JobStatus{}
 	}
 
-	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+	status := next()
+	if status.Stage != bosgo.JobStageChallenge || status.Challenge == nil || status.Challenge.CurStep != 0 {
+		t.Fatalf("got status %+v, wanted challenge at step 0", status)
 	}
 
-	// Send the pin
-	req := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	if _, err := userClient.Jobs.Answer(job.URI).ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	}).Send(); err != nil {
+		t.Fatalf("failed to answer first challenge: %v", err)
+	}
+
+	status = next()
+	if status.Stage != bosgo.JobStageChallenge || status.Challenge == nil || status.Challenge.CurStep != 1 {
+		t.Fatalf("got status %+v, wanted challenge at step 1", status)
+	}
+
+	if _, err := userClient.Jobs.Answer(job.URI).ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: DefaultAccessPIN,
+	}).Send(); err != nil {
+		t.Fatalf("failed to answer second challenge: %v", err)
+	}
+
+	status = next()
+	if status.Stage != bosgo.JobStageImported || !status.Finished {
+		t.Fatalf("got status %+v, wanted finished import", status)
+	}
+
+	select {
+	case _, ok := <-statuses:
+		if ok {
+			t.Errorf("got status after job finished, wanted channel to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("timed out waiting for status channel to be closed")
+	}
+}
+
+func TestJobWatchCancel(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	client := bosgo.New(s.Client(), s.Addr(), bosgo.WithClock(fastClock{}))
+	appClient := client.WithApplicationKey(DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	statuses, cancel := userClient.Jobs.Watch(job.URI)
+	if err := cancel(); err != nil {
+		t.Errorf("got error %v from cancel, wanted nil", err)
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-statuses:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for status channel to be closed")
+		}
+	}
+}
+
+func TestJobWatchError(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	client := bosgo.New(s.Client(), s.Addr(), bosgo.WithClock(fastClock{}))
+	appClient := client.WithApplicationKey(DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	statuses, cancel := userClient.Jobs.Watch("/jobs/unknown")
+
+	select {
+	case _, ok := <-statuses:
+		if ok {
+			t.Errorf("got status for unknown job, wanted channel to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for status channel to be closed")
+	}
+
+	if err := cancel(); !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+}
+
+func addDefaultAccess(userClient *bosgo.UserClient, store bool) (int64, int64, error) {
+	req := userClient.Accesses.Add(DefaultProviderID)
 	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "pin",
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+		Store: store,
+	})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
 		Value: DefaultAccessPIN,
+		Store: store,
 	})
-	transfer, err = req.Send()
+
+	job, err := req.Send()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		return 0, 0, err
+	}
+	if status.Access == nil {
+		return 0, 0, fmt.Errorf("no access found")
+	}
+	if !status.Access.HasAccounts() {
+		return 0, 0, bosgo.ErrNoAccountsImported
+	}
+
+	return status.Access.ID, status.Access.Accounts[0].ID, nil
+}
+
+func TestAccessCreateAddsAccessToList(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	ac, err := userClient.Accesses.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(ac.Accesses) != 1 {
+		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	}
+
+	if ac.Accesses[0].ID != accessID {
+		t.Errorf("got access id %d, wanted %d", ac.Accesses[0].ID, accessID)
+	}
+}
+
+func TestGetAccess(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	acc, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if acc.Name != "default access" {
+		t.Errorf("got access %s, wanted %s", acc.Name, "default access")
+	}
+}
+
+func TestUpdateAccess(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	// Store details
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	// Confirm refresh works
+	job, err := userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+	// Change the access details
+	s.mu.Lock()
+	s.Accesses[DefaultProviderID].ChallengeMap[ChallengePIN] = "4567"
+	s.mu.Unlock()
+
+	// Confirm refresh is challenged
+	job, err = userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	// Update stored answers
+	req := userClient.Accesses.Update(accessID)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: "4567",
+		Store: true,
+	})
+
+	_, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to update access: %v", err)
+	}
+
+	// Confirm refresh is not challenged
+	job, err = userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+
+}
+
+func TestDeleteAccess(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	delAccessID, err := userClient.Accesses.Delete(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if delAccessID == 0 {
+		t.Fatal("got 0 deleted Access ID")
+	}
+	access, _ := userClient.Accesses.Get(accessID).Send()
+	if access != nil {
+		t.Fatal("got some access, while it should have been deleted")
+	}
+
+	txsResp, _ := userClient.Transactions.List().Send()
+	if len(txsResp.Transactions) != 0 {
+		t.Fatal("got some txs, while they should have been deleted with the access")
+	}
+}
+
+func TestListScheduledTransactions(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.ScheduledTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve scheduled  transactions: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("got %d transactions, wanted 2", len(txs))
+	}
+}
+
+func TestListScheduledTransactionsLimit(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.ScheduledTransactions.List().Limit(1).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve scheduled  transactions: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Errorf("got %d transactions, wanted 1", len(txs))
+	}
+}
+
+func TestListScheduledTransactionsOffset(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	all, err := userClient.ScheduledTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve scheduled  transactions: %v", err)
+	}
+
+	txs, err := userClient.ScheduledTransactions.List().Offset(1).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve scheduled  transactions: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d transactions, wanted 1", len(txs))
+	}
+	if txs[0].ID != all[1].ID {
+		t.Errorf("got transaction %d, wanted %d", txs[0].ID, all[1].ID)
+	}
+}
+
+func TestListTransactions(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.Transactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve transactions: %v", err)
+	}
+	if len(txs.Transactions) != 3 {
+		t.Errorf("got %d transactions, wanted 3", len(txs.Transactions))
+	}
+}
+
+func TestListTransactionsLimit(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.Transactions.List().Limit(2).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve transactions: %v", err)
+	}
+	if len(txs.Transactions) != 2 {
+		t.Errorf("got %d transactions, wanted 3", len(txs.Transactions))
+	}
+}
+
+func TestListTransactionsOffset(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.Transactions.List().Offset(2).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve transactions: %v", err)
+	}
+	if len(txs.Transactions) != 1 {
+		t.Fatalf("got %d transactions, wanted 1", len(txs.Transactions))
+	}
+
+	if txs.Transactions[0].Amount.Value != "60.00" {
+		t.Errorf("got value %s, wanted %s", txs.Transactions[0].Amount.Value, "60.00")
+	}
+
+}
+
+func TestListTransactionsSince(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.Transactions.List().Since(time.Date(2017, 7, 28, 0, 0, 0, 0, time.UTC)).Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve transactions: %v", err)
+	}
+	if len(txs.Transactions) != 2 {
+		t.Fatalf("got %d transactions, wanted 2", len(txs.Transactions))
+	}
+
+	if txs.Transactions[0].Amount.Value != "-24.34" {
+		t.Errorf("got value %s, wanted %s", txs.Transactions[0].Amount.Value, "-24.34")
+	}
+
+}
+
+func TestListTransactionsCombinedFilters(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	tx := func(id, accountID, categoryID int64, day int, month time.Month, amount string) bosgo.Transaction {
+		return bosgo.Transaction{
+			ID:            id,
+			UserAccountID: accountID,
+			CategoryID:    categoryID,
+			EntryDate:     time.Date(2017, month, day, 0, 0, 0, 0, time.UTC),
+			Amount:        &bosgo.MoneyAmount{Currency: "EUR", Value: amount},
+		}
+	}
+	s.SetUser(User{
+		ID:            "filter-user",
+		Username:      "filter@example.com",
+		Password:      "filter",
+		ApplicationID: DefaultApplicationKey,
+		Transactions: []bosgo.Transaction{
+			tx(1, 100, 10, 10, time.January, "-20.00"),
+			tx(2, 100, 10, 10, time.February, "-75.00"),
+			tx(3, 100, 20, 10, time.March, "-120.00"),
+			tx(4, 200, 10, 15, time.March, "-60.00"),
+			tx(5, 100, 10, 20, time.March, "500.00"),
+		},
+	})
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login("filter@example.com", "filter").Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	list := func() *bosgo.ListTransactionsReq { return userClient.Transactions.List() }
+	date := func(day int, month time.Month) time.Time { return time.Date(2017, month, day, 0, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name  string
+		req   *bosgo.ListTransactionsReq
+		ids   []int64
+		total int
+	}{
+		{
+			name:  "no filter",
+			req:   list(),
+			ids:   []int64{1, 2, 3, 4, 5},
+			total: 5,
+		},
+		{
+			name:  "account",
+			req:   list().AccountID(100),
+			ids:   []int64{1, 2, 3, 5},
+			total: 4,
+		},
+		{
+			name:  "account and date window",
+			req:   list().AccountID(100).Since(date(1, time.February)).Until(date(15, time.March)),
+			ids:   []int64{2, 3},
+			total: 2,
+		},
+		{
+			name:  "until is inclusive",
+			req:   list().Until(date(10, time.February)),
+			ids:   []int64{1, 2},
+			total: 2,
+		},
+		{
+			name:  "category and min amount",
+			req:   list().CategoryID(10).MinAmount("50.00"),
+			ids:   []int64{2, 4, 5},
+			total: 3,
+		},
+		{
+			name:  "all filters newest first",
+			req:   list().AccountID(100).Since(date(1, time.January)).Until(date(31, time.March)).CategoryID(10).MinAmount("50").SortBy(bosgo.TransactionOrderEntryDateDesc),
+			ids:   []int64{5, 2},
+			total: 2,
+		},
+		{
+			name:  "all filters newest first paged",
+			req:   list().AccountID(100).CategoryID(10).MinAmount("50").SortBy(bosgo.TransactionOrderEntryDateDesc).Limit(1).Offset(1),
+			ids:   []int64{2},
+			total: 2,
+		},
+		{
+			name:  "sorted by amount",
+			req:   list().SortBy(bosgo.TransactionOrderAmount),
+			ids:   []int64{3, 2, 4, 1, 5},
+			total: 5,
+		},
+		{
+			name:  "sorted by amount descending",
+			req:   list().AccountID(100).SortBy(bosgo.TransactionOrderAmountDesc),
+			ids:   []int64{5, 1, 2, 3},
+			total: 4,
+		},
+		{
+			name:  "sorted oldest first",
+			req:   list().MinAmount("100").SortBy(bosgo.TransactionOrderEntryDate),
+			ids:   []int64{3, 5},
+			total: 2,
+		},
+		{
+			name:  "no match for account and category",
+			req:   list().AccountID(200).CategoryID(20),
+			ids:   nil,
+			total: 0,
+		},
+		{
+			name:  "no match for date window",
+			req:   list().Since(date(1, time.April)),
+			ids:   nil,
+			total: 0,
+		},
+		{
+			name:  "no match for min amount",
+			req:   list().MinAmount("1000"),
+			ids:   nil,
+			total: 0,
+		},
+		{
+			name:  "offset beyond results",
+			req:   list().AccountID(100).Offset(10),
+			ids:   nil,
+			total: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		page, err := tc.req.Send()
+		if err != nil {
+			t.Errorf("%s: failed to list transactions: %v", tc.name, err)
+			continue
+		}
+		var ids []int64
+		for _, tx := range page.Transactions {
+			ids = append(ids, tx.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.ids) {
+			t.Errorf("%s: got transactions %v, wanted %v", tc.name, ids, tc.ids)
+		}
+		if page.Total != tc.total {
+			t.Errorf("%s: got total %d, wanted %d", tc.name, page.Total, tc.total)
+		}
+	}
+
+	_, err = list().MinAmount("fifty").Send()
+	if status := errStatusCode(err); status != http.StatusBadRequest {
+		t.Errorf("got http status %d for invalid min amount, wanted %d", status, http.StatusBadRequest)
+	}
+	_, err = list().SortBy("category").Send()
+	if status := errStatusCode(err); status != http.StatusBadRequest {
+		t.Errorf("got http status %d for invalid sort order, wanted %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestListRepeatedTransactions(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(txs.Transactions) != 1 {
+		t.Errorf("got %d transactions, wanted 1", len(txs.Transactions))
+	}
+}
+
+func TestDescribeRepeatedTransaction(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, false); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve repeated transactions: %v", err)
+	}
+	if len(txs.Transactions) != 1 {
+		t.Fatalf("got %d transactions, wanted 1", len(txs.Transactions))
+	}
+	rent := txs.Transactions[0]
+
+	if got, want := rent.Describe(), "500.00 EUR to DE04200800957050250010 monthly"; got != want {
+		t.Errorf("got description %q, wanted %q", got, want)
+	}
+
+	after := time.Date(2017, 6, 15, 0, 0, 0, 0, time.UTC)
+	amount, date, ok := rent.NextAmount(after)
+	if !ok {
+		t.Fatalf("got no next amount, wanted one")
+	}
+	if amount.Value != "500.00" || amount.Currency != "EUR" {
+		t.Errorf("got next amount %+v, wanted 500.00 EUR", amount)
+	}
+	if want := time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC); !date.Equal(want) {
+		t.Errorf("got next date %v, wanted %v", date, want)
+	}
+
+	// Payments on the 24th of each month from June to December 2017
+	total, ok := rent.TotalCommitted(after)
+	if !ok {
+		t.Fatalf("got no total committed, wanted one")
+	}
+	if want := (bosgo.MoneyAmount{Currency: "EUR", Value: "3500.00"}); total != want {
+		t.Errorf("got total committed %+v, wanted %+v", total, want)
+	}
+}
+
+func TestSkipNextRepeatedTransaction(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	txs, err := userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list repeated transactions: %v", err)
+	}
+	if len(txs.Transactions) != 1 {
+		t.Fatalf("got %d transactions, wanted 1", len(txs.Transactions))
+	}
+	rtx := txs.Transactions[0]
+
+	skipped, err := userClient.RepeatedTransactions.SkipNext(strconv.FormatInt(rtx.ID, 10)).Send()
+	if err != nil {
+		t.Fatalf("failed to skip next occurrence: %v", err)
+	}
+
+	// The default standing order starts on January 1 and is paid on the 24th,
+	// so skipping the January 24 payment moves it to February 24
+	want := time.Date(2017, 2, 24, 0, 0, 0, 0, time.UTC)
+	if !skipped.Schedule.Start.Equal(want) {
+		t.Errorf("got next occurrence %v, wanted %v", skipped.Schedule.Start, want)
+	}
+	if skipped.Schedule.Frequency != rtx.Schedule.Frequency || !skipped.Schedule.Until.Equal(rtx.Schedule.Until) {
+		t.Errorf("got schedule %+v, wanted rule to be unchanged", skipped.Schedule)
+	}
+
+	txs, err = userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list repeated transactions: %v", err)
+	}
+	if !txs.Transactions[0].Schedule.Start.Equal(want) {
+		t.Errorf("got stored next occurrence %v, wanted %v", txs.Transactions[0].Schedule.Start, want)
+	}
+
+	_, err = userClient.RepeatedTransactions.SkipNext("9999").Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+}
+
+func TestCreateTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	// Create the transfer
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	if len(transfer.Errors) > 0 {
+		for _, pr := range transfer.Errors {
+			t.Logf("got unexpected error: %s", pr.Code)
+		}
+		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
+	}
+
+	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	// Send the pin
+	req := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "pin",
+		Value: DefaultAccessPIN,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	}
+	if transfer.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if len(transfer.Step.Data.AuthMethods) != 1 {
+		t.Fatalf("got %d auth methods, wanted 1", len(transfer.Step.Data.AuthMethods))
+	}
+	if transfer.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
+		t.Errorf("got auth method %v, wanted %v", transfer.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	}
+
+	// Send the auth method
+	req = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "auth_method",
+		Value: DefaultAuthMethod,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth method: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	}
+	if transfer.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if transfer.Step.Data.ChallengeMessage != DefaultAuthMessage {
+		t.Errorf("got challenge message %v, wanted %v", transfer.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	}
+
+	// Send the auth answer
+	req = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "tan",
+		Value: DefaultAuthAnswer,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth answer: %v", err)
+	}
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+	if transfer.EntryDate.IsZero() {
+		t.Errorf("got zero entry date, wanted it to be set")
+	}
+	if settled, ok := transfer.SettledAt(); !ok || settled.IsZero() {
+		t.Errorf("got settlement date %v (known: %v), wanted it to be set", settled, ok)
+	}
+}
+
+func TestTransferResultingTransactions(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, answer := range answers {
+		transfer, err = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).ChallengeAnswer(answer).Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", answer.ID, err)
+		}
+	}
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Fatalf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+
+	txs, err := userClient.Transfers.ResultingTransactions(transfer).Send()
+	if err != nil {
+		t.Fatalf("failed to find resulting transactions: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d resulting transactions, wanted 1", len(txs))
+	}
+	if txs[0].Amount == nil || txs[0].Amount.Value != "-12.50" {
+		t.Errorf("got amount %+v, wanted -12.50 EUR", txs[0].Amount)
+	}
+	if txs[0].Counterparty.Name != addr.Name {
+		t.Errorf("got counterparty %q, wanted %q", txs[0].Counterparty.Name, addr.Name)
+	}
+}
+
+func TestUpdateTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	// An outdated version is rejected
+	stale, err := userClient.Transfers.Update(transfer.ID, transfer.Version+1).Amount(bosgo.MoneyAmount{Currency: "EUR", Value: "99.00"}).Send()
+	if err != nil {
+		t.Fatalf("failed to update transfer: %v", err)
+	}
+	if len(stale.Errors) != 1 || stale.Errors[0].Code != "versions_mismatch" {
+		t.Errorf("got errors %+v, wanted versions_mismatch", stale.Errors)
+	}
+
+	newAmount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "15.00",
+	}
+	transfer, err = userClient.Transfers.Update(transfer.ID, transfer.Version).Amount(newAmount).Send()
+	if err != nil {
+		t.Fatalf("failed to update transfer: %v", err)
+	}
+	if len(transfer.Errors) > 0 {
+		t.Fatalf("got errors %+v, wanted none", transfer.Errors)
+	}
+	if transfer.Amount == nil || *transfer.Amount != newAmount {
+		t.Errorf("got amount %+v, wanted %+v", transfer.Amount, newAmount)
+	}
+	if transfer.To != addr {
+		t.Errorf("got recipient %+v, wanted %+v", transfer.To, addr)
+	}
+
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, ans := range answers {
+		req := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+		req.ChallengeAnswer(ans)
+		transfer, err = req.Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", ans.ID, err)
+		}
+	}
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+	if transfer.Amount == nil || *transfer.Amount != newAmount {
+		t.Errorf("got amount %+v, wanted %+v", transfer.Amount, newAmount)
+	}
+}
+
+func TestUpdateTransferSourceImmutable(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}, bosgo.MoneyAmount{Currency: "EUR", Value: "12.50"}).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	// The client cannot change the source account, so send the update directly
+	update := func(from int64) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"version":%d,"type":"regular","from":%d}`, transfer.Version, from)
+		req := httptest.NewRequest(http.MethodPut, "/v1/transfers/"+transfer.ID, strings.NewReader(body))
+		req.Header.Set("X-Token", userClient.SessionToken())
+		req.Header.Set("X-Application-Key", DefaultApplicationKey)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := update(accountID + 1)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, wanted %d", rec.Code, http.StatusBadRequest)
+	}
+	var resp errorResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Code != "transfer_source_immutable" {
+		t.Errorf("got errors %+v, wanted transfer_source_immutable", resp.Errors)
+	}
+
+	if rec := update(accountID); rec.Code != http.StatusOK {
+		t.Errorf("got status %d for unchanged source account, wanted %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestCreateTransferConfirmSimilar(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+	s.SetConfirmSimilar(true)
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentConfirmSimilarTransfer {
+		t.Fatalf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentConfirmSimilarTransfer)
+	}
+
+	// Processing without confirmation must not advance the transfer
+	unconfirmed, err := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).Send()
+	if err != nil {
+		t.Fatalf("failed to process transfer: %v", err)
+	}
+	if len(unconfirmed.Errors) != 1 || unconfirmed.Errors[0].Code != "confirmation_required" {
+		t.Errorf("got errors %+v, wanted confirmation_required", unconfirmed.Errors)
+	}
+	if unconfirmed.Step.Intent != bosgo.TransferIntentConfirmSimilarTransfer {
+		t.Errorf("got intent %v, wanted %v", unconfirmed.Step.Intent, bosgo.TransferIntentConfirmSimilarTransfer)
+	}
+
+	transfer, err = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).Confirm(true).Send()
+	if err != nil {
+		t.Fatalf("failed to confirm transfer: %v", err)
+	}
+	if len(transfer.Errors) > 0 {
+		t.Fatalf("got errors %+v, wanted none", transfer.Errors)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Fatalf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, answer := range answers {
+		transfer, err = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).ChallengeAnswer(answer).Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", answer.ID, err)
+		}
+	}
+
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+}
+
+func TestDeleteTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	req := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "pin",
+		Value: DefaultAccessPIN,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if transfer.State != bosgo.TransferStateOngoing {
+		t.Fatalf("got state %v, wanted %v", transfer.State, bosgo.TransferStateOngoing)
+	}
+
+	transfer, err = userClient.Transfers.Delete(transfer.ID).Send()
+	if err != nil {
+		t.Fatalf("failed to delete transfer: %v", err)
+	}
+	if transfer.State != bosgo.TransferStateCancelled {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateCancelled)
+	}
+
+	// Cancelled transfers can no longer be processed
+	req = userClient.Transfers.Process(transfer.ID, bosgo.TransferIntentSelectAuthMethod, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "auth_method",
+		Value: DefaultAuthMethod,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth method: %v", err)
+	}
+	if len(transfer.Errors) == 0 {
+		t.Errorf("got no errors, wanted processing of a cancelled transfer to fail")
+	}
+}
+
+func TestDeleteSucceededTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, ans := range answers {
+		req := userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+		req.ChallengeAnswer(ans)
+		transfer, err = req.Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", ans.ID, err)
+		}
+	}
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Fatalf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+
+	_, err = userClient.Transfers.Delete(transfer.ID).Send()
+	if errCode(err) != "transfer_already_succeeded" {
+		t.Errorf("got error code %s, wanted transfer_already_succeeded", errCode(err))
+	}
+}
+
+func TestCreateRecurringTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	rule := bosgo.RecurrenceRule{
+		Start:     time.Now(),
+		Frequency: bosgo.FrequencyMonthly,
+		Interval:  2,
+	}
+	// Create the transfer
+	transfer, err := userClient.RecurringTransfers.Create(accountID, addr, amount, rule, "description/usage").Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	if len(transfer.Errors) > 0 {
+		for _, pr := range transfer.Errors {
+			t.Logf("got unexpected error: %s", pr.Code)
+		}
+		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
+	}
+
+	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	// Send the pin
+	req := userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "pin",
+		Value: DefaultAccessPIN,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	}
+	if transfer.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if len(transfer.Step.Data.AuthMethods) != 1 {
+		t.Fatalf("got %d auth methods, wanted 1", len(transfer.Step.Data.AuthMethods))
+	}
+	if transfer.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
+		t.Errorf("got auth method %v, wanted %v", transfer.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	}
+
+	// Send the auth method
+	req = userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "auth_method",
+		Value: DefaultAuthMethod,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth method: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	}
+	if transfer.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if transfer.Step.Data.ChallengeMessage != DefaultAuthMessage {
+		t.Errorf("got challenge message %v, wanted %v", transfer.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	}
+
+	// Send the auth answer
+	req = userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "tan",
+		Value: DefaultAuthAnswer,
+	})
+	transfer, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth answer: %v", err)
+	}
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+}
+
+func TestCreateRepeatedTransaction(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	rule := bosgo.RecurrenceRule{
+		Start:     time.Now(),
+		Frequency: bosgo.FrequencyMonthly,
+		Interval:  1,
+	}
+	transfer, err := userClient.RepeatedTransactions.Create(accountID, addr, amount, rule, "rent").Send()
+	if err != nil {
+		t.Fatalf("failed to create repeated transaction: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Fatalf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, ans := range answers {
+		req := userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
+		req.ChallengeAnswer(ans)
+		transfer, err = req.Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", ans.ID, err)
+		}
+	}
+
+	if transfer.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	}
+}
+
+func TestDeleteRecurringTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	repeatedTxs, err := userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to get repeated txs: %v", err)
+	}
+
+	if len(repeatedTxs.Transactions) == 0 {
+		t.Fatalf("missing repeated txs on default access")
+	}
+
+	// Delete the repeated tx with unknown ID
+	recTrf, err := userClient.RepeatedTransactions.Delete("9999").Send()
+	if err == nil {
+		t.Fatalf("didn't fail to delete an unknown repeated transaction: %v", err)
+	}
+
+	// Delete the repeated tx with correct ID should
+	rtxID := strconv.Itoa(int(repeatedTxs.Transactions[0].ID))
+	recTrf, err = userClient.RepeatedTransactions.Delete(rtxID).Send()
+	if err != nil {
+		t.Fatalf("failed to delete recTrf: %v", err)
+	}
+
+	if len(recTrf.Errors) > 0 {
+		for _, pr := range recTrf.Errors {
+			t.Logf("got unexpected error: %s", pr.Code)
+		}
+		t.Fatalf("got %d errors, wanted none", len(recTrf.Errors))
+	}
+
+	if recTrf.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	// Send the pin
+	req := userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "pin",
+		Value: DefaultAccessPIN,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if recTrf.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	}
+	if recTrf.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if len(recTrf.Step.Data.AuthMethods) != 1 {
+		t.Fatalf("got %d auth methods, wanted 1", len(recTrf.Step.Data.AuthMethods))
+	}
+	if recTrf.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
+		t.Errorf("got auth method %v, wanted %v", recTrf.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	}
+
+	// Send the auth method
+	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "auth_method",
+		Value: DefaultAuthMethod,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth method: %v", err)
+	}
+	if recTrf.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	}
+	if recTrf.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if recTrf.Step.Data.ChallengeMessage != DefaultAuthMessage {
+		t.Errorf("got challenge message %v, wanted %v", recTrf.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	}
+
+	// Send the auth answer
+	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "tan",
+		Value: DefaultAuthAnswer,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth answer: %v", err)
+	}
+	if recTrf.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", recTrf.State, bosgo.TransferStateSucceeded)
+	}
+}
+
+func TestUpdateRecurringTransfer(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, _, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	repeatedTxs, err := userClient.RepeatedTransactions.List().Send()
+	if err != nil {
+		t.Fatalf("failed to get repeated txs: %v", err)
+	}
+
+	if len(repeatedTxs.Transactions) == 0 {
+		t.Fatalf("missing repeated txs on default access")
+	}
+
+	// update the repeated tx with unknown ID should fail
+	recTrf, err := userClient.RepeatedTransactions.Delete("9999").Send()
+	if err == nil {
+		t.Fatalf("didn't fail to delete an unknown repeated transaction: %v", err)
+	}
+
+	rtx := repeatedTxs.Transactions[0]
+	rtx.Amount.Value = "99.32"
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	// Update the repeated tx with correct ID
+	rtxID := strconv.Itoa(int(repeatedTxs.Transactions[0].ID))
+	recTrf, err = userClient.RepeatedTransactions.Update(rtxID, addr, *rtx.Amount, "this is the updated usage").Send()
+	if err != nil {
+		t.Fatalf("failed to delete recTrf: %v", err)
+	}
+
+	if len(recTrf.Errors) > 0 {
+		for _, pr := range recTrf.Errors {
+			t.Logf("got unexpected error: %s", pr.Code)
+		}
+		t.Fatalf("got %d errors, wanted none", len(recTrf.Errors))
+	}
+
+	if recTrf.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvidePIN)
+	}
+
+	// Send the pin
+	req := userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "pin",
+		Value: DefaultAccessPIN,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if recTrf.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	}
+	if recTrf.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if len(recTrf.Step.Data.AuthMethods) != 1 {
+		t.Fatalf("got %d auth methods, wanted 1", len(recTrf.Step.Data.AuthMethods))
+	}
+	if recTrf.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
+		t.Errorf("got auth method %v, wanted %v", recTrf.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	}
+
+	// Send the auth method
+	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "auth_method",
+		Value: DefaultAuthMethod,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth method: %v", err)
+	}
+	if recTrf.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
+		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	}
+	if recTrf.Step.Data == nil {
+		t.Fatalf("got nil step data, wanted non-nil")
+	}
+	if recTrf.Step.Data.ChallengeMessage != DefaultAuthMessage {
+		t.Errorf("got challenge message %v, wanted %v", recTrf.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	}
+
+	// Send the auth answer
+	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    "tan",
+		Value: DefaultAuthAnswer,
+	})
+	recTrf, err = req.Send()
+	if err != nil {
+		t.Fatalf("failed to process auth answer: %v", err)
+	}
+	if recTrf.State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", recTrf.State, bosgo.TransferStateSucceeded)
+	}
+}
+
+func TestWriteReadState(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteState(&buf); err != nil {
+		t.Fatalf("unexpected error writing state: %v", err)
+	}
+
+	s2 := New()
+	defer s2.Close()
+	s2.ReadState(&buf)
+
+	appClient2 := bosgo.NewAppClient(s2.Client(), s2.Addr(), DefaultApplicationKey)
+	userClient2, err := appClient2.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login to new server as user: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	// Create the transfer
+	transfer, err := userClient2.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	if len(transfer.Errors) > 0 {
+		for _, pr := range transfer.Errors {
+			t.Logf("got unexpected error: %s", pr.Code)
+		}
+		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
+	}
+
+}
+
+func TestReadStateBaselineFormat(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+
+	// State written before developer sessions and teams were recorded
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range []interface{}{s.Devs, s.Apps, s.Users, s.UserTokens, s.Jobs, s.Accesses, s.Transfers, s.RecurringTransfers} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("unexpected error encoding state: %v", err)
+		}
+	}
+
+	s2 := New()
+	defer s2.Close()
+	if err := s2.ReadState(&buf); err != nil {
+		t.Fatalf("unexpected error reading state: %v", err)
+	}
+
+	devClient := bosgo.NewDevClient(s2.Client(), s2.Addr(), s2.setDevLoggedIn(DefaultDeveloperID))
+	if _, err := devClient.Teams.Create(bosgo.TeamNew{Name: "My team"}).Send(); err != nil {
+		t.Errorf("failed to create team: %v", err)
+	}
+}
+
+func TestReadStateRestoresIDs(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+	s.SetIDSeed(5000)
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	if _, err := userClient.Transfers.Create(accountID, addr, amount).Send(); err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteState(&buf); err != nil {
+		t.Fatalf("unexpected error writing state: %v", err)
+	}
+
+	s2 := New()
+	defer s2.Close()
+	if err := s2.ReadState(&buf); err != nil {
+		t.Fatalf("unexpected error reading state: %v", err)
+	}
+	restored := make(map[string]bool)
+	for id := range s2.Transfers {
+		restored[id] = true
+	}
+
+	appClient2 := bosgo.NewAppClient(s2.Client(), s2.Addr(), DefaultApplicationKey)
+	userClient2, err := appClient2.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login to new server as user: %v", err)
+	}
+
+	transfer, err := userClient2.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+	if restored[transfer.ID] {
+		t.Errorf("got transfer id %s, which was already in use", transfer.ID)
+	}
+	if id, _ := strconv.ParseInt(transfer.ID, 16, 64); id <= 5000 {
+		t.Errorf("got transfer id %s, wanted one above the restored ids", transfer.ID)
+	}
+}
+
+func TestWriteReadStateConfirmSimilar(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+	s.SetConfirmSimilar(true)
+
+	var buf bytes.Buffer
+	if err := s.WriteState(&buf); err != nil {
+		t.Fatalf("unexpected error writing state: %v", err)
+	}
+
+	s2 := New()
+	defer s2.Close()
+	s2.SetLogger(t)
+	if err := s2.ReadState(&buf); err != nil {
+		t.Fatalf("unexpected error reading state: %v", err)
+	}
+	if s2.logger == nil {
+		t.Errorf("got nil logger after reading state, wanted it kept")
+	}
+
+	appClient := bosgo.NewAppClient(s2.Client(), s2.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login to new server as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentConfirmSimilarTransfer {
+		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentConfirmSimilarTransfer)
+	}
+}
+
+func TestWriteReadStateConcurrent(t *testing.T) {
+	s := NewWithDefaults()
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				userClient, err := appClient.Users.Create(fmt.Sprintf("user%d-%d@example.com", i, j), "password").Send()
+				if err != nil {
+					t.Errorf("failed to create user: %v", err)
+					return
+				}
+				if _, _, err := addDefaultAccess(userClient, false); err != nil {
+					t.Errorf("failed to add access: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	var snapshots [][]byte
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		if err := s.WriteState(&buf); err != nil {
+			t.Fatalf("unexpected error writing state: %v", err)
+		}
+		snapshots = append(snapshots, buf.Bytes())
+	}
+	wg.Wait()
+
+	for i, snapshot := range snapshots {
+		s2 := New()
+		if err := s2.ReadState(bytes.NewReader(snapshot)); err != nil {
+			s2.Close()
+			t.Fatalf("unexpected error reading snapshot %d: %v", i, err)
+		}
+		for token, userID := range s2.UserTokens {
+			if _, exists := s2.Users[userID]; !exists {
+				t.Errorf("snapshot %d: token %s belongs to unknown user %s", i, token, userID)
+			}
+		}
+		for id, job := range s2.Jobs {
+			if _, exists := s2.Users[job.UserID]; !exists {
+				t.Errorf("snapshot %d: job %s belongs to unknown user %s", i, id, job.UserID)
+			}
+		}
+		s2.Close()
+	}
+}
+
+func TestAccessRefreshMultiStep(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	job, err := userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	if status.Challenge == nil {
+		t.Fatalf("expecting job to have unanswered challenges")
+	}
+
+	// Nothing is stored so we need to answer 2 challenges
+	if len(status.Challenge.NextChallenges) != 2 {
+		for _, ch := range status.Challenge.NextChallenges {
+			t.Logf("got challenge id %s", ch.ID)
+		}
+		t.Errorf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+	}
+
+	req := userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer first challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	if status.Challenge == nil {
+		t.Fatalf("expecting job to have unanswered challenges")
+	}
+
+	if len(status.Challenge.NextChallenges) != 2 {
+		for _, ch := range status.Challenge.NextChallenges {
+			t.Logf("got challenge id %s", ch.ID)
+		}
+		t.Fatalf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+	}
+
+	req = userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: DefaultAccessPIN,
+	})
+
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer second challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+	ac, err := userClient.Accesses.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(ac.Accesses) != 1 {
+		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	}
+
+}
+
+func TestAccessRefreshWrongPin(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	job, err := userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	if status.Challenge == nil {
+		t.Fatalf("expecting job to have unanswered challenges")
+	}
+
+	if len(status.Challenge.NextChallenges) != 2 {
+		for _, ch := range status.Challenge.NextChallenges {
+			t.Logf("got challenge id %s", ch.ID)
+		}
+		t.Fatalf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+	}
+
+	req := userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: "wrongpin",
+	})
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer first challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	if status.Challenge == nil {
+		t.Fatalf("expecting job to have unanswered challenges")
+	}
+
+	if len(status.Errors) == 0 {
+		t.Fatalf("got 0 errors, wanted at least one")
+	}
+
+	gotWrongPinProblem := false
+	for _, p := range status.Errors {
+		if p.Code == "user_wrong_pin" {
+			gotWrongPinProblem = true
+			break
+		}
+	}
+
+	if !gotWrongPinProblem {
+		t.Errorf("did not get problem 'user_wrong_pin'")
+	}
+
+	req = userClient.Jobs.Answer(job.URI)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: DefaultAccessPIN,
+	})
+
+	if _, err := req.Send(); err != nil {
+		t.Fatalf("failed to answer second challenge: %v", err)
+	}
+	status, err = userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if !status.Finished {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+}
+
+func TestAccessRefreshCustomProblems(t *testing.T) {
+	s := NewWithDefaults()
+
+	access := s.MakeAccess(DefaultProviderID+"_problems", "access with problems")
+	ad := AccessDetails{
+		Access:               *access,
+		Transactions:         []bosgo.Transaction{},
+		RepeatedTransactions: []bosgo.RepeatedTransaction{},
+		ChallengeMap: map[string]string{
+			ChallengeLogin: DefaultAccessLogin + "_problems",
+			ChallengePIN:   DefaultAccessPIN,
+		},
+		TransferAuths: []TransferAuth{
+			{
+				Method:  DefaultAuthMethod,
+				Message: DefaultAuthMessage,
+				Answer:  DefaultAuthAnswer,
+			},
+		},
+		StageProblems: map[bosgo.JobStage][]bosgo.Problem{
+			bosgo.JobStageChallenge: {
+				{
+					Code: "fi_accountnumber_10_digits_pin_between_5_to_10",
+				},
+			},
+		},
+	}
+	s.AddAccess(ad)
+
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addAccess(userClient, DefaultProviderID+"_problems", DefaultAccessLogin+"_problems", DefaultAccessPIN)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	job, err := userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+
+	if len(status.Errors) == 0 {
+		t.Fatal("missing status errors")
+	}
+
+	if status.Errors[0].Code != "fi_accountnumber_10_digits_pin_between_5_to_10" {
+		t.Fatalf("got bad error code %s, wanted %s", status.Errors[0].Code, "fi_accountnumber_10_digits_pin_between_5_to_10")
+	}
+
+	if status.Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	}
+	if status.Finished {
+		t.Errorf("got finished %v, wanted false", status.Finished)
+	}
+}
+
+func TestAccessRefreshStored(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	job, err := userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	t.Logf("job URI: %s", job.URI)
+
+	// Expect to go straight to imported since challenges are stored
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+
+	ac, err := userClient.Accesses.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(ac.Accesses) != 1 {
+		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	}
+
+}
+
+func TestAccessRefreshStatus(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	before := time.Now()
+	if _, err := userClient.Accesses.Refresh(accessID).Send(); err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+
+	access, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to get access: %v", err)
+	}
+	if access.RefreshStatus != bosgo.RefreshStatusOK {
+		t.Errorf("got refresh status %q, wanted %q", access.RefreshStatus, bosgo.RefreshStatusOK)
+	}
+	if access.LastRefreshedAt.Before(before.Add(-time.Second)) {
+		t.Errorf("got last refreshed at %v, wanted after %v", access.LastRefreshedAt, before)
+	}
+	if access.IsStale(time.Hour) {
+		t.Errorf("access is stale immediately after refresh")
+	}
+}
+
+func TestAccessesListIncludeAccounts(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, false); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	ac, err := userClient.Accesses.List().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(ac.Accesses) != 1 {
+		t.Fatalf("got %d accesses, wanted 1", len(ac.Accesses))
+	}
+	if len(ac.Accesses[0].Accounts) != 0 {
+		t.Errorf("got %d accounts, wanted 0", len(ac.Accesses[0].Accounts))
+	}
+
+	ac, err = userClient.Accesses.List().IncludeAccounts().Send()
+	if err != nil {
+		t.Fatalf("failed to retrieve accesses: %v", err)
+	}
+	if len(ac.Accesses) != 1 {
+		t.Fatalf("got %d accesses, wanted 1", len(ac.Accesses))
+	}
+	if len(ac.Accesses[0].Accounts) != 2 {
+		t.Errorf("got %d accounts, wanted 2", len(ac.Accesses[0].Accounts))
+	}
+}
+
+func TestJobWait(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	req := userClient.Accesses.Add(DefaultProviderID)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: DefaultAccessLogin,
+	})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: DefaultAccessPIN,
+	})
+
+	job, err := req.Send()
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := userClient.Jobs.Wait(ctx, job.URI, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to wait for job: %v", err)
+	}
+
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+	if status.Finished != true {
+		t.Errorf("got finished %v, wanted true", status.Finished)
+	}
+}
+
+func TestJobsList(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, false); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	// Leave a second job waiting for challenge answers
+	active, err := userClient.Accesses.Add(DefaultProviderID).Send()
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	jobs, err := userClient.Jobs.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("got %d jobs, wanted 2", len(jobs))
+	}
+
+	jobs, err = userClient.Jobs.List().Finished(false).Send()
+	if err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("got %d active jobs, wanted 1", len(jobs))
+	}
+	if jobs[0].URI != active.URI {
+		t.Errorf("got job %q, wanted %q", jobs[0].URI, active.URI)
+	}
+	if jobs[0].Stage != bosgo.JobStageChallenge {
+		t.Errorf("got stage %v, wanted %v", jobs[0].Stage, bosgo.JobStageChallenge)
+	}
+
+	jobs, err = userClient.Jobs.List().Finished(true).Send()
+	if err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("got %d finished jobs, wanted 1", len(jobs))
+	}
+	if jobs[0].Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", jobs[0].Stage, bosgo.JobStageImported)
+	}
+}
+
+func TestAccessRefreshAll(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, true); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	jobs, err := userClient.Accesses.RefreshAll().Send()
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, wanted 1", len(jobs))
+	}
+
+	status, err := userClient.Jobs.Get(jobs[0].URI).Send()
+	if err != nil {
+		t.Fatalf("failed to get job status: %v", err)
+	}
+
+	if status.Stage != bosgo.JobStageImported {
+		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	}
+}
+
+func TestJobKind(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	job, err := userClient.Accesses.Add(DefaultProviderID).Send()
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	if job.Kind != bosgo.JobKindCreate {
+		t.Errorf("got add job kind %q, wanted %q", job.Kind, bosgo.JobKindCreate)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	job, err = userClient.Accesses.Refresh(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to refresh access: %v", err)
+	}
+	if job.Kind != bosgo.JobKindRefresh {
+		t.Errorf("got refresh job kind %q, wanted %q", job.Kind, bosgo.JobKindRefresh)
+	}
+
+	jobs, err := userClient.Accesses.RefreshAll().Send()
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	for _, job := range jobs {
+		if job.Kind != bosgo.JobKindRefresh {
+			t.Errorf("got refresh all job kind %q, wanted %q", job.Kind, bosgo.JobKindRefresh)
+		}
+	}
+}
+
+func TestAccessRefreshAllAndWait(t *testing.T) {
+	s := NewWithDefaults()
+
+	access := s.MakeAccess(DefaultProviderID+"_second", "second access")
+	s.AddAccess(AccessDetails{
+		Access:               *access,
+		Transactions:         []bosgo.Transaction{},
+		RepeatedTransactions: []bosgo.RepeatedTransaction{},
+		ChallengeMap: map[string]string{
+			ChallengeLogin: DefaultAccessLogin,
+			ChallengePIN:   DefaultAccessPIN,
+		},
+	})
+
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	for _, providerID := range []string{DefaultProviderID, DefaultProviderID + "_second"} {
+		req := userClient.Accesses.Add(providerID)
+		req.ChallengeAnswer(bosgo.ChallengeAnswer{
+			ID:    ChallengeLogin,
+			Value: DefaultAccessLogin,
+			Store: true,
+		})
+		req.ChallengeAnswer(bosgo.ChallengeAnswer{
+			ID:    ChallengePIN,
+			Value: DefaultAccessPIN,
+			Store: true,
+		})
+		if _, err := req.Send(); err != nil {
+			t.Fatalf("failed to add access for %s: %v", providerID, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := userClient.Accesses.RefreshAll().SendAndWait(ctx)
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d job statuses, wanted 2", len(statuses))
+	}
+	for _, status := range statuses {
+		if status.Stage != bosgo.JobStageImported {
+			t.Errorf("got stage %v for job %s, wanted %v", status.Stage, status.URI, bosgo.JobStageImported)
+		}
+	}
+}
+
+func TestAccessRefreshWarnings(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	ad := s.Accesses[DefaultProviderID]
+	ad.RefreshWarnings = bosgo.Warnings{
+		{Domain: "provider", Code: "provider_timeout_partial_data"},
+	}
+	s.AddAccess(ad)
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, true); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	summary, err := userClient.Accesses.WaitForAllReady(ctx)
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	if len(summary.Succeeded) != 1 {
+		t.Fatalf("got %d succeeded jobs, wanted 1 since warnings are not fatal", len(summary.Succeeded))
+	}
+	status := summary.Succeeded[0]
+	if !status.Warnings.Has("provider_timeout_partial_data") {
+		t.Errorf("got warnings %v, wanted provider_timeout_partial_data", status.Warnings.Codes())
+	}
+	if len(status.Errors) != 0 {
+		t.Errorf("got errors %+v, wanted none", status.Errors)
+	}
+}
+
+func TestAccessPartialRefresh(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	before, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to get access: %v", err)
+	}
+	if _, current := before.DataFreshness(); !current {
+		t.Fatalf("got data that is not current after import, wanted current")
+	}
+
+	ad := s.Accesses[DefaultProviderID]
+	ad.PartialRefresh = true
+	s.AddAccess(ad)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := userClient.Accesses.RefreshAll().SendAndWait(ctx)
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("got %d job statuses, wanted 1", len(statuses))
+	}
+	if !statuses[0].IsPartial() {
+		t.Errorf("got job with warnings %v that is not partial, wanted partial", statuses[0].Warnings.Codes())
+	}
+
+	after, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to get access: %v", err)
+	}
+	asOf, current := after.DataFreshness()
+	if current {
+		t.Errorf("got current data after partial refresh, wanted stale")
+	}
+	if !asOf.Equal(before.LastRefreshedAt) {
+		t.Errorf("got data as of %v, wanted unchanged %v", asOf, before.LastRefreshedAt)
+	}
+	if after.RefreshStatus != bosgo.RefreshStatusPartial {
+		t.Errorf("got refresh status %q, wanted %q", after.RefreshStatus, bosgo.RefreshStatusPartial)
+	}
+}
+
+func TestAccessRefreshError(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	accessID, _, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	before, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to get access: %v", err)
+	}
+
+	ad := s.Accesses[DefaultProviderID]
+	ad.RefreshFails = true
+	s.AddAccess(ad)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := userClient.Accesses.RefreshAll().SendAndWait(ctx)
+	if err != nil {
+		t.Fatalf("failed to refresh accesses: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("got %d job statuses, wanted 1", len(statuses))
+	}
+	if statuses[0].Stage != bosgo.JobStageProblem {
+		t.Errorf("got job stage %q, wanted %q", statuses[0].Stage, bosgo.JobStageProblem)
+	}
+	if len(statuses[0].Errors) == 0 {
+		t.Errorf("got no job errors, wanted the refresh problem")
+	}
+
+	after, err := userClient.Accesses.Get(accessID).Send()
+	if err != nil {
+		t.Fatalf("failed to get access: %v", err)
+	}
+	if after.RefreshStatus != bosgo.RefreshStatusError {
+		t.Errorf("got refresh status %q, wanted %q", after.RefreshStatus, bosgo.RefreshStatusError)
+	}
+	if !after.LastRefreshedAt.Equal(before.LastRefreshedAt) {
+		t.Errorf("got last refresh %v, wanted unchanged %v", after.LastRefreshedAt, before.LastRefreshedAt)
+	}
+}
+
+func TestAccessWaitForAllReady(t *testing.T) {
+	s := NewWithDefaults()
+
+	access := s.MakeAccess(DefaultProviderID+"_second", "second access")
+	s.AddAccess(AccessDetails{
+		Access:               *access,
+		Transactions:         []bosgo.Transaction{},
+		RepeatedTransactions: []bosgo.RepeatedTransaction{},
+		ChallengeMap: map[string]string{
+			ChallengeLogin: DefaultAccessLogin,
+			ChallengePIN:   DefaultAccessPIN,
+		},
+	})
+
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	// Only the default access stores its credentials so the second one will
+	// need them to be supplied again on refresh
+	if _, _, err := addDefaultAccess(userClient, true); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	if _, _, err := addAccess(userClient, DefaultProviderID+"_second", DefaultAccessLogin, DefaultAccessPIN); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	summary, err := userClient.Accesses.WaitForAllReady(ctx)
+	if err != nil {
+		t.Fatalf("failed to wait for accesses: %v", err)
+	}
+	if len(summary.Succeeded) != 1 {
+		t.Errorf("got %d succeeded jobs, wanted 1", len(summary.Succeeded))
+	}
+	if len(summary.NeedsCredentials) != 1 {
+		t.Errorf("got %d jobs needing credentials, wanted 1", len(summary.NeedsCredentials))
+	}
+	if len(summary.Failed) != 0 {
+		t.Errorf("got %d failed jobs, wanted 0", len(summary.Failed))
+	}
+}
+
+func TestUserOverview(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	if _, _, err := addDefaultAccess(userClient, true); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	overview, err := userClient.Overview(context.Background(), "EUR")
+	if err != nil {
+		t.Fatalf("failed to get overview: %v", err)
+	}
+
+	wantTotal := bosgo.MoneyAmount{Currency: "EUR", Value: "1016.20"}
+	if overview.TotalBalance != wantTotal {
+		t.Errorf("got total balance %+v, wanted %+v", overview.TotalBalance, wantTotal)
+	}
+	if overview.AccountCount != 2 {
+		t.Errorf("got %d accounts, wanted 2", overview.AccountCount)
+	}
+	if overview.AccessCount != 1 {
+		t.Errorf("got %d accesses, wanted 1", overview.AccessCount)
+	}
+	if len(overview.RecentTransactions) != 3 {
+		t.Errorf("got %d recent transactions, wanted 3", len(overview.RecentTransactions))
+	}
+}
+
+func TestAccountStatement(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	_, accountID, err := addDefaultAccess(userClient, true)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+
+	var buf bytes.Buffer
+	contentType, err := userClient.Accounts.Statement(accountID).From(time.Now().AddDate(0, -1, 0)).To(time.Now()).Send(&buf)
+	if err != nil {
+		t.Fatalf("failed to download statement: %v", err)
+	}
+
+	if contentType != "application/pdf" {
+		t.Errorf("got content type %q, wanted %q", contentType, "application/pdf")
+	}
+	if !bytes.Equal(buf.Bytes(), StatementPDF) {
+		t.Errorf("got statement %q, wanted %q", buf.Bytes(), StatementPDF)
+	}
+
+	_, err = userClient.Accounts.Statement(accountID + 1000).Send(&buf)
+	if errCode(err) != "resource_not_found" {
+		t.Errorf("got error %q, wanted resource_not_found", errCode(err))
+	}
+}
+
+func addAccess(userClient *bosgo.UserClient, provider, login, pin string) (int64, int64, error) {
+	req := userClient.Accesses.Add(provider)
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengeLogin,
+		Value: login,
+	})
+	req.ChallengeAnswer(bosgo.ChallengeAnswer{
+		ID:    ChallengePIN,
+		Value: pin,
+	})
+
+	job, err := req.Send()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	status, err := userClient.Jobs.Get(job.URI).Send()
+	if err != nil {
+		return 0, 0, err
+	}
+	if status.Access == nil {
+		return 0, 0, fmt.Errorf("no access found")
+	}
+	if !status.Access.HasAccounts() {
+		return 0, 0, bosgo.ErrNoAccountsImported
+	}
+
+	return status.Access.ID, status.Access.Accounts[0].ID, nil
+}
+
+func TestErrorSentinels(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	_, err := appClient.Users.Login(DefaultUsername, "wrong").Send()
+	if !errors.Is(err, bosgo.ErrAuthenticationFailed) {
+		t.Errorf("got error %v, wanted ErrAuthenticationFailed", err)
+	}
+
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login: %v", err)
+	}
+
+	_, err = userClient.Accesses.Get(999999).Send()
+	if !errors.Is(err, bosgo.ErrResourceNotFound) {
+		t.Errorf("got error %v, wanted ErrResourceNotFound", err)
+	}
+	if errors.Is(err, bosgo.ErrAuthenticationFailed) {
+		t.Errorf("not found error matched ErrAuthenticationFailed")
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	_, err := appClient.Users.Login(DefaultUsername, "wrong").Send()
+	berr, ok := err.(*bosgo.Error)
+	if !ok {
+		t.Fatalf("got error %v, wanted a *bosgo.Error", err)
+	}
+	if berr.Code() != "authentication_failed" {
+		t.Errorf("got code %q, wanted authentication_failed", berr.Code())
+	}
+	if !berr.IsAuth() {
+		t.Errorf("got IsAuth false, wanted true")
+	}
+	if berr.IsValidation() || berr.IsRetryable() {
+		t.Errorf("authentication failure reported as validation error or retryable")
+	}
+
+	_, err = appClient.IBAN.Validate("DE89370400440532013001").Send()
+	berr, ok = err.(*bosgo.Error)
+	if !ok {
+		t.Fatalf("got error %v, wanted a *bosgo.Error", err)
+	}
+	if berr.Code() != "validation_bad_parameters" {
+		t.Errorf("got code %q, wanted validation_bad_parameters", berr.Code())
+	}
+	if !berr.IsValidation() {
+		t.Errorf("got IsValidation false, wanted true")
+	}
+	if berr.IsAuth() || berr.IsRetryable() {
+		t.Errorf("validation failure reported as authentication error or retryable")
+	}
+}
+
+func errCode(err error) string {
+	berr, ok := err.(*bosgo.Error)
+	if !ok {
+		return "<error not a bosgo.Error>"
+	}
+
+	if len(berr.Errors) == 0 {
+		return "<no errors found>"
+	}
+
+	return berr.Errors[0].Code
+}
+
+func errStatusCode(err error) int {
+	berr, ok := err.(*bosgo.Error)
+	if !ok {
+		return 0
+	}
+
+	return berr.StatusCode
+}
+
+func TestBeneficiaries(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+
+	list, err := userClient.Beneficiaries.List().Send()
 	if err != nil {
-		t.Fatalf("failed to process pin: %v", err)
+		t.Fatalf("failed to list beneficiaries: %v", err)
 	}
-	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	if len(list) != 0 {
+		t.Errorf("got %d beneficiaries, wanted 0", len(list))
 	}
-	if transfer.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
 	}
-	if len(transfer.Step.Data.AuthMethods) != 1 {
-		t.Fatalf("got %d auth methods, wanted 1", len(transfer.Step.Data.AuthMethods))
+	created, err := userClient.Beneficiaries.Create(addr).Send()
+	if err != nil {
+		t.Fatalf("failed to create beneficiary: %v", err)
 	}
-	if transfer.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
-		t.Errorf("got auth method %v, wanted %v", transfer.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	if created.RemoteAcc.IBAN != addr.IBAN {
+		t.Errorf("got IBAN %q, wanted %q", created.RemoteAcc.IBAN, addr.IBAN)
+	}
+	if created.RemoteAcc.Label != addr.Name {
+		t.Errorf("got label %q, wanted %q", created.RemoteAcc.Label, addr.Name)
 	}
 
-	// Send the auth method
-	req = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "auth_method",
-		Value: DefaultAuthMethod,
-	})
-	transfer, err = req.Send()
+	list, err = userClient.Beneficiaries.List().Send()
 	if err != nil {
-		t.Fatalf("failed to process auth method: %v", err)
-	}
-	if transfer.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+		t.Fatalf("failed to list beneficiaries: %v", err)
 	}
-	if transfer.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+	if len(list) != 1 {
+		t.Fatalf("got %d beneficiaries, wanted 1", len(list))
 	}
-	if transfer.Step.Data.ChallengeMessage != DefaultAuthMessage {
-		t.Errorf("got challenge message %v, wanted %v", transfer.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	if list[0] != *created {
+		t.Errorf("got beneficiary %+v, wanted %+v", list[0], *created)
 	}
 
-	// Send the auth answer
-	req = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "tan",
-		Value: DefaultAuthAnswer,
-	})
-	transfer, err = req.Send()
+	got, err := userClient.Beneficiaries.Get(created.ID).Send()
 	if err != nil {
-		t.Fatalf("failed to process auth answer: %v", err)
+		t.Fatalf("failed to get beneficiary: %v", err)
 	}
-	if transfer.State != bosgo.TransferStateSucceeded {
-		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	if *got != *created {
+		t.Errorf("got beneficiary %+v, wanted %+v", *got, *created)
 	}
 }
 
-func TestCreateRecurringTransfer(t *testing.T) {
+func TestDeleteBeneficiary(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -920,383 +4401,565 @@ func TestCreateRecurringTransfer(t *testing.T) {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	_, accountID, err := addDefaultAccess(userClient, false)
+	created, err := userClient.Beneficiaries.Create(bosgo.TransferAddress{Name: "Jane Doe", IBAN: "DE28500105175552834822"}).Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to create beneficiary: %v", err)
 	}
 
-	amount := bosgo.MoneyAmount{
-		Currency: "EUR",
-		Value:    "12.50",
+	if err := userClient.Beneficiaries.Delete(created.ID).Send(); err != nil {
+		t.Fatalf("failed to delete beneficiary: %v", err)
 	}
 
-	addr := bosgo.TransferAddress{
-		Name: "Jane Doe",
-		IBAN: "DE28500105175552834822",
+	list, err := userClient.Beneficiaries.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list beneficiaries: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("got %d beneficiaries, wanted 0", len(list))
 	}
 
-	rule := bosgo.RecurrenceRule{
-		Start:     time.Now(),
-		Frequency: bosgo.FrequencyMonthly,
-		Interval:  2,
+	_, err = userClient.Beneficiaries.Get(created.ID).Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
 	}
-	// Create the transfer
-	transfer, err := userClient.RecurringTransfers.Create(accountID, addr, amount, rule, "description/usage").Send()
+
+	err = userClient.Beneficiaries.Delete(created.ID).Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+}
+
+func TestValidateIBAN(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	details, err := appClient.IBAN.Validate("DE89370400440532013000").Send()
 	if err != nil {
-		t.Fatalf("failed to create transfer: %v", err)
+		t.Fatalf("failed to validate IBAN: %v", err)
+	}
+	if details.Account.IBAN != "DE89370400440532013000" {
+		t.Errorf("got IBAN %q, wanted %q", details.Account.IBAN, "DE89370400440532013000")
+	}
+	if len(details.Banks) != 1 {
+		t.Fatalf("got %d banks, wanted 1", len(details.Banks))
+	}
+	if details.Banks[0].ID != "COBADEFFXXX" {
+		t.Errorf("got bank %q, wanted %q", details.Banks[0].ID, "COBADEFFXXX")
 	}
+}
 
-	if len(transfer.Errors) > 0 {
-		for _, pr := range transfer.Errors {
-			t.Logf("got unexpected error: %s", pr.Code)
+func TestValidateIBANChecksum(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	_, err := appClient.IBAN.Validate("DE89370400440532013001").Send()
+	if err == nil {
+		t.Fatalf("got no error, wanted validation_bad_parameters")
+	}
+	if code := errCode(err); code != "validation_bad_parameters" {
+		t.Errorf("got error code %q, wanted validation_bad_parameters", code)
+	}
+	if berr, ok := err.(*bosgo.Error); ok {
+		if keys := berr.Errors[0].Payload["field_key"]; len(keys) != 1 || keys[0] != "iban" {
+			t.Errorf("got field keys %v, wanted iban", keys)
 		}
-		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
 	}
+}
 
-	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
+func TestTeamInvite(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	// Send the pin
-	req := userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "pin",
-		Value: DefaultAccessPIN,
-	})
-	transfer, err = req.Send()
+	invitee := Dev{ID: "invited-dev", Email: "new.developer@example.com"}
+	s.setDev(invitee)
+
+	owner := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+	member := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(invitee.ID))
+
+	team, err := owner.Teams.Create(bosgo.TeamNew{Name: "My team"}).Send()
 	if err != nil {
-		t.Fatalf("failed to process pin: %v", err)
+		t.Fatalf("failed to create team: %v", err)
 	}
-	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	if !team.Owner {
+		t.Errorf("got owner false, wanted true")
 	}
-	if transfer.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+
+	if err := owner.Teams.Invite(team.ID, invitee.Email).Send(); err != nil {
+		t.Fatalf("failed to invite developer: %v", err)
 	}
-	if len(transfer.Step.Data.AuthMethods) != 1 {
-		t.Fatalf("got %d auth methods, wanted 1", len(transfer.Step.Data.AuthMethods))
+
+	token, exists := s.InviteToken(team.ID, invitee.Email)
+	if !exists {
+		t.Fatalf("no invite token found")
 	}
-	if transfer.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
-		t.Errorf("got auth method %v, wanted %v", transfer.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+
+	ir, err := member.Teams.AcceptInvite(token).Send()
+	if err != nil {
+		t.Fatalf("failed to accept invite: %v", err)
+	}
+	if !ir.Success {
+		t.Errorf("got success false, wanted true")
+	}
+	if ir.TeamName != "My team" {
+		t.Errorf("got team name %q, wanted %q", ir.TeamName, "My team")
 	}
 
-	// Send the auth method
-	req = userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "auth_method",
-		Value: DefaultAuthMethod,
-	})
-	transfer, err = req.Send()
+	// The invite may only be used once
+	_, err = member.Teams.AcceptInvite(token).Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+
+	members, err := member.Teams.ListMembers(team.ID).Send()
 	if err != nil {
-		t.Fatalf("failed to process auth method: %v", err)
+		t.Fatalf("failed to list members: %v", err)
 	}
-	if transfer.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
-		t.Errorf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	if len(members) != 2 {
+		t.Fatalf("got %d members, wanted 2", len(members))
 	}
-	if transfer.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+	if members[0].Email != DefaultDeveloperEmail || !members[0].Owner {
+		t.Errorf("got first member %+v, wanted owner %s", members[0], DefaultDeveloperEmail)
 	}
-	if transfer.Step.Data.ChallengeMessage != DefaultAuthMessage {
-		t.Errorf("got challenge message %v, wanted %v", transfer.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	if members[1].Email != invitee.Email || members[1].Owner {
+		t.Errorf("got second member %+v, wanted %s", members[1], invitee.Email)
 	}
 
-	// Send the auth answer
-	req = userClient.RecurringTransfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "tan",
-		Value: DefaultAuthAnswer,
-	})
-	transfer, err = req.Send()
+	teams, err := member.Teams.List().Send()
 	if err != nil {
-		t.Fatalf("failed to process auth answer: %v", err)
+		t.Fatalf("failed to list teams: %v", err)
 	}
-	if transfer.State != bosgo.TransferStateSucceeded {
-		t.Errorf("got state %v, wanted %v", transfer.State, bosgo.TransferStateSucceeded)
+	if len(teams) != 1 || teams[0].ID != team.ID || teams[0].Owner {
+		t.Errorf("got teams %+v, wanted non-owned team %s", teams, team.ID)
+	}
+
+	access := []bosgo.TeamMemberNewAccess{{ResourceName: "application", AccessLevel: 1}}
+	accesses, err := owner.Teams.UpdateMemberAccess(team.ID, members[1].ID, access).Send()
+	if err != nil {
+		t.Fatalf("failed to update member access: %v", err)
+	}
+	if len(accesses) != 1 || accesses[0].ResourceName != "application" || accesses[0].AccessLevel != 1 {
+		t.Errorf("got accesses %+v, wanted application level 1", accesses)
+	}
+
+	// Only the owner may change access
+	_, err = member.Teams.UpdateMemberAccess(team.ID, members[0].ID, access).Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q, wanted authentication_failed", code)
 	}
 }
 
-func TestDeleteRecurringTransfer(t *testing.T) {
+func TestApplicationKeys(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
-	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+
+	key, err := devClient.Applications.CreateKey(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
+		t.Fatalf("failed to create key: %v", err)
+	}
+	if key.Key == "" {
+		t.Errorf("got empty key, wanted non-empty")
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	page, err := devClient.Applications.ListKeys(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	if len(page.Keys) != 1 || page.Keys[0].Key != key.Key {
+		t.Errorf("got keys %+v, wanted %s", page.Keys, key.Key)
 	}
 
-	repeatedTxs, err := userClient.RepeatedTransactions.List().Send()
+	if err := devClient.Applications.DeleteKey(DefaultApplicationKey, key.Key).Confirm(DefaultDeveloperPassword).Send(); err != nil {
+		t.Fatalf("failed to delete key: %v", err)
+	}
+
+	page, err = devClient.Applications.ListKeys(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to get repeated txs: %v", err)
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	if len(page.Keys) != 0 {
+		t.Errorf("got %d keys, wanted 0", len(page.Keys))
 	}
 
-	if len(repeatedTxs.Transactions) == 0 {
-		t.Fatalf("missing repeated txs on default access")
+	err = devClient.Applications.DeleteKey(DefaultApplicationKey, key.Key).Confirm(DefaultDeveloperPassword).Send()
+	if !bosgo.IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
 	}
+}
 
-	// Delete the repeated tx with unknown ID
-	recTrf, err := userClient.RepeatedTransactions.Delete("9999").Send()
-	if err == nil {
-		t.Fatalf("didn't fail to delete an unknown repeated transaction: %v", err)
+func TestDeleteApplicationKeyConfirmation(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	// Delete the repeated tx with correct ID should
-	rtxID := strconv.Itoa(int(repeatedTxs.Transactions[0].ID))
-	recTrf, err = userClient.RepeatedTransactions.Delete(rtxID).Send()
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+
+	key, err := devClient.Applications.CreateKey(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to delete recTrf: %v", err)
+		t.Fatalf("failed to create key: %v", err)
 	}
 
-	if len(recTrf.Errors) > 0 {
-		for _, pr := range recTrf.Errors {
-			t.Logf("got unexpected error: %s", pr.Code)
-		}
-		t.Fatalf("got %d errors, wanted none", len(recTrf.Errors))
+	err = devClient.Applications.DeleteKey(DefaultApplicationKey, key.Key).Send()
+	if !errors.Is(err, bosgo.ErrConfirmationRequired) {
+		t.Errorf("got error %v, wanted ErrConfirmationRequired", err)
 	}
 
-	if recTrf.Step.Intent != bosgo.TransferIntentProvidePIN {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvidePIN)
+	err = devClient.Applications.DeleteKey(DefaultApplicationKey, key.Key).Confirm("wrong").Send()
+	if code := errCode(err); code != "confirmation_failed" {
+		t.Errorf("got error code %q, wanted confirmation_failed", code)
 	}
 
-	// Send the pin
-	req := userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "pin",
-		Value: DefaultAccessPIN,
-	})
-	recTrf, err = req.Send()
+	err = devClient.ApplicationKeys.Delete(key.Key).Send()
+	if !errors.Is(err, bosgo.ErrConfirmationRequired) {
+		t.Errorf("got error %v, wanted ErrConfirmationRequired", err)
+	}
+
+	page, err := devClient.Applications.ListKeys(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to process pin: %v", err)
+		t.Fatalf("failed to list keys: %v", err)
 	}
-	if recTrf.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	if len(page.Keys) != 1 {
+		t.Fatalf("got %d keys, wanted 1 since deletion was not confirmed", len(page.Keys))
 	}
-	if recTrf.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+
+	if err := devClient.ApplicationKeys.Delete(key.Key).Confirm(DefaultDeveloperPassword).Send(); err != nil {
+		t.Fatalf("failed to delete key: %v", err)
 	}
-	if len(recTrf.Step.Data.AuthMethods) != 1 {
-		t.Fatalf("got %d auth methods, wanted 1", len(recTrf.Step.Data.AuthMethods))
+
+	page, err = devClient.Applications.ListKeys(DefaultApplicationKey).Send()
+	if err != nil {
+		t.Fatalf("failed to list keys: %v", err)
 	}
-	if recTrf.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
-		t.Errorf("got auth method %v, wanted %v", recTrf.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	if len(page.Keys) != 0 {
+		t.Errorf("got %d keys, wanted 0", len(page.Keys))
 	}
+}
 
-	// Send the auth method
-	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "auth_method",
-		Value: DefaultAuthMethod,
-	})
-	recTrf, err = req.Send()
-	if err != nil {
-		t.Fatalf("failed to process auth method: %v", err)
+func TestDeleteApplicationConfirmation(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
-	if recTrf.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	defer s.Close()
+
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+
+	err := devClient.Applications.Delete(DefaultApplicationKey).Send()
+	if !errors.Is(err, bosgo.ErrConfirmationRequired) {
+		t.Errorf("got error %v, wanted ErrConfirmationRequired", err)
 	}
-	if recTrf.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+	if code := errCode(err); code != "confirmation_required" {
+		t.Errorf("got error code %q, wanted confirmation_required", code)
 	}
-	if recTrf.Step.Data.ChallengeMessage != DefaultAuthMessage {
-		t.Errorf("got challenge message %v, wanted %v", recTrf.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	if _, exists := s.getApp(DefaultApplicationKey); !exists {
+		t.Fatalf("application was deleted without confirmation")
 	}
 
-	// Send the auth answer
-	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "tan",
-		Value: DefaultAuthAnswer,
-	})
-	recTrf, err = req.Send()
-	if err != nil {
-		t.Fatalf("failed to process auth answer: %v", err)
+	if err := devClient.Applications.Delete(DefaultApplicationKey).Confirm(DefaultDeveloperPassword).Send(); err != nil {
+		t.Fatalf("failed to delete application: %v", err)
+	}
+	if _, exists := s.getApp(DefaultApplicationKey); exists {
+		t.Errorf("application was not deleted")
+	}
+}
+
+func TestDeleteDeveloperConfirmation(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+
+	err := devClient.Delete().Send()
+	if !errors.Is(err, bosgo.ErrConfirmationRequired) {
+		t.Errorf("got error %v, wanted ErrConfirmationRequired", err)
+	}
+
+	err = devClient.Delete().Confirm("wrong").Send()
+	if code := errCode(err); code != "confirmation_failed" {
+		t.Errorf("got error code %q, wanted confirmation_failed", code)
 	}
-	if recTrf.State != bosgo.TransferStateSucceeded {
-		t.Errorf("got state %v, wanted %v", recTrf.State, bosgo.TransferStateSucceeded)
+	if _, exists := s.getDev(DefaultDeveloperID); !exists {
+		t.Fatalf("developer was deleted without confirmation")
+	}
+
+	if err := devClient.Delete().Confirm(DefaultDeveloperPassword).Send(); err != nil {
+		t.Fatalf("failed to delete developer: %v", err)
+	}
+	if _, exists := s.getDev(DefaultDeveloperID); exists {
+		t.Errorf("developer was not deleted")
+	}
+	if _, exists := s.getApp(DefaultApplicationKey); exists {
+		t.Errorf("developer's application was not deleted")
 	}
 }
 
-func TestUpdateRecurringTransfer(t *testing.T) {
+func TestDeveloperLogin(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
 	defer s.Close()
 
-	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
+	client := bosgo.New(s.Client(), s.Addr())
+
+	if _, err := client.Login(DefaultDeveloperEmail, "wrong").Send(); errCode(err) != "authentication_failed" {
+		t.Errorf("got error %v, wanted authentication_failed", err)
 	}
 
-	_, _, err = addDefaultAccess(userClient, false)
+	devClient, err := client.Login(DefaultDeveloperEmail, DefaultDeveloperPassword).Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
+		t.Fatalf("failed to login: %v", err)
 	}
 
-	repeatedTxs, err := userClient.RepeatedTransactions.List().Send()
+	page, err := devClient.Applications.List().Send()
 	if err != nil {
-		t.Fatalf("failed to get repeated txs: %v", err)
+		t.Fatalf("failed to list applications: %v", err)
+	}
+	if len(page.Applications) != 1 || page.Applications[0].ApplicationID != DefaultApplicationKey {
+		t.Errorf("got applications %+v, wanted %s", page.Applications, DefaultApplicationKey)
 	}
 
-	if len(repeatedTxs.Transactions) == 0 {
-		t.Fatalf("missing repeated txs on default access")
+	if err := devClient.Logout().Send(); err != nil {
+		t.Fatalf("failed to logout: %v", err)
 	}
 
-	// update the repeated tx with unknown ID should fail
-	recTrf, err := userClient.RepeatedTransactions.Delete("9999").Send()
-	if err == nil {
-		t.Fatalf("didn't fail to delete an unknown repeated transaction: %v", err)
+	_, err = devClient.Applications.List().Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q after logout, wanted authentication_failed", code)
 	}
+}
 
-	rtx := repeatedTxs.Transactions[0]
-	rtx.Amount.Value = "99.32"
+func TestDeveloperCreate(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
 
-	addr := bosgo.TransferAddress{
-		Name: "Jane Doe",
-		IBAN: "DE28500105175552834822",
+	client := bosgo.New(s.Client(), s.Addr())
+
+	_, err := client.CreateDeveloper(DefaultDeveloperEmail, "secret").Send()
+	if code := errCode(err); code != "authentication_email_not_unique" {
+		t.Errorf("got error code %q, wanted authentication_email_not_unique", code)
 	}
 
-	// Update the repeated tx with correct ID
-	rtxID := strconv.Itoa(int(repeatedTxs.Transactions[0].ID))
-	recTrf, err = userClient.RepeatedTransactions.Update(rtxID, addr, *rtx.Amount, "this is the updated usage").Send()
+	devClient, err := client.CreateDeveloper("new@example.com", "secret").Send()
 	if err != nil {
-		t.Fatalf("failed to delete recTrf: %v", err)
+		t.Fatalf("failed to create developer: %v", err)
 	}
 
-	if len(recTrf.Errors) > 0 {
-		for _, pr := range recTrf.Errors {
-			t.Logf("got unexpected error: %s", pr.Code)
+	page, err := devClient.Applications.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list applications: %v", err)
+	}
+	if len(page.Applications) != 0 {
+		t.Errorf("got %d applications, wanted 0", len(page.Applications))
+	}
+
+	if _, err := client.Login("new@example.com", "secret").Send(); err != nil {
+		t.Errorf("failed to login as new developer: %v", err)
+	}
+}
+
+func TestDeveloperCreateConcurrent(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
+	defer s.Close()
+
+	client := bosgo.New(s.Client(), s.Addr())
+
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.CreateDeveloper("new@example.com", "secret").Send()
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else if code := errCode(err); code != "authentication_email_not_unique" {
+			t.Errorf("got error code %q, wanted authentication_email_not_unique", code)
 		}
-		t.Fatalf("got %d errors, wanted none", len(recTrf.Errors))
 	}
+	if created != 1 {
+		t.Errorf("created %d developers with the same email, wanted 1", created)
+	}
+}
 
-	if recTrf.Step.Intent != bosgo.TransferIntentProvidePIN {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvidePIN)
+func TestApplicationLifecycle(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	// Send the pin
-	req := userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "pin",
-		Value: DefaultAccessPIN,
-	})
-	recTrf, err = req.Send()
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+
+	created, err := devClient.Applications.Create("first label").Send()
 	if err != nil {
-		t.Fatalf("failed to process pin: %v", err)
+		t.Fatalf("failed to create application: %v", err)
 	}
-	if recTrf.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
+	if created.ApplicationID == "" {
+		t.Fatalf("got empty application id")
 	}
-	if recTrf.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+
+	find := func() (bosgo.ApplicationMetadata, bool) {
+		page, err := devClient.Applications.List().Send()
+		if err != nil {
+			t.Fatalf("failed to list applications: %v", err)
+		}
+		for _, app := range page.Applications {
+			if app.ApplicationID == created.ApplicationID {
+				return app, true
+			}
+		}
+		return bosgo.ApplicationMetadata{}, false
 	}
-	if len(recTrf.Step.Data.AuthMethods) != 1 {
-		t.Fatalf("got %d auth methods, wanted 1", len(recTrf.Step.Data.AuthMethods))
+
+	app, found := find()
+	if !found {
+		t.Fatalf("created application %s not listed", created.ApplicationID)
 	}
-	if recTrf.Step.Data.AuthMethods[0].ID != DefaultAuthMethod {
-		t.Errorf("got auth method %v, wanted %v", recTrf.Step.Data.AuthMethods[0].ID, DefaultAuthMethod)
+	if app.Label != "first label" {
+		t.Errorf("got label %q, wanted %q", app.Label, "first label")
 	}
 
-	// Send the auth method
-	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "auth_method",
-		Value: DefaultAuthMethod,
-	})
-	recTrf, err = req.Send()
-	if err != nil {
-		t.Fatalf("failed to process auth method: %v", err)
+	if err := devClient.Applications.Update(created.ApplicationID, "second label").Send(); err != nil {
+		t.Fatalf("failed to update application: %v", err)
 	}
-	if recTrf.Step.Intent != bosgo.TransferIntentProvideChallengeAnswer {
-		t.Errorf("got intent %v, wanted %v", recTrf.Step.Intent, bosgo.TransferIntentProvideChallengeAnswer)
+	app, _ = find()
+	if app.Label != "second label" {
+		t.Errorf("got label %q after update, wanted %q", app.Label, "second label")
 	}
-	if recTrf.Step.Data == nil {
-		t.Fatalf("got nil step data, wanted non-nil")
+
+	if err := devClient.Applications.Delete(created.ApplicationID).Confirm(DefaultDeveloperPassword).Send(); err != nil {
+		t.Fatalf("failed to delete application: %v", err)
 	}
-	if recTrf.Step.Data.ChallengeMessage != DefaultAuthMessage {
-		t.Errorf("got challenge message %v, wanted %v", recTrf.Step.Data.ChallengeMessage, DefaultAuthMessage)
+	if _, found := find(); found {
+		t.Errorf("deleted application %s still listed", created.ApplicationID)
 	}
 
-	// Send the auth answer
-	req = userClient.RecurringTransfers.Process(recTrf.ID, recTrf.Step.Intent, recTrf.Version)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    "tan",
-		Value: DefaultAuthAnswer,
-	})
-	recTrf, err = req.Send()
-	if err != nil {
-		t.Fatalf("failed to process auth answer: %v", err)
-	}
-	if recTrf.State != bosgo.TransferStateSucceeded {
-		t.Errorf("got state %v, wanted %v", recTrf.State, bosgo.TransferStateSucceeded)
+	other := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn("other-dev"))
+	s.setDev(Dev{ID: "other-dev", Email: "other@example.com", Password: "password"})
+	err = other.Applications.Update(DefaultApplicationKey, "stolen").Send()
+	if code := errCode(err); code != "authentication_failed" {
+		t.Errorf("got error code %q updating another developer's application, wanted authentication_failed", code)
 	}
 }
 
-func TestWriteReadState(t *testing.T) {
+func TestDevListUsers(t *testing.T) {
 	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
+	}
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-	if err != nil {
-		t.Fatalf("failed to login as user: %v", err)
+	want := []string{DefaultUserID}
+	for _, name := range []string{"first@example.com", "second@example.com"} {
+		if _, err := appClient.Users.Create(name, "password").Send(); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		user, _ := s.GetUserByName(name)
+		want = append(want, user.ID)
 	}
+	sort.Strings(want)
 
-	_, accountID, err := addDefaultAccess(userClient, false)
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+	page, err := devClient.Applications.ListUsers(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to add access: %v", err)
-	}
-
-	var buf bytes.Buffer
-	if err := s.WriteState(&buf); err != nil {
-		t.Fatalf("unexpected error writing state: %v", err)
+		t.Fatalf("failed to list users: %v", err)
 	}
-
-	s2 := New()
-	defer s2.Close()
-	s2.ReadState(&buf)
-
-	appClient2 := bosgo.NewAppClient(s2.Client(), s2.Addr(), DefaultApplicationKey)
-	userClient2, err := appClient2.Users.Login(DefaultUsername, DefaultPassword).Send()
-	if err != nil {
-		t.Fatalf("failed to login to new server as user: %v", err)
+	if !reflect.DeepEqual(page.Users, want) {
+		t.Errorf("got users %v, wanted %v", page.Users, want)
 	}
+}
 
-	amount := bosgo.MoneyAmount{
-		Currency: "EUR",
-		Value:    "12.50",
+func TestDevListUsersPaging(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	addr := bosgo.TransferAddress{
-		Name: "Jane Doe",
-		IBAN: "DE28500105175552834822",
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	for i := 0; i < 4; i++ {
+		if _, err := appClient.Users.Create(fmt.Sprintf("user%d@example.com", i), "password").Send(); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
 	}
 
-	// Create the transfer
-	transfer, err := userClient2.Transfers.Create(accountID, addr, amount).Send()
+	devClient := bosgo.NewDevClient(s.Client(), s.Addr(), s.setDevLoggedIn(DefaultDeveloperID))
+	all, err := devClient.Applications.ListUsers(DefaultApplicationKey).Send()
 	if err != nil {
-		t.Fatalf("failed to create transfer: %v", err)
+		t.Fatalf("failed to list users: %v", err)
+	}
+	if len(all.Users) != 5 {
+		t.Fatalf("got %d users, wanted 5", len(all.Users))
 	}
 
-	if len(transfer.Errors) > 0 {
-		for _, pr := range transfer.Errors {
-			t.Logf("got unexpected error: %s", pr.Code)
+	var got []string
+	var pages int
+	cursor := ""
+	for {
+		page, err := devClient.Applications.ListUsers(DefaultApplicationKey).Cursor(cursor).Limit(2).Send()
+		if err != nil {
+			t.Fatalf("failed to list users: %v", err)
 		}
-		t.Fatalf("got %d errors, wanted none", len(transfer.Errors))
+		pages++
+		if len(page.Users) > 2 {
+			t.Errorf("got %d users on page %d, wanted at most 2", len(page.Users), pages)
+		}
+		got = append(got, page.Users...)
+		if page.NextCursor == "" {
+			break
+		}
+		if pages > 5 {
+			t.Fatalf("paging did not terminate")
+		}
+		cursor = page.NextCursor
 	}
 
+	if pages != 3 {
+		t.Errorf("got %d pages, wanted 3", pages)
+	}
+	if !reflect.DeepEqual(got, all.Users) {
+		t.Errorf("got paged users %v, wanted %v", got, all.Users)
+	}
 }
 
-func TestAccessRefreshMultiStep(t *testing.T) {
+func TestAccountGet(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -1305,100 +4968,192 @@ func TestAccessRefreshMultiStep(t *testing.T) {
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	accessID, _, err := addDefaultAccess(userClient, false)
-	if err != nil {
+	if _, _, err := addDefaultAccess(userClient, false); err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	job, err := userClient.Accesses.Refresh(accessID).Send()
+	page, err := userClient.Accounts.List().Send()
 	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+		t.Fatalf("failed to list accounts: %v", err)
 	}
-	t.Logf("job URI: %s", job.URI)
-
-	status, err := userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+	if len(page.Accounts) == 0 {
+		t.Fatalf("got no accounts")
 	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+
+	for _, want := range page.Accounts {
+		account, err := userClient.Accounts.Get(strconv.FormatInt(want.ID, 10)).Send()
+		if err != nil {
+			t.Fatalf("failed to get account %d: %v", want.ID, err)
+		}
+		if account.ID != want.ID || account.IBAN != want.IBAN {
+			t.Errorf("got account %d with iban %q, wanted %d with iban %q", account.ID, account.IBAN, want.ID, want.IBAN)
+		}
 	}
 
-	if status.Challenge == nil {
-		t.Fatalf("expecting job to have unanswered challenges")
+	_, err = userClient.Accounts.Get("999999").Send()
+	if code := errCode(err); code != "resource_not_found" {
+		t.Errorf("got error code %q, wanted resource_not_found", code)
 	}
+}
 
-	// Nothing is stored so we need to answer 2 challenges
-	if len(status.Challenge.NextChallenges) != 2 {
-		for _, ch := range status.Challenge.NextChallenges {
-			t.Logf("got challenge id %s", ch.ID)
-		}
-		t.Errorf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+func TestInjectError(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	req := userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: DefaultAccessLogin,
-	})
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer first challenge: %v", err)
+	policy := bosgo.RetryPolicy{
+		MaxRetries: 3,
+		Wait:       time.Millisecond,
+		MaxWait:    5 * time.Millisecond,
 	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
+	client := bosgo.New(s.Client(), s.Addr(), bosgo.WithRetryPolicy(policy))
+	userClient, err := client.WithApplicationKey(DefaultApplicationKey).Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
-	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	if status.Challenge == nil {
-		t.Fatalf("expecting job to have unanswered challenges")
+	s.InjectError("/v1/accounts", http.StatusServiceUnavailable, "service_unavailable", 2)
+	if _, err := userClient.Accounts.List().Send(); err != nil {
+		t.Fatalf("failed to list accounts after transient errors: %v", err)
 	}
 
-	if len(status.Challenge.NextChallenges) != 2 {
-		for _, ch := range status.Challenge.NextChallenges {
-			t.Logf("got challenge id %s", ch.ID)
-		}
-		t.Fatalf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+	noRetry := bosgo.New(s.Client(), s.Addr(), bosgo.WithRetryPolicy(bosgo.RetryPolicy{}))
+	userClient, err = noRetry.WithApplicationKey(DefaultApplicationKey).Users.Login(DefaultUsername, DefaultPassword).Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	req = userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: DefaultAccessPIN,
-	})
+	s.InjectError("/v1/accounts", http.StatusServiceUnavailable, "service_unavailable", 1)
+	_, err = userClient.Accounts.List().Send()
+	if status := errStatusCode(err); status != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, wanted %d", status, http.StatusServiceUnavailable)
+	}
+	if _, err := userClient.Accounts.List().Send(); err != nil {
+		t.Errorf("failed to list accounts once injected error was used: %v", err)
+	}
+}
 
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer second challenge: %v", err)
+func TestInjectLatency(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+
+	s.InjectLatency(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send(); err != nil {
+		t.Fatalf("failed to login as user: %v", err)
 	}
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("got response after %v, wanted at least 50ms", elapsed)
 	}
-	if status.Finished != true {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Context(ctx).Send(); err == nil {
+		t.Errorf("got no error, wanted request to time out")
 	}
+}
 
-	ac, err := userClient.Accesses.List().Send()
-	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
+func TestSetIDSeed(t *testing.T) {
+	ids := func() []string {
+		s := NewWithDefaults()
+		if testing.Verbose() {
+			s.SetLogger(t)
+		}
+		defer s.Close()
+
+		s.SetIDSeed(1000)
+
+		access := s.MakeAccess(DefaultProviderID, "seeded access")
+
+		appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+		if _, err := appClient.Users.Create("seeded@example.com", "password").Send(); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		user, _ := s.GetUserByName("seeded@example.com")
+
+		userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
+		if err != nil {
+			t.Fatalf("failed to login as user: %v", err)
+		}
+		job, err := userClient.Accesses.Add(DefaultProviderID).Send()
+		if err != nil {
+			t.Fatalf("failed to add access: %v", err)
+		}
+
+		return []string{
+			user.ID,
+			job.URI,
+			strconv.FormatInt(access.ID, 10),
+			strconv.FormatInt(access.Accounts[0].ID, 10),
+		}
+	}
+
+	first := ids()
+	want := []string{"000003e9", "/jobs/000003e9", "1001", "1001"}
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("got ids %v, wanted %v", first, want)
 	}
-	if len(ac.Accesses) != 1 {
-		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	if second := ids(); !reflect.DeepEqual(first, second) {
+		t.Errorf("got ids %v from second server, wanted %v", second, first)
+	}
+}
+
+func TestAddUserWithAccess(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
+
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
+	seen := make(map[int64]string)
+	for _, name := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		userID, accessID := s.AddUserWithAccess(name, "password", DefaultProviderID)
+
+		userClient, err := appClient.Users.Login(name, "password").Send()
+		if err != nil {
+			t.Fatalf("failed to login as %s: %v", name, err)
+		}
+
+		accesses, err := userClient.Accesses.List().Send()
+		if err != nil {
+			t.Fatalf("failed to list accesses: %v", err)
+		}
+		if len(accesses.Accesses) != 1 || accesses.Accesses[0].ID != accessID {
+			t.Errorf("got accesses %+v for %s, wanted one with id %d", accesses.Accesses, name, accessID)
+		}
 
+		page, err := userClient.Transactions.List().Send()
+		if err != nil {
+			t.Fatalf("failed to list transactions: %v", err)
+		}
+		if len(page.Transactions) == 0 {
+			t.Errorf("got no transactions for %s", name)
+		}
+		for _, tx := range page.Transactions {
+			if tx.AccessID != accessID {
+				t.Errorf("got transaction %d of access %d for %s, wanted access %d", tx.ID, tx.AccessID, name, accessID)
+			}
+			if other, exists := seen[tx.ID]; exists {
+				t.Errorf("got transaction %d for both %s and %s", tx.ID, other, userID)
+			}
+			seen[tx.ID] = userID
+		}
+	}
 }
 
-func TestAccessRefreshWrongPin(t *testing.T) {
+func TestListTransfers(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -1407,132 +5162,87 @@ func TestAccessRefreshWrongPin(t *testing.T) {
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	accessID, _, err := addDefaultAccess(userClient, false)
+	_, accountID, err := addDefaultAccess(userClient, false)
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	job, err := userClient.Accesses.Refresh(accessID).Send()
-	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
 	}
-	t.Logf("job URI: %s", job.URI)
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
-	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
 	}
 
-	if status.Challenge == nil {
-		t.Fatalf("expecting job to have unanswered challenges")
+	completed, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-
-	if len(status.Challenge.NextChallenges) != 2 {
-		for _, ch := range status.Challenge.NextChallenges {
-			t.Logf("got challenge id %s", ch.ID)
+	answers := []bosgo.ChallengeAnswer{
+		{ID: "pin", Value: DefaultAccessPIN},
+		{ID: "auth_method", Value: DefaultAuthMethod},
+		{ID: "tan", Value: DefaultAuthAnswer},
+	}
+	for _, answer := range answers {
+		completed, err = userClient.Transfers.Process(completed.ID, completed.Step.Intent, completed.Version).ChallengeAnswer(answer).Send()
+		if err != nil {
+			t.Fatalf("failed to process %s: %v", answer.ID, err)
 		}
-		t.Fatalf("got %d challenges, wanted %d", len(status.Challenge.NextChallenges), 2)
+	}
+	if completed.State != bosgo.TransferStateSucceeded {
+		t.Fatalf("got state %v, wanted %v", completed.State, bosgo.TransferStateSucceeded)
 	}
 
-	req := userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: DefaultAccessLogin,
-	})
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: "wrongpin",
-	})
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer first challenge: %v", err)
+	ongoing, err := userClient.Transfers.Create(accountID, addr, amount).Send()
+	if err != nil {
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
+
+	page, err := userClient.Transfers.List().Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to list transfers: %v", err)
 	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	if len(page.Transfers) != 2 || page.Transfers[0].ID != completed.ID || page.Transfers[1].ID != ongoing.ID {
+		t.Fatalf("got transfers %+v, wanted %s and %s", page.Transfers, completed.ID, ongoing.ID)
 	}
-
-	if status.Challenge == nil {
-		t.Fatalf("expecting job to have unanswered challenges")
+	if page.Transfers[0].Created.IsZero() || page.Transfers[0].Updated.Before(page.Transfers[0].Created) {
+		t.Errorf("got created %v and updated %v, wanted set", page.Transfers[0].Created, page.Transfers[0].Updated)
 	}
 
-	if len(status.Errors) == 0 {
-		t.Fatalf("got 0 errors, wanted at least one")
+	page, err = userClient.Transfers.List().State(bosgo.TransferStateSucceeded).Send()
+	if err != nil {
+		t.Fatalf("failed to list transfers: %v", err)
 	}
-
-	gotWrongPinProblem := false
-	for _, p := range status.Errors {
-		if p.Code == "user_wrong_pin" {
-			gotWrongPinProblem = true
-			break
-		}
+	if len(page.Transfers) != 1 || page.Transfers[0].ID != completed.ID {
+		t.Fatalf("got transfers %+v, wanted %s", page.Transfers, completed.ID)
 	}
-
-	if !gotWrongPinProblem {
-		t.Errorf("did not get problem 'user_wrong_pin'")
+	if page.Transfers[0].State != bosgo.TransferStateSucceeded {
+		t.Errorf("got state %v, wanted %v", page.Transfers[0].State, bosgo.TransferStateSucceeded)
 	}
 
-	req = userClient.Jobs.Answer(job.URI)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: DefaultAccessPIN,
-	})
-
-	if err := req.Send(); err != nil {
-		t.Fatalf("failed to answer second challenge: %v", err)
-	}
-	status, err = userClient.Jobs.Get(job.URI).Send()
+	s.AddUserWithAccess("other@example.com", "password", DefaultProviderID)
+	otherClient, err := appClient.Users.Login("other@example.com", "password").Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to login as other user: %v", err)
 	}
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	page, err = otherClient.Transfers.List().Send()
+	if err != nil {
+		t.Fatalf("failed to list transfers: %v", err)
 	}
-	if !status.Finished {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+	if len(page.Transfers) != 0 {
+		t.Errorf("got %d transfers for other user, wanted 0", len(page.Transfers))
 	}
-
 }
 
-func TestAccessRefreshCustomProblems(t *testing.T) {
+func TestGetTransfer(t *testing.T) {
 	s := NewWithDefaults()
-
-	access := s.MakeAccess(DefaultProviderID+"_problems", "access with problems")
-	ad := AccessDetails{
-		Access:               *access,
-		Transactions:         []bosgo.Transaction{},
-		RepeatedTransactions: []bosgo.RepeatedTransaction{},
-		ChallengeMap: map[string]string{
-			ChallengeLogin: DefaultAccessLogin + "_problems",
-			ChallengePIN:   DefaultAccessPIN,
-		},
-		TransferAuths: []TransferAuth{
-			{
-				Method:  DefaultAuthMethod,
-				Message: DefaultAuthMessage,
-				Answer:  DefaultAuthAnswer,
-			},
-		},
-		StageProblems: map[bosgo.JobStage][]bosgo.Problem{
-			bosgo.JobStageChallenge: {
-				{
-					Code: "fi_accountnumber_10_digits_pin_between_5_to_10",
-				},
-			},
-		},
-	}
-	s.AddAccess(ad)
-
 	if testing.Verbose() {
 		s.SetLogger(t)
 	}
@@ -1540,47 +5250,52 @@ func TestAccessRefreshCustomProblems(t *testing.T) {
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
-
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
 
-	accessID, _, err := addAccess(userClient, DefaultProviderID+"_problems", DefaultAccessLogin+"_problems", DefaultAccessPIN)
+	_, accountID, err := addDefaultAccess(userClient, false)
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	job, err := userClient.Accesses.Refresh(accessID).Send()
-	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
 	}
-	t.Logf("job URI: %s", job.URI)
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
 	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	transfer, err = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).ChallengeAnswer(bosgo.ChallengeAnswer{ID: "pin", Value: DefaultAccessPIN}).Send()
+	if err != nil {
+		t.Fatalf("failed to process pin: %v", err)
 	}
 
-	if len(status.Errors) == 0 {
-		t.Fatal("missing status errors")
+	got, err := userClient.Transfers.Get(transfer.ID).Send()
+	if err != nil {
+		t.Fatalf("failed to get transfer: %v", err)
 	}
-
-	if status.Errors[0].Code != "fi_accountnumber_10_digits_pin_between_5_to_10" {
-		t.Fatalf("got bad error code %s, wanted %s", status.Errors[0].Code, "fi_accountnumber_10_digits_pin_between_5_to_10")
+	if got.Step.Intent != transfer.Step.Intent {
+		t.Errorf("got intent %v, wanted %v", got.Step.Intent, transfer.Step.Intent)
 	}
-
-	if status.Stage != bosgo.JobStageChallenge {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageChallenge)
+	if got.State != transfer.State || got.Version != transfer.Version {
+		t.Errorf("got state %v version %d, wanted state %v version %d", got.State, got.Version, transfer.State, transfer.Version)
 	}
-	if status.Finished {
-		t.Errorf("got finished %v, wanted false", status.Finished)
+
+	_, err = userClient.Transfers.Get("unknown").Send()
+	if code := errCode(err); code != "resource_not_found" {
+		t.Errorf("got error code %q, wanted resource_not_found", code)
 	}
 }
 
-func TestAccessRefreshStored(t *testing.T) {
+func TestTransferStoredPIN(t *testing.T) {
 	s := NewWithDefaults()
 	if testing.Verbose() {
 		s.SetLogger(t)
@@ -1588,91 +5303,103 @@ func TestAccessRefreshStored(t *testing.T) {
 	defer s.Close()
 
 	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
-	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 
+	amount := bosgo.MoneyAmount{
+		Currency: "EUR",
+		Value:    "12.50",
+	}
+
+	addr := bosgo.TransferAddress{
+		Name: "Jane Doe",
+		IBAN: "DE28500105175552834822",
+	}
+
+	// PIN stored while adding the access
+	userClient, err := appClient.Users.Login(DefaultUsername, DefaultPassword).Send()
 	if err != nil {
 		t.Fatalf("failed to login as user: %v", err)
 	}
-
-	accessID, _, err := addDefaultAccess(userClient, true)
+	_, accountID, err := addDefaultAccess(userClient, true)
 	if err != nil {
 		t.Fatalf("failed to add access: %v", err)
 	}
 
-	job, err := userClient.Accesses.Refresh(accessID).Send()
+	transfer, err := userClient.Transfers.Create(accountID, addr, amount).Send()
 	if err != nil {
-		t.Fatalf("failed to refresh access: %v", err)
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-	t.Logf("job URI: %s", job.URI)
-
-	// Expect to go straight to imported since challenges are stored
-	status, err := userClient.Jobs.Get(job.URI).Send()
-	if err != nil {
-		t.Fatalf("failed to get job status: %v", err)
+	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v with pin stored by access, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
 	}
 
-	if status.Stage != bosgo.JobStageImported {
-		t.Errorf("got stage %v, wanted %v", status.Stage, bosgo.JobStageImported)
+	// PIN stored while processing a transfer
+	if _, err := appClient.Users.Create("stored@example.com", "password").Send(); err != nil {
+		t.Fatalf("failed to create user: %v", err)
 	}
-	if status.Finished != true {
-		t.Errorf("got finished %v, wanted true", status.Finished)
+	userClient, err = appClient.Users.Login("stored@example.com", "password").Send()
+	if err != nil {
+		t.Fatalf("failed to login as user: %v", err)
+	}
+	_, accountID, err = addDefaultAccess(userClient, false)
+	if err != nil {
+		t.Fatalf("failed to add access: %v", err)
 	}
 
-	ac, err := userClient.Accesses.List().Send()
+	transfer, err = userClient.Transfers.Create(accountID, addr, amount).Send()
 	if err != nil {
-		t.Fatalf("failed to retrieve accesses: %v", err)
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-	if len(ac.Accesses) != 1 {
-		t.Errorf("got %d accesses, wanted 1", len(ac.Accesses))
+	if transfer.Step.Intent != bosgo.TransferIntentProvidePIN {
+		t.Fatalf("got intent %v without stored pin, wanted %v", transfer.Step.Intent, bosgo.TransferIntentProvidePIN)
 	}
-
-}
-
-func addAccess(userClient *bosgo.UserClient, provider, login, pin string) (int64, int64, error) {
-	req := userClient.Accesses.Add(provider)
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengeLogin,
-		Value: login,
-	})
-	req.ChallengeAnswer(bosgo.ChallengeAnswer{
-		ID:    ChallengePIN,
-		Value: pin,
-	})
-
-	job, err := req.Send()
+	transfer, err = userClient.Transfers.Process(transfer.ID, transfer.Step.Intent, transfer.Version).StoredChallengeAnswer(ChallengePIN, DefaultAccessPIN).Send()
 	if err != nil {
-		return 0, 0, err
+		t.Fatalf("failed to process pin: %v", err)
+	}
+	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Fatalf("got intent %v, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
 	}
 
-	status, err := userClient.Jobs.Get(job.URI).Send()
+	transfer, err = userClient.Transfers.Create(accountID, addr, amount).Send()
 	if err != nil {
-		return 0, 0, err
+		t.Fatalf("failed to create transfer: %v", err)
 	}
-	if status.Access == nil {
-		return 0, 0, fmt.Errorf("no access found")
+	if transfer.Step.Intent != bosgo.TransferIntentSelectAuthMethod {
+		t.Errorf("got intent %v with pin stored by transfer, wanted %v", transfer.Step.Intent, bosgo.TransferIntentSelectAuthMethod)
 	}
-
-	return status.Access.ID, status.Access.Accounts[0].ID, nil
 }
 
-func errCode(err error) string {
-	berr, ok := err.(*bosgo.Error)
-	if !ok {
-		return "<error not a bosgo.Error>"
+func TestProvidersInitialChallenge(t *testing.T) {
+	s := NewWithDefaults()
+	if testing.Verbose() {
+		s.SetLogger(t)
 	}
+	defer s.Close()
 
-	if len(berr.Errors) == 0 {
-		return "<no errors found>"
-	}
+	appClient := bosgo.NewAppClient(s.Client(), s.Addr(), DefaultApplicationKey)
 
-	return berr.Errors[0].Code
-}
+	// EmptyProviderID has an access but was not added as a provider, so its
+	// challenges come from the access
+	for _, providerID := range []string{DefaultProviderID, EmptyProviderID} {
+		fields, err := appClient.Providers.InitialChallenge(providerID).Send()
+		if err != nil {
+			t.Fatalf("failed to get initial challenge for %s: %v", providerID, err)
+		}
 
-func errStatusCode(err error) int {
-	berr, ok := err.(*bosgo.Error)
-	if !ok {
-		return 0
+		var ids []string
+		for _, f := range fields {
+			ids = append(ids, f.ID)
+			if f.ID == ChallengePIN && !f.Secure {
+				t.Errorf("got insecure pin field for %s, wanted secure", providerID)
+			}
+		}
+		if want := []string{ChallengeLogin, ChallengePIN}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("got fields %v for %s, wanted %v", ids, providerID, want)
+		}
 	}
 
-	return berr.StatusCode
+	_, err := appClient.Providers.InitialChallenge("unknown").Send()
+	if code := errCode(err); code != "resource_not_found" {
+		t.Errorf("got error code %q, wanted resource_not_found", code)
+	}
 }
@@ -1,6 +1,12 @@
 package bosgo
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -9,6 +15,14 @@ type DeveloperCredentials struct {
 	Password string `json:"password"`
 	OTP      string `json:"otp"`
 }
+
+// DeveloperConfirmAction re-confirms the developer's identity before a
+// destructive operation is performed.
+type DeveloperConfirmAction struct {
+	Password string `json:"password,omitempty"` // developer's password if registered by email and password
+	Email    string `json:"email,omitempty"`    // primary email address used on provider side if OAuth authorization is used
+}
+
 type DeveloperProfile struct {
 	Company             string          `json:"company"`
 	HasProductionAccess bool            `json:"has_production_access"`
@@ -121,12 +135,53 @@ type CategoryList []Category
 
 type Category struct {
 	ID    int64             `json:"id"`
-	Names map[string]string `json:"names"`
+	Names map[string]string `json:"names"` // names indexed by locale
 	Group string            `json:"group"`
 }
 
+// DefaultCategoryLocale is the locale whose category names are used when a
+// name in the requested locale is not available.
+const DefaultCategoryLocale = "en"
+
+// Name returns the name of the category in the given locale, such as "de".
+// If there is no name in that locale then the name in the locale's base
+// language, such as "de" for "de-AT", is returned, followed by the name in
+// DefaultCategoryLocale and finally the name in the first locale in
+// alphabetical order. Name returns an empty string only if the category has
+// no names.
+func (c Category) Name(locale string) string {
+	if name, ok := c.Names[locale]; ok {
+		return name
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		if name, ok := c.Names[locale[:i]]; ok {
+			return name
+		}
+	}
+	if name, ok := c.Names[DefaultCategoryLocale]; ok {
+		return name
+	}
+	locales := make([]string, 0, len(c.Names))
+	for l := range c.Names {
+		locales = append(locales, l)
+	}
+	if len(locales) == 0 {
+		return ""
+	}
+	sort.Strings(locales)
+	return c.Names[locales[0]]
+}
+
 type ProviderSearchResults []ProviderSearchResult
 
+// ProviderSearchPage is a page of provider search results.
+type ProviderSearchPage struct {
+	Results ProviderSearchResults
+	Total   int // total number of matching providers, or -1 if not reported by the API
+	Limit   int // the limit requested, or zero if none
+	Offset  int // the offset requested
+}
+
 type ProviderSearchResult struct {
 	Score    float64  `json:"score"`
 	Provider Provider `json:"provider"`
@@ -160,6 +215,37 @@ type ChallengeSpec struct {
 	Info        map[string]string `json:"info,omitempty"`
 }
 
+// StoreableFields returns the IDs of the provider's challenges whose answers
+// may be stored by the API for later use, i.e. those not marked unstoreable.
+// It may be used to decide which fields to offer to remember.
+func (p *Provider) StoreableFields() []string {
+	var ids []string
+	for _, ch := range p.Challenges {
+		if !ch.UnStoreable {
+			ids = append(ids, ch.ID)
+		}
+	}
+	return ids
+}
+
+// authMethods returns the distinct methods listed by the provider's
+// challenges. A method's description is taken from the challenge's info if
+// present.
+func (p *Provider) authMethods() []AuthMethod {
+	var methods []AuthMethod
+	seen := map[string]bool{}
+	for _, ch := range p.Challenges {
+		for _, m := range ch.Methods {
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			methods = append(methods, AuthMethod{ID: m, Description: ch.Info[m]})
+		}
+	}
+	return methods
+}
+
 type ChallengeType string
 
 const (
@@ -206,10 +292,45 @@ type Access struct {
 	Capabilities      AccessCapabilities `json:"capabilities"`
 	Beneficiaries     []Beneficiary      `json:"beneficiaries,omitempty"`
 	ConsentExpiration time.Time          `json:"consent_expiration,omitempty"`
+	LastRefreshedAt   time.Time          `json:"last_refreshed_at,omitempty"`
+	RefreshStatus     RefreshStatus      `json:"refresh_status,omitempty"`
 	// Personal information of the user, just for this access
 	UserInfo UserInfo `json:"user_info,omitempty"`
+
+	clock Clock // clock of the client that fetched the access
+}
+
+// IsStale reports whether the access has not been refreshed within maxAge,
+// judged by the clock of the client that fetched it. An access that has never
+// been refreshed is always stale.
+func (a *Access) IsStale(maxAge time.Duration) bool {
+	if a.LastRefreshedAt.IsZero() {
+		return true
+	}
+	return clockOrDefault(a.clock).Now().Sub(a.LastRefreshedAt) > maxAge
+}
+
+// DataFreshness returns the time as of which the access's data is complete,
+// which is the time of its last complete refresh, and reports whether that
+// refresh was the most recent one. When a refresh only partially succeeds,
+// for example because the provider timed out, LastRefreshedAt is left
+// unchanged so current is false and asOf may be used to tell the user how old
+// the data they are looking at is. asOf is zero if the access has never been
+// refreshed.
+func (a *Access) DataFreshness() (asOf time.Time, current bool) {
+	return a.LastRefreshedAt, !a.LastRefreshedAt.IsZero() && a.RefreshStatus == RefreshStatusOK
 }
 
+// RefreshStatus describes the outcome of the most recent refresh of an access.
+type RefreshStatus string
+
+const (
+	RefreshStatusOK               RefreshStatus = "ok"
+	RefreshStatusNeedsCredentials RefreshStatus = "needs_credentials"
+	RefreshStatusError            RefreshStatus = "error"
+	RefreshStatusPartial          RefreshStatus = "partial" // only some data could be refreshed
+)
+
 // UserInfo represents personal information about the user of this access
 type UserInfo struct {
 	PhoneNumber   string     `json:"phone_number,omitempty"`
@@ -259,6 +380,31 @@ type ProviderAllowedOperations struct {
 	ReadBeneficiaries   bool `json:"beneficiaries"`
 }
 
+// Allows reports whether the operation named by capability is allowed.
+// Capabilities are named as in the JSON encoding of ProviderAllowedOperations,
+// for example "transfer" or "balance". Unknown capabilities are not allowed.
+func (o ProviderAllowedOperations) Allows(capability string) bool {
+	switch capability {
+	case "transfer":
+		return o.PaymentTransfer
+	case "statement":
+		return o.AccountStatement
+	case "balance":
+		return o.AccountBalance
+	case "create_recurring_transfer":
+		return o.CreateRecTrf
+	case "read_recurring_transfer":
+		return o.ReadRecTrf
+	case "update_recurring_transfer":
+		return o.UpdateRecTrf
+	case "delete_recurring_transfer":
+		return o.DeleteRecTrf
+	case "beneficiaries":
+		return o.ReadBeneficiaries
+	}
+	return false
+}
+
 type AccountType string
 
 const (
@@ -270,18 +416,72 @@ const (
 
 type Job struct {
 	URI string `json:"uri"`
+
+	// Kind reports whether the job was started by adding a new access or by
+	// refreshing an existing one. It is set by the client from the request that
+	// started the job and is not part of the API response.
+	Kind JobKind `json:"-"`
 }
 
+// JobKind describes the operation that started a job.
+type JobKind string
+
+const (
+	// JobKindCreate is the kind of a job started by adding an access. These
+	// jobs usually need the user to answer challenges.
+	JobKindCreate JobKind = "create"
+
+	// JobKindRefresh is the kind of a job started by refreshing an access.
+	// These jobs may complete without further input when credentials are
+	// stored.
+	JobKindRefresh JobKind = "refresh"
+)
+
 type JobStatus struct {
 	Finished  bool        `json:"finished"`
 	Stage     JobStage    `json:"stage"`
 	Challenge *Challenge  `json:"challenge,omitempty"`
 	URI       string      `json:"uri,omitempty"`
 	Errors    []Problem   `json:"errors,omitempty"`
+	Warnings  Warnings    `json:"warnings,omitempty"`
 	Access    *JobAccess  `json:"access,omitempty"`
 	Consent   *JobConsent `json:"consent,omitempty"`
 }
 
+// RequiredAnswerIDs returns the IDs of the challenge fields that must be
+// answered for the job to progress. It returns nil if the job is not waiting
+// for answers.
+func (j *JobStatus) RequiredAnswerIDs() []string {
+	return j.answerIDs(false)
+}
+
+// OptionalAnswerIDs returns the IDs of the challenge fields that may be
+// answered but that the job can progress without, such as a selector for a
+// sub-account. It returns nil if the job is not waiting for answers.
+func (j *JobStatus) OptionalAnswerIDs() []string {
+	return j.answerIDs(true)
+}
+
+func (j *JobStatus) answerIDs(optional bool) []string {
+	if j.Challenge == nil {
+		return nil
+	}
+	var ids []string
+	for _, f := range j.Challenge.NextChallenges {
+		if f.Optional == optional {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
+
+// IsPartial reports whether the job finished importing but only part of the
+// data could be refreshed, as reported by a WarningPartialData warning. The
+// access's data may then be stale; see Access.DataFreshness.
+func (j *JobStatus) IsPartial() bool {
+	return j.Stage == JobStageImported && j.Warnings.Has(WarningPartialData)
+}
+
 type JobStage string
 
 const (
@@ -301,6 +501,10 @@ type JobConsent struct {
 }
 
 type Challenge struct {
+	CanContinue    bool             `json:"can_continue"`
+	CurStep        int              `json:"current_step"`
+	MaxSteps       int              `json:"max_steps,omitempty"`
+	Hint           string           `json:"hint,omitempty"`
 	NextChallenges []ChallengeField `json:"next_challenges"`
 	LastProblems   []Problem        `json:"last_problems"`
 }
@@ -328,11 +532,47 @@ type Problem struct {
 	ContainsPrivateInformation bool                   `json:"contains_private_information"`
 }
 
+// Warnings holds problems reported alongside an otherwise successful
+// response, for example when data could only be partially imported because a
+// provider timed out. Data returned with warnings is usable but may be stale or
+// incomplete.
+type Warnings []Problem
+
+// WarningPartialData is the code of the warning reported by a job that
+// finished but could only import part of the access's data.
+const WarningPartialData = "partial_data"
+
+// Has reports whether a warning with the given code is present.
+func (w Warnings) Has(code string) bool {
+	for _, p := range w {
+		if p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Codes returns the codes of the warnings in the order they were reported.
+func (w Warnings) Codes() []string {
+	var codes []string
+	for _, p := range w {
+		codes = append(codes, p.Code)
+	}
+	return codes
+}
+
 type JobAccess struct {
 	ID         int64        `json:"id,omitempty"`
 	ProviderID string       `json:"provider_id,omitempty"`
 	Name       string       `json:"name,omitempty"`
-	Accounts   []JobAccount `json:"accounts,omitempty"`
+	Accounts   []JobAccount `json:"accounts,omitempty"` // may be empty, see HasAccounts
+}
+
+// HasAccounts reports whether any accounts were imported with the access. A
+// login to a bank can succeed without yielding any supported accounts, so
+// callers must check HasAccounts before indexing Accounts.
+func (a *JobAccess) HasAccounts() bool {
+	return a != nil && len(a.Accounts) > 0
 }
 
 type JobAccount struct {
@@ -369,6 +609,83 @@ type Transaction struct {
 	Gvcode                string          `json:"gvcode,omitempty"`
 }
 
+// TransactionOrder specifies the order in which transactions are listed.
+type TransactionOrder string
+
+const (
+	TransactionOrderEntryDate     TransactionOrder = "entry_date"  // oldest first
+	TransactionOrderEntryDateDesc TransactionOrder = "-entry_date" // newest first
+	TransactionOrderAmount        TransactionOrder = "amount"      // smallest first, so largest payments first
+	TransactionOrderAmountDesc    TransactionOrder = "-amount"     // largest first, so largest receipts first
+)
+
+// transferBookingWindow is the period after a transfer's entry date within
+// which its resulting transaction is expected to be booked.
+const transferBookingWindow = 3 * 24 * time.Hour
+
+// MatchesTransfer reports whether the transaction looks like the booking of
+// the transfer tr. The API does not link transactions to the transfers that
+// created them, so the transaction must debit the transfer's source account
+// by the transferred amount, be paid to the transfer's recipient and be
+// entered within a few days of the transfer's entry date.
+func (t Transaction) MatchesTransfer(tr *Transfer) bool {
+	if t.Amount == nil || tr.Amount == nil || tr.EntryDate.IsZero() {
+		return false
+	}
+	if tr.From.AccountID != 0 && t.UserAccountID != tr.From.AccountID {
+		return false
+	}
+	if !sameIBAN(t.Counterparty.Account.IBAN, tr.To.IBAN) {
+		return false
+	}
+
+	if t.Amount.Currency != tr.Amount.Currency {
+		return false
+	}
+	booked, ok := new(big.Rat).SetString(t.Amount.Value)
+	if !ok {
+		return false
+	}
+	transferred, ok := new(big.Rat).SetString(tr.Amount.Value)
+	if !ok {
+		return false
+	}
+	if booked.Neg(booked).Cmp(transferred) != 0 {
+		return false
+	}
+
+	day := tr.EntryDate.Truncate(24 * time.Hour)
+	return !t.EntryDate.Before(day) && t.EntryDate.Before(day.Add(transferBookingWindow))
+}
+
+// Hash returns a stable hash of the transaction's content that may be used to
+// match the same transaction across refreshes when its ID is not stable. Only
+// fields that a bank does not change once a transaction has been booked are
+// included: the entry and settlement dates, the amount and its currency, the
+// usage text and the IBAN of the counterparty. Categorisation and other
+// enrichment added by Bankrs is deliberately excluded.
+func (t Transaction) Hash() string {
+	var currency, value string
+	if t.Amount != nil {
+		currency = t.Amount.Currency
+		value = t.Amount.Value
+	}
+
+	h := sha256.New()
+	for _, field := range []string{
+		t.EntryDate.UTC().Format(time.RFC3339),
+		t.SettlementDate.UTC().Format(time.RFC3339),
+		value,
+		currency,
+		t.Usage,
+		t.Counterparty.Account.IBAN,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 type AccountRef struct {
 	ProviderID string `json:"provider_id"`
 	IBAN       string `json:"iban,omitempty"`
@@ -411,12 +728,196 @@ type RepeatedTransaction struct {
 	Usage         string         `json:"usage"`
 }
 
+// Describe returns a short human readable summary of the standing order, such
+// as "500.00 EUR to DE04200800957050250010 monthly".
+func (r RepeatedTransaction) Describe() string {
+	var desc string
+	if r.Amount != nil {
+		desc = r.Amount.Value + " " + r.Amount.Currency + " "
+	}
+
+	desc += "to "
+	switch {
+	case r.RemoteAccount.Label != "" && r.RemoteAccount.IBAN != "":
+		desc += r.RemoteAccount.Label + " (" + r.RemoteAccount.IBAN + ")"
+	case r.RemoteAccount.Label != "":
+		desc += r.RemoteAccount.Label
+	case r.RemoteAccount.IBAN != "":
+		desc += r.RemoteAccount.IBAN
+	default:
+		desc += "unknown account"
+	}
+
+	return desc + " " + r.Schedule.describeFrequency()
+}
+
+// NextAmount returns the amount and date of the first payment of the
+// standing order that is due after t. The boolean result is false if no
+// further payments are due.
+func (r RepeatedTransaction) NextAmount(t time.Time) (MoneyAmount, time.Time, bool) {
+	if r.Amount == nil {
+		return MoneyAmount{}, time.Time{}, false
+	}
+	next := r.Schedule.firstAfter(t)
+	if next.IsZero() {
+		return MoneyAmount{}, time.Time{}, false
+	}
+	return *r.Amount, next, true
+}
+
+// TotalCommitted returns the sum of all payments of the standing order that
+// are due after t. The boolean result is false if the standing order has no
+// end date, so its total is unbounded, or its amount cannot be parsed.
+func (r RepeatedTransaction) TotalCommitted(t time.Time) (MoneyAmount, bool) {
+	if r.Amount == nil || (r.Schedule.Until.IsZero() && r.Schedule.Frequency != FrequencyOnce) {
+		return MoneyAmount{}, false
+	}
+	amount, ok := new(big.Rat).SetString(r.Amount.Value)
+	if !ok {
+		return MoneyAmount{}, false
+	}
+
+	total := new(big.Rat)
+	for next := r.Schedule.firstAfter(t); !next.IsZero(); next = r.Schedule.Next(next) {
+		total.Add(total, amount)
+	}
+	return MoneyAmount{Currency: r.Amount.Currency, Value: total.FloatString(2)}, true
+}
+
 type RecurrenceRule struct {
 	Start     time.Time `json:"start"`
 	Until     time.Time `json:"until"`
 	Frequency Frequency `json:"frequency"`
 	Interval  int       `json:"interval"`
-	ByDay     int       `json:"by_day"`
+	// ByDay is the day within the frequency unit on which the rule occurs: a
+	// weekday (1-5, starting with Monday) for weekly rules or a day of the
+	// month (1-31, or -1 for the last day) for monthly rules. When zero the
+	// rule occurs on the weekday or day of the month of Start.
+	ByDay int `json:"by_day"`
+}
+
+// Next returns the first occurrence of the rule that is after t. Occurrences
+// are counted from Start, or from t if Start is zero, so a monthly rule that
+// starts on January 31 occurs on the last day of February and then on March
+// 31. It returns the zero time if the rule does not repeat or the following
+// occurrence would be after Until.
+func (r RecurrenceRule) Next(t time.Time) time.Time {
+	start := r.Start
+	if start.IsZero() {
+		start = t
+	}
+
+	// Estimate the number of occurrences up to t, erring low, and step
+	// forwards from there
+	var n int
+	switch r.Frequency {
+	case FrequencyDaily:
+		n = int(t.Sub(start).Hours()/24) / r.interval()
+	case FrequencyWeekly:
+		n = int(t.Sub(start).Hours()/(24*7)) / r.interval()
+	case FrequencyMonthly:
+		n = ((t.Year()-start.Year())*12 + int(t.Month()-start.Month())) / r.interval()
+	case FrequencyYearly:
+		n = (t.Year() - start.Year()) / r.interval()
+	default:
+		return time.Time{}
+	}
+	n--
+	if n < 0 {
+		n = 0
+	}
+
+	next := r.occurrence(start, n)
+	for !next.After(t) || next.Before(start) {
+		n++
+		next = r.occurrence(start, n)
+	}
+
+	if !r.Until.IsZero() && next.After(r.Until) {
+		return time.Time{}
+	}
+	return next
+}
+
+func (r RecurrenceRule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// occurrence returns the nth occurrence of the rule counted from start. Days
+// of the month that do not exist in a month, such as the 31st or February 29,
+// are clamped to the last day of that month.
+func (r RecurrenceRule) occurrence(start time.Time, n int) time.Time {
+	interval := r.interval()
+	switch r.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n*interval)
+	case FrequencyWeekly:
+		if r.ByDay >= 1 && r.ByDay <= 5 {
+			start = start.AddDate(0, 0, (r.ByDay-int(start.Weekday())+7)%7)
+		}
+		return start.AddDate(0, 0, 7*n*interval)
+	case FrequencyMonthly:
+		day := start.Day()
+		if r.ByDay != 0 {
+			day = r.ByDay
+		}
+		return dayOfMonth(start, start.Year(), start.Month()+time.Month(n*interval), day)
+	case FrequencyYearly:
+		return dayOfMonth(start, start.Year()+n*interval, start.Month(), start.Day())
+	}
+	return time.Time{}
+}
+
+// dayOfMonth returns the given day of the month at the time of day of t. A day
+// of -1 or one past the end of the month gives the last day of the month. The
+// month may be out of range and is normalized as by time.Date.
+func dayOfMonth(t time.Time, year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day < 1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// firstAfter returns the first occurrence of the rule that is after t, or the
+// zero time if there is none.
+func (r RecurrenceRule) firstAfter(t time.Time) time.Time {
+	if r.Start.IsZero() {
+		return time.Time{}
+	}
+	if r.Frequency == FrequencyOnce {
+		if r.Start.After(t) {
+			return r.Start
+		}
+		return time.Time{}
+	}
+	return r.Next(t)
+}
+
+// describeFrequency describes how often the rule repeats, such as "monthly"
+// or "every 2 weeks".
+func (r RecurrenceRule) describeFrequency() string {
+	var unit string
+	switch r.Frequency {
+	case FrequencyDaily:
+		unit = "day"
+	case FrequencyWeekly:
+		unit = "week"
+	case FrequencyMonthly:
+		unit = "month"
+	case FrequencyYearly:
+		unit = "year"
+	default:
+		return "once"
+	}
+	if r.Interval > 1 {
+		return fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	return string(r.Frequency)
 }
 
 type Frequency string
@@ -441,6 +942,12 @@ type TransferAddress struct {
 	AccountID int64  `json:"bank_account_id,omitempty"`
 }
 
+// normalized returns a copy of the address with its IBAN normalized.
+func (a TransferAddress) normalized() TransferAddress {
+	a.IBAN = NormalizeIBAN(a.IBAN)
+	return a
+}
+
 type TransferType string
 
 const (
@@ -465,9 +972,22 @@ type Transfer struct {
 	Updated        time.Time        `json:"updated,omitempty"`
 	RemoteID       string           `json:"remote_id"`
 	Errors         []Problem        `json:"errors"`
+	Warnings       Warnings         `json:"warnings,omitempty"`
 	Consent        *TransferConsent `json:"consent,omitempty"`
 }
 
+// SettledAt returns the date on which the transfer settles at the recipient's
+// bank. The boolean result is false if the settlement date is not yet known,
+// which is the case until the transfer has succeeded.
+func (t Transfer) SettledAt() (time.Time, bool) {
+	return t.SettlementDate, !t.SettlementDate.IsZero()
+}
+
+// TransferPage is a list of a user's money transfers.
+type TransferPage struct {
+	Transfers []Transfer `json:"transfers"`
+}
+
 type RecurringTransfer struct {
 	ID       string           `json:"id"`
 	From     TransferAddress  `json:"from"`
@@ -494,6 +1014,9 @@ const (
 	TransferStateCancelled TransferState = "cancelled"
 )
 
+// String returns the state as it is represented in the API.
+func (s TransferState) String() string { return string(s) }
+
 type TransferIntent string
 
 const (
@@ -505,6 +1028,9 @@ const (
 	TransferIntentConsent                TransferIntent = "consent"
 )
 
+// String returns the intent as it is represented in the API.
+func (i TransferIntent) String() string { return string(i) }
+
 type PaymentTransferCancelParams struct {
 	ID      string `json:"id"`
 	Version int    `json:"version"`
@@ -561,9 +1087,23 @@ type TransferConsent struct {
 }
 
 type DeletedUser struct {
-	DeletedUserID string `json:"deleted_user_id"`
+	DeletedUserID string     `json:"deleted_user_id"`
+	Mode          DeleteMode `json:"mode,omitempty"` // how the user was deleted, if reported
 }
 
+// DeleteMode specifies how a user is deleted.
+type DeleteMode string
+
+const (
+	// DeleteModeDeactivate deactivates the user and logs out all of their
+	// sessions. The user's data is retained for the retention period
+	// required by regulation before being purged.
+	DeleteModeDeactivate DeleteMode = "deactivate"
+
+	// DeleteModeErase purges the user and all of their data immediately.
+	DeleteModeErase DeleteMode = "erase"
+)
+
 type IBANDetails struct {
 	Account IBANAccount `json:"acc_ref"`
 	Banks   []IBANBank  `json:"fis"`
@@ -697,6 +1237,76 @@ type LinkedTeam struct {
 	Owner  bool   `json:"owner"`
 }
 
+// Team is a group of developers that share access to the owner's resources.
+type Team struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Owner     bool         `json:"owner"` // whether the requesting developer owns the team
+	Members   []TeamMember `json:"members"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// TeamNew holds the details of a team to be created.
+type TeamNew struct {
+	Name string `json:"name"`
+}
+
+// TeamMember is a developer who belongs to a team.
+type TeamMember struct {
+	ID        string    `json:"id,omitempty"`
+	Email     string    `json:"email"`
+	Owner     bool      `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamInvite holds the email address of a developer to be invited to a team.
+type TeamInvite struct {
+	Email string `json:"email"`
+}
+
+// TeamInviteToken holds the token sent to an invited developer, used to accept
+// the invite.
+type TeamInviteToken struct {
+	Token string `json:"token"`
+}
+
+// TeamInviteStep describes what an invited developer must do before an invite
+// can be accepted.
+type TeamInviteStep string
+
+const (
+	TeamInviteStepLogin         TeamInviteStep = "login"
+	TeamInviteStepCreateAccount TeamInviteStep = "create_account"
+)
+
+// TeamInviteResponse is the outcome of accepting an invite to join a team.
+type TeamInviteResponse struct {
+	TeamName string         `json:"team_name"`
+	Success  bool           `json:"success"`
+	Step     TeamInviteStep `json:"step,omitempty"`
+}
+
+// TeamMemberAccess is the access level a team member has to one of the team's
+// resources.
+type TeamMemberAccess struct {
+	ResourceName string    `json:"resource_name"`
+	AccessLevel  int       `json:"access_level"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TeamMemberNewAccess holds an access level to be granted to a team member for
+// one of the team's resources.
+type TeamMemberNewAccess struct {
+	ResourceName string `json:"resource_name"`
+	AccessLevel  int    `json:"access_level"`
+}
+
+// TeamMemberUpdateAccess holds the complete set of access levels to be granted
+// to a team member, replacing any they have.
+type TeamMemberUpdateAccess struct {
+	Accesses []TeamMemberNewAccess `json:"accesses"`
+}
+
 type CredentialsPage struct {
 	Entries []CredentialEntry `json:"entries,omitempty"`
 }
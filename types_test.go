@@ -2,12 +2,14 @@ package bosgo
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // typeMap is a mapping of api blueprint data structure name to bosgo type
@@ -47,7 +49,7 @@ var typeMap = map[string]interface{}{
 	"DailyRequestsStats":               DailyRequestsStats{},
 	"DailyTransfersStats":              DailyTransfersStats{},
 	"DailyUsersStats":                  DailyUsersStats{},
-	"DeveloperConfirmAction":           nil,
+	"DeveloperConfirmAction":           DeveloperConfirmAction{},
 	"DeveloperCredentials":             DeveloperCredentials{},
 	"DeveloperOAuthLogin":              nil,
 	"DeveloperProfile":                 DeveloperProfile{},
@@ -84,14 +86,14 @@ var typeMap = map[string]interface{}{
 	"StatsMoneyAmount":                 StatsMoneyAmount{},
 	"StatsValueChange":                 StatsValueChange{},
 	"TeamAccess":                       nil,
-	"TeamInvite":                       nil,
-	"TeamInviteResponse":               nil,
-	"TeamInviteToken":                  nil,
-	"TeamMember":                       nil,
-	"TeamMemberAccess":                 nil,
-	"TeamMemberNewAccess":              nil,
-	"TeamMemberUpdateAccess":           nil,
-	"TeamNew":                          nil,
+	"TeamInvite":                       TeamInvite{},
+	"TeamInviteResponse":               TeamInviteResponse{},
+	"TeamInviteToken":                  TeamInviteToken{},
+	"TeamMember":                       TeamMember{},
+	"TeamMemberAccess":                 TeamMemberAccess{},
+	"TeamMemberNewAccess":              TeamMemberNewAccess{},
+	"TeamMemberUpdateAccess":           TeamMemberUpdateAccess{},
+	"TeamNew":                          TeamNew{},
 	"TeamUpdate":                       nil,
 	"Transaction":                      Transaction{},
 	"TransactionCategorisationRequest": nil,
@@ -119,9 +121,17 @@ var typeMap = map[string]interface{}{
 }
 
 var exclusions = map[string][]string{
+	"Access": {
+		"last_refreshed_at", // decoded by bosgo but not yet documented by the API
+		"refresh_status",    // decoded by bosgo but not yet documented by the API
+	},
+	"JobStatus": {
+		"warnings", // decoded by bosgo but not yet documented by the API
+	},
 	"TransferResponse": {
 		"type",     // bosgo uses separate structs for the two types of transfer response
 		"schedule", // schedile only used for recurring transfer responses
+		"warnings", // decoded by bosgo but not yet documented by the API
 	},
 }
 
@@ -170,7 +180,7 @@ func TestTypes(t *testing.T) {
 
 			// Check if bosgo has extra fields defined
 			for bosField := range fieldsByTag {
-				if bosField == "-" {
+				if bosField == "-" || excluded(bpType.Name, bosField) {
 					continue
 				}
 				if _, ok := bpFields[bosField]; !ok {
@@ -194,6 +204,9 @@ func getFieldTags(typ reflect.Type) map[string]reflect.StructField {
 	rec = func(typ reflect.Type) {
 		for i := 0; i < typ.NumField(); i++ {
 			f := typ.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported fields are never part of the API
+			}
 			if f.Anonymous {
 				rec(f.Type)
 				continue
@@ -532,3 +545,397 @@ func TestParser(t *testing.T) {
 		t.Errorf("unexpected error: %v", p.Err())
 	}
 }
+
+func TestTransactionHash(t *testing.T) {
+	newTransaction := func() Transaction {
+		return Transaction{
+			ID:             1,
+			EntryDate:      time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC),
+			SettlementDate: time.Date(2017, 6, 2, 0, 0, 0, 0, time.UTC),
+			Amount: &MoneyAmount{
+				Currency: "EUR",
+				Value:    "-12.50",
+			},
+			Usage: "Invoice 1234",
+			Counterparty: Counterparty{
+				Name: "ACME",
+				Account: AccountRef{
+					IBAN: "DE89370400440532013000",
+				},
+			},
+		}
+	}
+
+	tx1 := newTransaction()
+	tx2 := newTransaction()
+	tx2.ID = 2
+	tx2.CategoryID = 99
+
+	if tx1.Hash() != tx2.Hash() {
+		t.Errorf("got different hashes for identical transactions: %s, %s", tx1.Hash(), tx2.Hash())
+	}
+
+	tx3 := newTransaction()
+	tx3.Amount.Value = "-12.51"
+	if tx1.Hash() == tx3.Hash() {
+		t.Errorf("got identical hashes for transactions with different amounts: %s", tx1.Hash())
+	}
+}
+
+func TestJobStageJSON(t *testing.T) {
+	stages := map[JobStage]string{
+		JobStageUnauthenticated: "unauthenticated",
+		JobStageAuthenticated:   "authenticated",
+		JobStageChallenge:       "challenge",
+		JobStageImported:        "imported",
+		JobStageCancelled:       "cancelled",
+		JobStageProblem:         "problem",
+		JobStageConsent:         "consent",
+	}
+
+	for stage, wire := range stages {
+		data, err := json.Marshal(JobStatus{Stage: stage})
+		if err != nil {
+			t.Fatalf("failed to marshal job status: %v", err)
+		}
+		if !strings.Contains(string(data), `"stage":"`+wire+`"`) {
+			t.Errorf("got %s, wanted stage %q", data, wire)
+		}
+
+		var status JobStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			t.Fatalf("failed to unmarshal job status: %v", err)
+		}
+		if status.Stage != stage {
+			t.Errorf("got stage %q, wanted %q", status.Stage, stage)
+		}
+	}
+}
+
+func TestTransferStateString(t *testing.T) {
+	states := map[TransferState]string{
+		TransferStateOngoing:   "ongoing",
+		TransferStateSucceeded: "succeeded",
+		TransferStateFailed:    "failed",
+		TransferStateCancelled: "cancelled",
+	}
+
+	for state, wire := range states {
+		if got := state.String(); got != wire {
+			t.Errorf("got %q, wanted %q", got, wire)
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			t.Fatalf("failed to marshal state: %v", err)
+		}
+		if want := `"` + wire + `"`; string(data) != want {
+			t.Errorf("got %s, wanted %s", data, want)
+		}
+	}
+}
+
+func TestTransferIntentString(t *testing.T) {
+	intents := map[TransferIntent]string{
+		TransferIntentProvidePIN:             "provide_pin",
+		TransferIntentProvideCredentials:     "provide_credentials",
+		TransferIntentSelectAuthMethod:       "select_auth_method",
+		TransferIntentProvideChallengeAnswer: "provide_challenge_answer",
+		TransferIntentConfirmSimilarTransfer: "confirm_similar_transfer",
+		TransferIntentConsent:                "consent",
+	}
+
+	for intent, wire := range intents {
+		if got := intent.String(); got != wire {
+			t.Errorf("got %q, wanted %q", got, wire)
+		}
+		data, err := json.Marshal(intent)
+		if err != nil {
+			t.Fatalf("failed to marshal intent: %v", err)
+		}
+		if want := `"` + wire + `"`; string(data) != want {
+			t.Errorf("got %s, wanted %s", data, want)
+		}
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	start := time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC)
+	leapDay := time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		rule RecurrenceRule
+		t    time.Time
+		want time.Time
+	}{
+		{
+			rule: RecurrenceRule{Frequency: FrequencyDaily, Interval: 2},
+			t:    start,
+			want: time.Date(2017, 2, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyWeekly},
+			t:    start,
+			want: time.Date(2017, 2, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyWeekly, ByDay: 5},
+			t:    start,
+			want: time.Date(2017, 2, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyMonthly, Interval: 1},
+			t:    start,
+			want: time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: start, Frequency: FrequencyMonthly, Interval: 1},
+			t:    time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: start, Frequency: FrequencyMonthly, Interval: 1},
+			t:    time.Date(2017, 3, 31, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 4, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: start, Frequency: FrequencyMonthly, Interval: 2},
+			t:    time.Date(2017, 3, 31, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), Frequency: FrequencyMonthly, ByDay: 24},
+			t:    time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 1, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: time.Date(2017, 1, 25, 0, 0, 0, 0, time.UTC), Frequency: FrequencyMonthly, ByDay: 24},
+			t:    time.Date(2017, 1, 25, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 2, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: time.Date(2017, 1, 10, 0, 0, 0, 0, time.UTC), Frequency: FrequencyMonthly, ByDay: -1},
+			t:    time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyYearly, Interval: 1},
+			t:    start,
+			want: time.Date(2018, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: leapDay, Frequency: FrequencyYearly, Interval: 1},
+			t:    leapDay,
+			want: time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Start: leapDay, Frequency: FrequencyYearly, Interval: 1},
+			t:    time.Date(2019, 2, 28, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyYearly, Interval: 1, Until: time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC)},
+			t:    start,
+			want: time.Time{},
+		},
+		{
+			rule: RecurrenceRule{Frequency: FrequencyOnce},
+			t:    start,
+			want: time.Time{},
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.rule.Next(tc.t); !got.Equal(tc.want) {
+			t.Errorf("%+v after %v: got %v, wanted %v", tc.rule, tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestProviderStoreableFields(t *testing.T) {
+	p := Provider{
+		Challenges: []ChallengeSpec{
+			{ID: "login", Type: ChallengeTypeAlphaNumeric},
+			{ID: "pin", Type: ChallengeTypeNumeric, Secure: true},
+			{ID: "tan", Type: ChallengeTypeNumeric, Secure: true, UnStoreable: true},
+			{ID: "customer_id", Type: ChallengeTypeAlphaNumeric, Optional: true},
+		},
+	}
+
+	got := p.StoreableFields()
+	want := []string{"login", "pin", "customer_id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got storeable fields %v, wanted %v", got, want)
+	}
+
+	p.Challenges = []ChallengeSpec{{ID: "tan", UnStoreable: true}}
+	if got := p.StoreableFields(); len(got) != 0 {
+		t.Errorf("got storeable fields %v, wanted none", got)
+	}
+}
+
+func TestCategoryName(t *testing.T) {
+	c := Category{
+		ID:    1,
+		Names: map[string]string{"de": "Bargeld", "en": "Cash", "fr": "Espèces"},
+	}
+
+	testCases := []struct {
+		locale string
+		want   string
+	}{
+		{locale: "de", want: "Bargeld"},
+		{locale: "de-AT", want: "Bargeld"},
+		{locale: "fr_CH", want: "Espèces"},
+		{locale: "it", want: "Cash"},
+		{locale: "", want: "Cash"},
+	}
+	for _, tc := range testCases {
+		if got := c.Name(tc.locale); got != tc.want {
+			t.Errorf("Name(%q): got %q, wanted %q", tc.locale, got, tc.want)
+		}
+	}
+
+	c.Names = map[string]string{"fr": "Espèces", "de": "Bargeld"}
+	if got := c.Name("it"); got != "Bargeld" {
+		t.Errorf("Name(%q) without default locale: got %q, wanted %q", "it", got, "Bargeld")
+	}
+
+	if got := (Category{}).Name("de"); got != "" {
+		t.Errorf("Name(%q) without names: got %q, wanted empty", "de", got)
+	}
+}
+
+func TestAccessIsStale(t *testing.T) {
+	now := time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		lastRefreshed time.Time
+		want          bool
+	}{
+		{lastRefreshed: time.Time{}, want: true},
+		{lastRefreshed: now.Add(-time.Minute), want: false},
+		{lastRefreshed: now.Add(-time.Hour), want: false},
+		{lastRefreshed: now.Add(-2 * time.Hour), want: true},
+	}
+
+	for _, tc := range testCases {
+		a := Access{LastRefreshedAt: tc.lastRefreshed, clock: &fakeClock{now: now}}
+		if got := a.IsStale(time.Hour); got != tc.want {
+			t.Errorf("IsStale(1h) with last refresh %v: got %v, wanted %v", tc.lastRefreshed, got, tc.want)
+		}
+	}
+}
+
+func TestTransactionMatchesTransfer(t *testing.T) {
+	entry := time.Date(2017, 7, 31, 14, 0, 0, 0, time.UTC)
+	tr := &Transfer{
+		From:      TransferAddress{AccountID: 2},
+		To:        TransferAddress{Name: "Jane Doe", IBAN: "DE28500105175552834822"},
+		Amount:    &MoneyAmount{Currency: "EUR", Value: "12.5"},
+		EntryDate: entry,
+	}
+
+	booking := Transaction{
+		UserAccountID: 2,
+		Counterparty:  Counterparty{Account: AccountRef{IBAN: "DE28 5001 0517 5552 8348 22"}},
+		Amount:        &MoneyAmount{Currency: "EUR", Value: "-12.50"},
+		EntryDate:     time.Date(2017, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(tx *Transaction)
+		want   bool
+	}{
+		{name: "match", modify: func(tx *Transaction) {}, want: true},
+		{name: "other account", modify: func(tx *Transaction) { tx.UserAccountID = 3 }, want: false},
+		{name: "other recipient", modify: func(tx *Transaction) { tx.Counterparty.Account.IBAN = "DE89370400440532013000" }, want: false},
+		{name: "other amount", modify: func(tx *Transaction) { tx.Amount = &MoneyAmount{Currency: "EUR", Value: "-12.51"} }, want: false},
+		{name: "credit", modify: func(tx *Transaction) { tx.Amount = &MoneyAmount{Currency: "EUR", Value: "12.50"} }, want: false},
+		{name: "other currency", modify: func(tx *Transaction) { tx.Amount = &MoneyAmount{Currency: "USD", Value: "-12.50"} }, want: false},
+		{name: "booked before", modify: func(tx *Transaction) { tx.EntryDate = entry.AddDate(0, 0, -1) }, want: false},
+		{name: "booked much later", modify: func(tx *Transaction) { tx.EntryDate = entry.AddDate(0, 0, 5) }, want: false},
+	}
+
+	for _, tc := range testCases {
+		tx := booking
+		tc.modify(&tx)
+		if got := tx.MatchesTransfer(tr); got != tc.want {
+			t.Errorf("%s: got %v, wanted %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRepeatedTransactionDescribe(t *testing.T) {
+	testCases := []struct {
+		rt   RepeatedTransaction
+		want string
+	}{
+		{
+			rt: RepeatedTransaction{
+				Amount:        &MoneyAmount{Currency: "EUR", Value: "500.00"},
+				RemoteAccount: AccountRef{IBAN: "DE04200800957050250010", Label: "Landlord"},
+				Schedule:      RecurrenceRule{Frequency: FrequencyMonthly, Interval: 1},
+			},
+			want: "500.00 EUR to Landlord (DE04200800957050250010) monthly",
+		},
+		{
+			rt: RepeatedTransaction{
+				Amount:        &MoneyAmount{Currency: "EUR", Value: "20.00"},
+				RemoteAccount: AccountRef{Label: "Gym"},
+				Schedule:      RecurrenceRule{Frequency: FrequencyWeekly, Interval: 2},
+			},
+			want: "20.00 EUR to Gym every 2 weeks",
+		},
+		{
+			rt: RepeatedTransaction{
+				Schedule: RecurrenceRule{Frequency: FrequencyOnce},
+			},
+			want: "to unknown account once",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.rt.Describe(); got != tc.want {
+			t.Errorf("got %q, wanted %q", got, tc.want)
+		}
+	}
+}
+
+func TestRepeatedTransactionTotalCommittedOpenEnded(t *testing.T) {
+	rt := RepeatedTransaction{
+		Amount:   &MoneyAmount{Currency: "EUR", Value: "500.00"},
+		Schedule: RecurrenceRule{Start: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), Frequency: FrequencyMonthly},
+	}
+
+	if total, ok := rt.TotalCommitted(time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("got total %+v for open ended standing order, wanted none", total)
+	}
+	if _, date, ok := rt.NextAmount(time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)); !ok || !date.Equal(time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got next date %v (%v), wanted 2017-07-01", date, ok)
+	}
+}
+
+func TestRepeatedTransactionMonthEnd(t *testing.T) {
+	rt := RepeatedTransaction{
+		Amount: &MoneyAmount{Currency: "EUR", Value: "100.00"},
+		Schedule: RecurrenceRule{
+			Start:     time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2017, 12, 30, 0, 0, 0, 0, time.UTC),
+			Frequency: FrequencyMonthly,
+		},
+	}
+	after := time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	if _, date, ok := rt.NextAmount(after); !ok || !date.Equal(time.Date(2017, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got next date %v (%v), wanted 2017-03-31", date, ok)
+	}
+
+	// Payments on the last day of each month from March to November 2017,
+	// since December 31 is after Until
+	total, ok := rt.TotalCommitted(after)
+	if !ok {
+		t.Fatalf("got no total committed, wanted one")
+	}
+	if want := (MoneyAmount{Currency: "EUR", Value: "900.00"}); total != want {
+		t.Errorf("got total committed %+v, wanted %+v", total, want)
+	}
+}
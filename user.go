@@ -17,10 +17,16 @@ package bosgo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,13 +35,9 @@ import (
 // concurrent use by multiple goroutines.
 type UserClient struct {
 	// never modified once they have been set
-	hc             *http.Client
-	addr           string
+	clientOptions
 	token          string // session token
 	applicationKey string
-	ua             string
-	environment    string
-	retryPolicy    RetryPolicy
 
 	UserID                string
 	Accesses              *AccessesService
@@ -47,13 +49,20 @@ type UserClient struct {
 	Transfers             *TransfersService
 	RecurringTransfers    *RecurringTransfersService
 	Consents              *ConsentsService
+	Beneficiaries         *BeneficiariesService
 }
 
-// NewUserClient creates a new user client, ready to use.
-func NewUserClient(client *http.Client, addr string, userID string, token string, applicationKey string) *UserClient {
+// NewUserClient creates a new user client, ready to use. The options are
+// interpreted in the same way as by New.
+func NewUserClient(client *http.Client, addr string, userID string, token string, applicationKey string, opts ...ClientOption) *UserClient {
+	c := New(client, addr, opts...)
+	return newUserClient(c.clientOptions, userID, token, applicationKey)
+}
+
+// newUserClient creates a UserClient that uses the supplied options.
+func newUserClient(opts clientOptions, userID string, token string, applicationKey string) *UserClient {
 	uc := &UserClient{
-		hc:             client,
-		addr:           addr,
+		clientOptions:  opts,
 		token:          token,
 		applicationKey: applicationKey,
 		UserID:         userID,
@@ -67,31 +76,16 @@ func NewUserClient(client *http.Client, addr string, userID string, token string
 	uc.Transfers = NewTransfersService(uc)
 	uc.RecurringTransfers = NewRecurringTransfersService(uc)
 	uc.Consents = NewConsentsService(uc)
+	uc.Beneficiaries = NewBeneficiariesService(uc)
 
 	return uc
 }
 
-func (u *UserClient) userAgent() string {
-	if u.ua == "" {
-		return DefaultUserAgent
-	}
-
-	return DefaultUserAgent + " " + u.ua
-}
 func (u *UserClient) newReq(path string) req {
-	return req{
-		hc:   u.hc,
-		addr: u.addr,
-		path: path,
-		headers: headers{
-			"User-Agent":        u.userAgent(),
-			"x-token":           u.token,
-			"x-application-key": u.applicationKey,
-		},
-		par:         params{},
-		environment: u.environment,
-		retryPolicy: u.retryPolicy,
-	}
+	r := u.clientOptions.newReq(path)
+	r.headers["x-token"] = u.token
+	r.headers["x-application-key"] = u.applicationKey
+	return r
 }
 
 // SessionToken returns the current session token.
@@ -99,6 +93,20 @@ func (u *UserClient) SessionToken() string {
 	return u.token
 }
 
+// ApplicationKey returns the application key used by the client. It may be
+// stored with the user's ID and session token to restore the session later
+// with NewUserClient.
+func (u *UserClient) ApplicationKey() string {
+	return u.applicationKey
+}
+
+// App creates an AppClient for the application the user belongs to, copying
+// options set on the receiver. It may be used for application scoped requests,
+// such as provider searches, by code that holds only a user client.
+func (u *UserClient) App() *AppClient {
+	return newAppClient(u.clientOptions, u.applicationKey)
+}
+
 // Logout returns a request that may be used to log a user out of the Bankrs
 // API. Once this request has been sent the user client is no longer valid and
 // should not be used.
@@ -135,6 +143,53 @@ func (r *UserLogoutReq) Send() error {
 	return nil
 }
 
+// ChangePassword prepares and returns a request to change the user's
+// password. Once the change succeeds all of the user's other sessions are
+// logged out; the client's own session remains valid.
+func (u *UserClient) ChangePassword(old, new string) *UserChangePasswordReq {
+	return &UserChangePasswordReq{
+		req: u.newReq(apiV1 + "/users/password"),
+		data: userChangePasswordData{
+			OldPassword: old,
+			NewPassword: new,
+		},
+	}
+}
+
+type userChangePasswordData struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type UserChangePasswordReq struct {
+	req
+	data userChangePasswordData
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *UserChangePasswordReq) Context(ctx context.Context) *UserChangePasswordReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *UserChangePasswordReq) ClientID(id string) *UserChangePasswordReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to change the user's password.
+func (r *UserChangePasswordReq) Send() error {
+	_, cleanup, err := r.req.postJSON(r.data)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // Delete returns a request that may be used to delete a user account and its
 // associated data. Once this request has been sent the user client is no
 // longer valid and should not be used.
@@ -148,6 +203,7 @@ func (u *UserClient) Delete(password string) *UserDeleteReq {
 type UserDeleteReq struct {
 	req
 	password string
+	mode     DeleteMode
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -157,12 +213,29 @@ func (r *UserDeleteReq) Context(ctx context.Context) *UserDeleteReq {
 	return r
 }
 
-// Send sends the request to delete a user.
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *UserDeleteReq) ClientID(id string) *UserDeleteReq {
+	r.req.clientID = id
+	return r
+}
+
+// Mode sets how the user is to be deleted. If no mode is set then the API
+// decides, which currently means the user is erased.
+func (r *UserDeleteReq) Mode(mode DeleteMode) *UserDeleteReq {
+	r.mode = mode
+	return r
+}
+
+// Send sends the request to delete a user. The returned DeletedUser reports
+// the mode of deletion that was carried out.
 func (r *UserDeleteReq) Send() (*DeletedUser, error) {
 	data := struct {
-		Password string `json:"password"`
+		Password string     `json:"password"`
+		Mode     DeleteMode `json:"mode,omitempty"`
 	}{
 		Password: r.password,
+		Mode:     r.mode,
 	}
 
 	res, cleanup, err := r.req.delete(&data)
@@ -210,6 +283,13 @@ func (r *ListAccessesReq) ClientID(id string) *ListAccessesReq {
 	return r
 }
 
+// IncludeAccounts requests that the accounts belonging to each access are
+// returned inline, avoiding the need to fetch each access individually.
+func (r *ListAccessesReq) IncludeAccounts() *ListAccessesReq {
+	r.req.par.Set("include_accounts", "true")
+	return r
+}
+
 func (r *ListAccessesReq) Send() (*AccessPage, error) {
 	res, cleanup, err := r.req.get()
 	defer cleanup()
@@ -221,20 +301,45 @@ func (r *ListAccessesReq) Send() (*AccessPage, error) {
 	if err := json.NewDecoder(res.Body).Decode(&page.Accesses); err != nil {
 		return nil, decodeError(err, res)
 	}
+	for i := range page.Accesses {
+		page.Accesses[i].clock = r.req.clock
+	}
 
 	return &page, nil
 }
 
+// SendInto decodes the response into v, a pointer to a slice of structs embedding Access.
+func (r *ListAccessesReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// Add prepares and returns a request to add an access to the provider with the
+// given id. Answers to the provider's challenges may be supplied one at a time
+// using ChallengeAnswer, which suits callers that collect them incrementally,
+// for example from a form. Use AddWithAnswers when all answers are already
+// known.
 func (a *AccessesService) Add(providerID string) *AddAccessReq {
 	return &AddAccessReq{
 		req:        a.client.newReq(apiV1 + "/accesses"),
+		client:     a.client,
 		providerID: providerID,
 		answers:    ChallengeAnswerList{},
 	}
 }
 
+// AddWithAnswers prepares and returns a request to add an access to the
+// provider with the given id, supplying the complete list of answers to the
+// provider's challenges up front. Further answers may still be added using
+// ChallengeAnswer.
+func (a *AccessesService) AddWithAnswers(providerID string, answers ChallengeAnswerList) *AddAccessReq {
+	r := a.Add(providerID)
+	r.answers = append(r.answers, answers...)
+	return r
+}
+
 type AddAccessReq struct {
 	req
+	client     *UserClient
 	providerID string
 	answers    ChallengeAnswerList
 }
@@ -279,9 +384,35 @@ func (r *AddAccessReq) Send() (*Job, error) {
 		return nil, decodeError(err, res)
 	}
 
+	job.Kind = JobKindCreate
 	return &job, nil
 }
 
+// ErrNoAccountsImported is returned by AddAccessReq.SendAndWait when the
+// access was added but the provider yielded no supported accounts.
+var ErrNoAccountsImported = errors.New("no accounts imported")
+
+// SendAndWait sends the request and then waits for the resulting job using
+// Jobs.Wait, returning its latest status. The status should be inspected to
+// determine whether the user must answer a challenge or grant consent. If the
+// access was imported without any accounts then the status is returned
+// together with ErrNoAccountsImported.
+func (r *AddAccessReq) SendAndWait(ctx context.Context) (*JobStatus, error) {
+	job, err := r.Context(ctx).Send()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := r.client.Jobs.Wait(ctx, job.URI, refreshPollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if status.Stage == JobStageImported && !status.Access.HasAccounts() {
+		return status, ErrNoAccountsImported
+	}
+	return status, nil
+}
+
 func (a *AccessesService) Delete(id int64) *DeleteAccessReq {
 	return &DeleteAccessReq{
 		req: a.client.newReq(apiV1 + "/accesses/" + strconv.FormatInt(id, 10)),
@@ -362,10 +493,16 @@ func (r *GetAccessReq) Send() (*Access, error) {
 	if err := json.NewDecoder(res.Body).Decode(&ba); err != nil {
 		return nil, decodeError(err, res)
 	}
+	ba.clock = r.req.clock
 
 	return &ba, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding Access.
+func (r *GetAccessReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 // Update prepares and returns a request to update the stored answers for a
 // bank access associated with the user.
 func (a *AccessesService) Update(id int64) *UpdateAccessReq {
@@ -417,6 +554,7 @@ func (r *UpdateAccessReq) Send() (*Access, error) {
 	if err := json.NewDecoder(res.Body).Decode(&ba); err != nil {
 		return nil, decodeError(err, res)
 	}
+	ba.clock = r.req.clock
 
 	return &ba, nil
 }
@@ -460,6 +598,7 @@ func (r *RefreshAccessReq) Send() (*Job, error) {
 		return nil, decodeError(err, res)
 	}
 
+	job.Kind = JobKindRefresh
 	return &job, nil
 }
 
@@ -467,12 +606,14 @@ func (r *RefreshAccessReq) Send() (*Job, error) {
 // accesses associated with the user. The request returns one job per access.
 func (a *AccessesService) RefreshAll() *RefreshAllAccessesReq {
 	return &RefreshAllAccessesReq{
-		req: a.client.newReq(apiV1 + "/accesses/refresh"),
+		req:    a.client.newReq(apiV1 + "/accesses/refresh"),
+		client: a.client,
 	}
 }
 
 type RefreshAllAccessesReq struct {
 	req
+	client *UserClient
 }
 
 // Context sets the context to be used during this request. If no context is supplied then
@@ -501,9 +642,152 @@ func (r *RefreshAllAccessesReq) Send() ([]Job, error) {
 		return nil, decodeError(err, res)
 	}
 
+	for i := range jobs {
+		jobs[i].Kind = JobKindRefresh
+	}
 	return jobs, nil
 }
 
+const (
+	// refreshPollInterval is the interval used by the SendAndWait methods when
+	// polling jobs.
+	refreshPollInterval = time.Second
+
+	// refreshMaxPolling is the maximum number of jobs SendAndWait will poll concurrently.
+	refreshMaxPolling = 4
+)
+
+// SendAndWait sends the request and then waits for each of the returned jobs
+// to finish, polling up to four jobs concurrently. It returns the final status
+// of each job in the order the jobs were returned by the API. If waiting for
+// any job fails then the statuses that could be obtained are returned together
+// with a *JobWaitError describing the failures.
+func (r *RefreshAllAccessesReq) SendAndWait(ctx context.Context) ([]JobStatus, error) {
+	statuses, _, _, err := r.sendAndWait(ctx)
+	return statuses, err
+}
+
+// sendAndWait implements SendAndWait. In addition it returns the status of
+// each job that was waited for successfully and the outcome of waiting for
+// each job, both keyed by job URI since the API may return the same job more
+// than once.
+func (r *RefreshAllAccessesReq) sendAndWait(ctx context.Context) ([]JobStatus, map[string]JobStatus, *BatchResult, error) {
+	jobs, err := r.Context(ctx).Send()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	statuses := make([]JobStatus, len(jobs))
+	errs := make([]error, len(jobs))
+
+	sem := make(chan struct{}, refreshMaxPolling)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			status, err := r.client.Jobs.Wait(ctx, jobs[i].URI, refreshPollInterval)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			statuses[i] = *status
+		}(i)
+	}
+	wg.Wait()
+
+	result := &BatchResult{}
+	byURI := make(map[string]JobStatus, len(jobs))
+	for i, job := range jobs {
+		result.add(job.URI, errs[i])
+		if errs[i] == nil {
+			byURI[job.URI] = statuses[i]
+		}
+	}
+	if !result.AllSucceeded() {
+		return statuses, byURI, result, &JobWaitError{Errors: result.Errors(), Result: result}
+	}
+
+	return statuses, byURI, result, nil
+}
+
+// WaitForAllReady refreshes all of the user's accesses and waits for each
+// refresh to complete, returning a summary of the outcome. If waiting for any
+// of the refresh jobs fails then the summary of the remaining jobs is returned
+// together with a *JobWaitError. If ctx is cancelled or its deadline expires
+// then the context's error is returned.
+func (a *AccessesService) WaitForAllReady(ctx context.Context) (*RefreshSummary, error) {
+	_, byURI, result, err := a.RefreshAll().sendAndWait(ctx)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	werr, partial := err.(*JobWaitError)
+	if err != nil && !partial {
+		return nil, err
+	}
+
+	summary := &RefreshSummary{}
+	for _, uri := range result.Keys() {
+		status, ok := byURI[uri]
+		if !ok || !result.Succeeded(uri) {
+			// waiting for this job failed, it is reported in werr
+			continue
+		}
+		switch {
+		case needsUserInput(&status):
+			summary.NeedsCredentials = append(summary.NeedsCredentials, status)
+		case status.Stage == JobStageImported && len(status.Errors) == 0:
+			summary.Succeeded = append(summary.Succeeded, status)
+		default:
+			summary.Failed = append(summary.Failed, status)
+		}
+	}
+
+	if werr != nil {
+		return summary, werr
+	}
+	return summary, nil
+}
+
+// RefreshSummary summarises the outcome of refreshing all of a user's accesses.
+type RefreshSummary struct {
+	// Succeeded holds the jobs that imported up to date data.
+	Succeeded []JobStatus
+
+	// NeedsCredentials holds the jobs that are waiting for the user to answer
+	// a challenge or grant consent.
+	NeedsCredentials []JobStatus
+
+	// Failed holds the jobs that ended with a problem or were cancelled.
+	Failed []JobStatus
+}
+
+// JobWaitError is returned when waiting for one or more jobs failed.
+type JobWaitError struct {
+	// Errors holds the error encountered for each failed job, indexed by job URI.
+	Errors map[string]error
+
+	// Result holds the outcome of waiting for every job, indexed by job URI.
+	Result *BatchResult
+}
+
+func (e *JobWaitError) Error() string {
+	uris := make([]string, 0, len(e.Errors))
+	for uri := range e.Errors {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	msgs := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		msgs = append(msgs, uri+": "+e.Errors[uri].Error())
+	}
+	return "waiting for jobs failed: " + strings.Join(msgs, "; ")
+}
+
 // JobsService provides access to jobs related API services.
 type JobsService struct {
 	client *UserClient
@@ -511,6 +795,60 @@ type JobsService struct {
 
 func NewJobsService(u *UserClient) *JobsService { return &JobsService{client: u} }
 
+// List returns a request that may be used to list the jobs of the user, such
+// as in-flight access creations or refreshes.
+func (j *JobsService) List() *ListJobsReq {
+	return &ListJobsReq{
+		req: j.client.newReq(apiV1 + "/jobs"),
+	}
+}
+
+type ListJobsReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ListJobsReq) Context(ctx context.Context) *ListJobsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListJobsReq) ClientID(id string) *ListJobsReq {
+	r.req.clientID = id
+	return r
+}
+
+// Finished restricts the list to jobs that have finished when finished is
+// true or to jobs that are still active when it is false.
+func (r *ListJobsReq) Finished(finished bool) *ListJobsReq {
+	r.req.par.Set("finished", strconv.FormatBool(finished))
+	return r
+}
+
+// Send sends the request to list jobs.
+func (r *ListJobsReq) Send() ([]JobStatus, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []JobStatus
+	if err := json.NewDecoder(res.Body).Decode(&jobs); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return jobs, nil
+}
+
+// SendInto decodes the response into v, a pointer to a slice of structs embedding JobStatus.
+func (r *ListJobsReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 // Get returns a request that may be used to get the details of a job.
 func (j *JobsService) Get(uri string) *JobGetReq {
 	return &JobGetReq{
@@ -552,6 +890,104 @@ func (r *JobGetReq) Send() (*JobStatus, error) {
 	return &status, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding JobStatus.
+func (r *JobGetReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// Wait polls the job identified by uri every interval until it has finished,
+// reached the problem or cancelled stage or requires input from the user in
+// the form of a challenge answer or consent, and returns its latest status. Wait returns the
+// context's error if ctx is cancelled or its deadline expires first.
+func (j *JobsService) Wait(ctx context.Context, uri string, interval time.Duration) (*JobStatus, error) {
+	for {
+		status, err := j.Get(uri).Context(ctx).Send()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		switch {
+		case isJobTerminal(status), needsUserInput(status):
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clockOrDefault(j.client.clock).After(interval):
+		}
+	}
+}
+
+// Watch polls the job identified by uri in the background and sends each
+// distinct status of the job on the returned channel. The channel is closed
+// once the job has finished or reached the problem or cancelled stage, when
+// polling the job fails or when the returned cancel function is called. The
+// cancel function stops polling and returns the error that caused polling to
+// fail, or nil if it did not. It should always be called to release resources
+// once the caller is no longer interested in the job.
+func (j *JobsService) Watch(uri string) (<-chan JobStatus, func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan JobStatus)
+	done := make(chan struct{})
+	var watchErr error
+
+	go func() {
+		defer close(done)
+		defer close(ch)
+
+		var last *JobStatus
+		for {
+			status, err := j.Get(uri).Context(ctx).Send()
+			if err != nil {
+				if ctx.Err() == nil {
+					watchErr = err
+				}
+				return
+			}
+
+			if last == nil || !reflect.DeepEqual(*last, *status) {
+				select {
+				case ch <- *status:
+				case <-ctx.Done():
+					return
+				}
+				last = status
+			}
+
+			if isJobTerminal(status) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-clockOrDefault(j.client.clock).After(refreshPollInterval):
+			}
+		}
+	}()
+
+	stop := func() error {
+		cancel()
+		<-done
+		return watchErr
+	}
+	return ch, stop
+}
+
+// isJobTerminal reports whether the job will not progress any further.
+func isJobTerminal(status *JobStatus) bool {
+	return status.Finished || status.Stage == JobStageProblem || status.Stage == JobStageCancelled
+}
+
+// needsUserInput reports whether the job cannot progress until the user
+// answers a challenge or grants consent.
+func needsUserInput(status *JobStatus) bool {
+	return status.Stage == JobStageChallenge || status.Stage == JobStageConsent
+}
+
 // Answer returns a request that may be used to answer a challenge needed by a job
 func (j *JobsService) Answer(uri string) *JobAnswerReq {
 	return &JobAnswerReq{
@@ -585,21 +1021,32 @@ func (r *JobAnswerReq) ChallengeAnswer(answer ChallengeAnswer) *JobAnswerReq {
 	return r
 }
 
-// Send sends the request to get answer a challenge needed by a job.
-func (r *JobAnswerReq) Send() error {
+// Send sends the request to answer a challenge needed by a job. It returns the
+// status of the job after the answers have been applied, including any
+// challenges that remain to be answered. The status is nil if the API did not
+// include one in its response.
+func (r *JobAnswerReq) Send() (*JobStatus, error) {
 	data := struct {
 		Answers ChallengeAnswerList `json:"challenge_answers"`
 	}{
 		Answers: r.answers,
 	}
 
-	_, cleanup, err := r.req.putJSON(&data)
+	res, cleanup, err := r.req.putJSON(&data)
 	defer cleanup()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var status JobStatus
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, decodeError(err, res)
+	}
+
+	return &status, nil
 }
 
 // Cancel returns a request that may be used to cancel a job.
@@ -682,6 +1129,11 @@ func (r *ListAccountsReq) Send() (*AccountPage, error) {
 	return &page, nil
 }
 
+// SendInto decodes the response into v, a pointer to a slice of structs embedding Account.
+func (r *ListAccountsReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 type AccountPage struct {
 	Accounts []Account
 }
@@ -723,173 +1175,460 @@ func (r *GetAccountReq) Send() (*Account, error) {
 	return &account, nil
 }
 
-// TransactionsService provides access to transaction related API services.
-type TransactionsService struct {
-	client *UserClient
-}
-
-func NewTransactionsService(u *UserClient) *TransactionsService {
-	return &TransactionsService{client: u}
+// SendInto decodes the response into v, a pointer to a struct embedding Account.
+func (r *GetAccountReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
 }
 
-func (a *TransactionsService) List() *ListTransactionsReq {
-	return &ListTransactionsReq{
-		req: a.client.newReq(apiV1 + "/transactions"),
+// Provider prepares and returns a request to look up the financial provider
+// that holds an account, for example to display the bank's name alongside it.
+func (a *AccountsService) Provider(accountID int64) *AccountProviderReq {
+	return &AccountProviderReq{
+		req:    a.client.newReq(apiV1 + "/accounts/" + strconv.FormatInt(accountID, 10)),
+		client: a.client,
 	}
 }
 
-type ListTransactionsReq struct {
+type AccountProviderReq struct {
 	req
+	client *UserClient
 }
 
-func (r *ListTransactionsReq) Context(ctx context.Context) *ListTransactionsReq {
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *AccountProviderReq) Context(ctx context.Context) *AccountProviderReq {
 	r.req.ctx = ctx
 	return r
 }
 
 // ClientID sets a client identifier that will be passed to the Bankrs API in
 // the X-Client-Id header.
-func (r *ListTransactionsReq) ClientID(id string) *ListTransactionsReq {
+func (r *AccountProviderReq) ClientID(id string) *AccountProviderReq {
 	r.req.clientID = id
 	return r
 }
 
-func (r *ListTransactionsReq) AccountID(id int64) *ListTransactionsReq {
-	r.req.par["account_id"] = []string{strconv.FormatInt(id, 10)}
-	return r
-}
+// Send fetches the account and then its provider. If the account's provider
+// is not known to the API then a Provider holding only the provider ID is
+// returned so that callers can still display something for the account.
+func (r *AccountProviderReq) Send() (*Provider, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
 
-func (r *ListTransactionsReq) AccessID(id int64) *ListTransactionsReq {
-	r.req.par["access_id"] = []string{strconv.FormatInt(id, 10)}
-	return r
-}
+	var account Account
+	if err := json.NewDecoder(res.Body).Decode(&account); err != nil {
+		return nil, decodeError(err, res)
+	}
 
-func (r *ListTransactionsReq) Since(t time.Time) *ListTransactionsReq {
-	r.req.par["since"] = []string{t.Format(time.RFC3339)}
-	return r
+	p, err := r.client.accountProvider(&r.req, account)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return &Provider{ID: account.ProviderID}, nil
+	}
+	return p, nil
 }
 
-func (r *ListTransactionsReq) Limit(limit int) *ListTransactionsReq {
-	r.req.par["limit"] = []string{fmt.Sprintf("%d", limit)}
-	return r
-}
+// accountProvider fetches the provider of account, using the context and
+// client ID of parent. It returns nil without an error if the account has no
+// provider ID or its provider is not known to the API.
+func (u *UserClient) accountProvider(parent *req, account Account) (*Provider, error) {
+	if account.ProviderID == "" {
+		return nil, nil
+	}
 
-func (r *ListTransactionsReq) Offset(offset int) *ListTransactionsReq {
-	r.req.par["offset"] = []string{fmt.Sprintf("%d", offset)}
-	return r
-}
+	preq := u.newReq(apiV1 + "/providers/" + url.PathEscape(account.ProviderID))
+	preq.ctx = parent.ctx
+	preq.clientID = parent.clientID
 
-func (r *ListTransactionsReq) Send() (*TransactionPage, error) {
-	res, cleanup, err := r.req.get()
+	res, cleanup, err := preq.get()
 	defer cleanup()
 	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	var page TransactionPage
-	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+	var p Provider
+	if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
 		return nil, decodeError(err, res)
 	}
+	return &p, nil
+}
 
-	return &page, nil
+// TransferContext holds the details needed to initiate a transfer from an
+// account.
+type TransferContext struct {
+	// Account is the account the transfer will be made from. Its Capabilities
+	// list the transfer operations the account supports.
+	Account Account
+
+	// AccessCapabilities describes the recurring and scheduled transfers
+	// supported by the access holding the account.
+	AccessCapabilities AccessCapabilities
+
+	// AuthMethods lists the methods advertised by the account's provider for
+	// authorising transfers. It is empty if the provider does not advertise
+	// them, in which case they are offered once the transfer has been
+	// created.
+	AuthMethods []AuthMethod
 }
 
-func (a *TransactionsService) Get(id string) *GetTransactionReq {
-	return &GetTransactionReq{
-		req: a.client.newReq(apiV1 + "/transactions/" + url.PathEscape(id)),
+// TransferContext prepares and returns a request to fetch the details needed
+// to initiate a transfer from the account with the given id, such as when
+// displaying a transfer form.
+func (a *AccountsService) TransferContext(id int64) *TransferContextReq {
+	return &TransferContextReq{
+		req:    a.client.newReq(apiV1 + "/accounts/" + strconv.FormatInt(id, 10)),
+		client: a.client,
 	}
 }
 
-type GetTransactionReq struct {
+type TransferContextReq struct {
 	req
+	client *UserClient
 }
 
-func (r *GetTransactionReq) Context(ctx context.Context) *GetTransactionReq {
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *TransferContextReq) Context(ctx context.Context) *TransferContextReq {
 	r.req.ctx = ctx
 	return r
 }
 
 // ClientID sets a client identifier that will be passed to the Bankrs API in
 // the X-Client-Id header.
-func (r *GetTransactionReq) ClientID(id string) *GetTransactionReq {
+func (r *TransferContextReq) ClientID(id string) *TransferContextReq {
 	r.req.clientID = id
 	return r
 }
 
-func (r *GetTransactionReq) Send() (*Transaction, error) {
+// Send fetches the account followed by its access and provider. If the
+// account has no provider or its provider is not known to the API then no
+// auth methods are returned.
+func (r *TransferContextReq) Send() (*TransferContext, error) {
 	res, cleanup, err := r.req.get()
 	defer cleanup()
 	if err != nil {
 		return nil, err
 	}
 
-	var tx Transaction
-	if err := json.NewDecoder(res.Body).Decode(&tx); err != nil {
+	var tc TransferContext
+	if err := json.NewDecoder(res.Body).Decode(&tc.Account); err != nil {
 		return nil, decodeError(err, res)
 	}
 
-	return &tx, nil
-}
+	areq := r.client.newReq(apiV1 + "/accesses/" + strconv.FormatInt(tc.Account.BankAccessID, 10))
+	areq.ctx = r.req.ctx
+	areq.clientID = r.req.clientID
 
-// ScheduledTransactionsService provides access to scheduled transaction related API services.
-type ScheduledTransactionsService struct {
-	client *UserClient
-}
+	ares, acleanup, err := areq.get()
+	defer acleanup()
+	if err != nil {
+		return nil, err
+	}
 
-func NewScheduledTransactionsService(u *UserClient) *ScheduledTransactionsService {
-	return &ScheduledTransactionsService{client: u}
+	var access Access
+	if err := json.NewDecoder(ares.Body).Decode(&access); err != nil {
+		return nil, decodeError(err, ares)
+	}
+	tc.AccessCapabilities = access.Capabilities
+
+	p, err := r.client.accountProvider(&r.req, tc.Account)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		tc.AuthMethods = p.authMethods()
+	}
+
+	return &tc, nil
 }
 
-func (a *ScheduledTransactionsService) List() *ListScheduledTransactionsReq {
-	return &ListScheduledTransactionsReq{
-		req: a.client.newReq(apiV1 + "/scheduled_transactions"),
+// Statement prepares and returns a request to download the official
+// statement document for an account. Statements are returned as PDF.
+func (a *AccountsService) Statement(id int64) *AccountStatementReq {
+	return &AccountStatementReq{
+		req: a.client.newReq(apiV1 + "/accounts/" + strconv.FormatInt(id, 10) + "/statement"),
 	}
 }
 
-type ListScheduledTransactionsReq struct {
+type AccountStatementReq struct {
 	req
 }
 
-func (r *ListScheduledTransactionsReq) Context(ctx context.Context) *ListScheduledTransactionsReq {
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *AccountStatementReq) Context(ctx context.Context) *AccountStatementReq {
 	r.req.ctx = ctx
 	return r
 }
 
 // ClientID sets a client identifier that will be passed to the Bankrs API in
 // the X-Client-Id header.
-func (r *ListScheduledTransactionsReq) ClientID(id string) *ListScheduledTransactionsReq {
+func (r *AccountStatementReq) ClientID(id string) *AccountStatementReq {
 	r.req.clientID = id
 	return r
 }
 
-func (r *ListScheduledTransactionsReq) AccountID(id int64) *ListScheduledTransactionsReq {
-	r.req.par["account_id"] = []string{strconv.FormatInt(id, 10)}
+// From sets the start of the period covered by the statement.
+func (r *AccountStatementReq) From(t time.Time) *AccountStatementReq {
+	r.req.par["from"] = []string{t.Format(time.RFC3339)}
 	return r
 }
 
-func (r *ListScheduledTransactionsReq) AccessID(id int64) *ListScheduledTransactionsReq {
-	r.req.par["access_id"] = []string{strconv.FormatInt(id, 10)}
+// To sets the end of the period covered by the statement.
+func (r *AccountStatementReq) To(t time.Time) *AccountStatementReq {
+	r.req.par["to"] = []string{t.Format(time.RFC3339)}
 	return r
 }
 
-func (r *ListScheduledTransactionsReq) Send() ([]Transaction, error) {
-	res, cleanup, err := r.req.get()
-	defer cleanup()
+// Send sends the request and streams the statement document to w. It returns
+// the content type of the document as reported by the API.
+func (r *AccountStatementReq) Send(w io.Writer) (string, error) {
+	body, contentType, err := r.req.getRaw("application/pdf")
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	defer body.Close()
 
-	var txs []Transaction
-	if err := json.NewDecoder(res.Body).Decode(&txs); err != nil {
-		return nil, decodeError(err, res)
+	if _, err := io.Copy(w, body); err != nil {
+		return "", err
 	}
 
-	return txs, nil
+	return contentType, nil
 }
 
-func (a *ScheduledTransactionsService) Get(id string) *GetScheduledTransactionReq {
-	return &GetScheduledTransactionReq{
-		req: a.client.newReq(apiV1 + "/scheduled_transactions/" + url.PathEscape(id)),
+// TransactionsService provides access to transaction related API services.
+type TransactionsService struct {
+	client *UserClient
+}
+
+func NewTransactionsService(u *UserClient) *TransactionsService {
+	return &TransactionsService{client: u}
+}
+
+func (a *TransactionsService) List() *ListTransactionsReq {
+	return &ListTransactionsReq{
+		req: a.client.newReq(apiV1 + "/transactions"),
+	}
+}
+
+type ListTransactionsReq struct {
+	req
+}
+
+func (r *ListTransactionsReq) Context(ctx context.Context) *ListTransactionsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListTransactionsReq) ClientID(id string) *ListTransactionsReq {
+	r.req.clientID = id
+	return r
+}
+
+func (r *ListTransactionsReq) AccountID(id int64) *ListTransactionsReq {
+	r.req.par["account_id"] = []string{strconv.FormatInt(id, 10)}
+	return r
+}
+
+func (r *ListTransactionsReq) AccessID(id int64) *ListTransactionsReq {
+	r.req.par["access_id"] = []string{strconv.FormatInt(id, 10)}
+	return r
+}
+
+func (r *ListTransactionsReq) Since(t time.Time) *ListTransactionsReq {
+	r.req.par["since"] = []string{t.Format(time.RFC3339)}
+	return r
+}
+
+// Until restricts the list to transactions entered at or before t.
+func (r *ListTransactionsReq) Until(t time.Time) *ListTransactionsReq {
+	r.req.par["until"] = []string{t.Format(time.RFC3339)}
+	return r
+}
+
+// MinAmount restricts the list to transactions whose amount, disregarding its
+// sign, is at least value. The value is a decimal such as "50.00", so that
+// both payments and receipts of at least that size are included.
+func (r *ListTransactionsReq) MinAmount(value string) *ListTransactionsReq {
+	r.req.par["min_amount"] = []string{value}
+	return r
+}
+
+// CategoryID restricts the list to transactions in the category with the
+// given id.
+func (r *ListTransactionsReq) CategoryID(id int64) *ListTransactionsReq {
+	r.req.par["category_id"] = []string{strconv.FormatInt(id, 10)}
+	return r
+}
+
+// SortBy sets the order in which transactions are listed. Paging applies to
+// the sorted list.
+func (r *ListTransactionsReq) SortBy(order TransactionOrder) *ListTransactionsReq {
+	r.req.par["sort"] = []string{string(order)}
+	return r
+}
+
+func (r *ListTransactionsReq) Limit(limit int) *ListTransactionsReq {
+	r.req.par["limit"] = []string{fmt.Sprintf("%d", limit)}
+	return r
+}
+
+func (r *ListTransactionsReq) Offset(offset int) *ListTransactionsReq {
+	r.req.par["offset"] = []string{fmt.Sprintf("%d", offset)}
+	return r
+}
+
+func (r *ListTransactionsReq) Send() (*TransactionPage, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var page TransactionPage
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &page, nil
+}
+
+// SendInto decodes the response into v, a pointer to a struct embedding TransactionPage.
+func (r *ListTransactionsReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+func (a *TransactionsService) Get(id string) *GetTransactionReq {
+	return &GetTransactionReq{
+		req: a.client.newReq(apiV1 + "/transactions/" + url.PathEscape(id)),
+	}
+}
+
+type GetTransactionReq struct {
+	req
+}
+
+func (r *GetTransactionReq) Context(ctx context.Context) *GetTransactionReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *GetTransactionReq) ClientID(id string) *GetTransactionReq {
+	r.req.clientID = id
+	return r
+}
+
+func (r *GetTransactionReq) Send() (*Transaction, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var tx Transaction
+	if err := json.NewDecoder(res.Body).Decode(&tx); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &tx, nil
+}
+
+// SendInto decodes the response into v, a pointer to a struct embedding Transaction.
+func (r *GetTransactionReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// ScheduledTransactionsService provides access to scheduled transaction related API services.
+type ScheduledTransactionsService struct {
+	client *UserClient
+}
+
+func NewScheduledTransactionsService(u *UserClient) *ScheduledTransactionsService {
+	return &ScheduledTransactionsService{client: u}
+}
+
+func (a *ScheduledTransactionsService) List() *ListScheduledTransactionsReq {
+	return &ListScheduledTransactionsReq{
+		req: a.client.newReq(apiV1 + "/scheduled_transactions"),
+	}
+}
+
+type ListScheduledTransactionsReq struct {
+	req
+}
+
+func (r *ListScheduledTransactionsReq) Context(ctx context.Context) *ListScheduledTransactionsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListScheduledTransactionsReq) ClientID(id string) *ListScheduledTransactionsReq {
+	r.req.clientID = id
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) AccountID(id int64) *ListScheduledTransactionsReq {
+	r.req.par["account_id"] = []string{strconv.FormatInt(id, 10)}
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) AccessID(id int64) *ListScheduledTransactionsReq {
+	r.req.par["access_id"] = []string{strconv.FormatInt(id, 10)}
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) Since(t time.Time) *ListScheduledTransactionsReq {
+	r.req.par["since"] = []string{t.Format(time.RFC3339)}
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) Limit(limit int) *ListScheduledTransactionsReq {
+	r.req.par["limit"] = []string{fmt.Sprintf("%d", limit)}
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) Offset(offset int) *ListScheduledTransactionsReq {
+	r.req.par["offset"] = []string{fmt.Sprintf("%d", offset)}
+	return r
+}
+
+func (r *ListScheduledTransactionsReq) Send() ([]Transaction, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []Transaction
+	if err := json.NewDecoder(res.Body).Decode(&txs); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return txs, nil
+}
+
+// SendInto decodes the response into v, a pointer to a slice of structs embedding Transaction.
+func (r *ListScheduledTransactionsReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+func (a *ScheduledTransactionsService) Get(id string) *GetScheduledTransactionReq {
+	return &GetScheduledTransactionReq{
+		req: a.client.newReq(apiV1 + "/scheduled_transactions/" + url.PathEscape(id)),
 	}
 }
 
@@ -924,6 +1663,11 @@ func (r *GetScheduledTransactionReq) Send() (*Transaction, error) {
 	return &tx, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding Transaction.
+func (r *GetScheduledTransactionReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 // RepeatedTransactionsService provides access to repeated transaction related API services.
 type RepeatedTransactionsService struct {
 	client *UserClient
@@ -990,6 +1734,11 @@ func (r *ListRepeatedTransactionsReq) Send() (*RepeatedTransactionPage, error) {
 	return &page, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding RepeatedTransactionPage.
+func (r *ListRepeatedTransactionsReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
 func (r *RepeatedTransactionsService) Get(id string) *GetRepeatedTransactionReq {
 	return &GetRepeatedTransactionReq{
 		req: r.client.newReq(apiV1 + "/repeated_transactions/" + url.PathEscape(id)),
@@ -1027,6 +1776,61 @@ func (r *GetRepeatedTransactionReq) Send() (*RepeatedTransaction, error) {
 	return &tx, nil
 }
 
+// SendInto decodes the response into v, a pointer to a struct embedding RepeatedTransaction.
+func (r *GetRepeatedTransactionReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// SkipNext returns a request that may be used to skip the next occurrence of a
+// repeated transaction while keeping the standing order active.
+func (r *RepeatedTransactionsService) SkipNext(id string) *SkipRepeatedTransactionReq {
+	return &SkipRepeatedTransactionReq{
+		req: r.client.newReq(apiV1 + "/repeated_transactions/" + url.PathEscape(id) + "/skip"),
+	}
+}
+
+type SkipRepeatedTransactionReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *SkipRepeatedTransactionReq) Context(ctx context.Context) *SkipRepeatedTransactionReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *SkipRepeatedTransactionReq) ClientID(id string) *SkipRepeatedTransactionReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request and returns the updated repeated transaction, whose
+// schedule starts at the occurrence after the skipped one.
+func (r *SkipRepeatedTransactionReq) Send() (*RepeatedTransaction, error) {
+	res, cleanup, err := r.req.postJSON(nil)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var tx RepeatedTransaction
+	if err := json.NewDecoder(res.Body).Decode(&tx); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &tx, nil
+}
+
+// Create returns a request that may be used to create a new repeated
+// transaction, also known as a standing order. The returned recurring transfer
+// is progressed using RecurringTransfersService.Process.
+func (r *RepeatedTransactionsService) Create(from int64, to TransferAddress, amount MoneyAmount, rule RecurrenceRule, usage string) *CreateRecurringTransferReq {
+	return r.client.RecurringTransfers.Create(from, to, amount, rule, usage)
+}
+
 // Delete returns a request that may be used to delete a repeated transaction.
 func (r *RepeatedTransactionsService) Delete(id string) *DeleteRepeatedTransactionReq {
 	return &DeleteRepeatedTransactionReq{
@@ -1090,7 +1894,7 @@ func (r *RepeatedTransactionsService) Update(id string, to TransferAddress, amou
 	return &UpdateRepeatedTransactionReq{
 		req: r.client.newReq(apiV1 + "/repeated_transactions/" + url.PathEscape(id)),
 		data: transferParams{
-			To:     to,
+			To:     to.normalized(),
 			Amount: amount,
 			Type:   TransferTypeRecurring,
 			Usage:  usage,
@@ -1191,7 +1995,7 @@ func (t *TransfersService) Create(from int64, to TransferAddress, amount MoneyAm
 		req: t.client.newReq(apiV1 + "/transfers"),
 		data: transferParams{
 			From:   from,
-			To:     to,
+			To:     to.normalized(),
 			Amount: amount,
 			Type:   TransferTypeRegular,
 		},
@@ -1235,6 +2039,14 @@ func (r *CreateTransferReq) ChallengeAnswer(answer ChallengeAnswer) *CreateTrans
 	return r
 }
 
+// StoredChallengeAnswer adds an answer to one of the authorisation challenges
+// and asks for it to be stored, so that later transfers from accounts at the
+// same provider may skip the step that asks for it. It is typically used for
+// the PIN.
+func (r *CreateTransferReq) StoredChallengeAnswer(id, value string) *CreateTransferReq {
+	return r.ChallengeAnswer(ChallengeAnswer{ID: id, Value: value, Store: true})
+}
+
 // Send sends the request to create a money transfer.
 func (r *CreateTransferReq) Send() (*Transfer, error) {
 	res, cleanup, err := r.req.postJSON(r.data)
@@ -1283,6 +2095,7 @@ func (r *ProcessTransferReq) ClientID(id string) *ProcessTransferReq {
 }
 
 // Confirm sets whether the user has confirmed a transfer that appears to be similar to another that was recently sent.
+// It must be set to true to proceed when the transfer's step intent is TransferIntentConfirmSimilarTransfer.
 func (r *ProcessTransferReq) Confirm(confirm bool) *ProcessTransferReq {
 	r.data.Confirm = confirm
 	return r
@@ -1294,6 +2107,14 @@ func (r *ProcessTransferReq) ChallengeAnswer(answer ChallengeAnswer) *ProcessTra
 	return r
 }
 
+// StoredChallengeAnswer adds an answer to one of the authorisation challenges
+// and asks for it to be stored, so that later transfers from accounts at the
+// same provider may skip the step that asks for it. It is typically used for
+// the PIN.
+func (r *ProcessTransferReq) StoredChallengeAnswer(id, value string) *ProcessTransferReq {
+	return r.ChallengeAnswer(ChallengeAnswer{ID: id, Value: value, Store: true})
+}
+
 // Send sends the request to update information and answer challenges for a transfer.
 func (r *ProcessTransferReq) Send() (*Transfer, error) {
 	res, cleanup, err := r.req.postJSON(&r.data)
@@ -1361,17 +2182,306 @@ func (r *CancelTransferReq) Send() (*Transfer, error) {
 	return &tr, nil
 }
 
-// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
-//           RECURRING TRANSFERS SERVICE
-// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+// Update returns a request that may be used to change the recipient, amount or
+// description of a money transfer before it has been completed. The version
+// must match the current version of the transfer.
+func (t *TransfersService) Update(id string, version int) *UpdateTransferReq {
+	return &UpdateTransferReq{
+		req: t.client.newReq(apiV1 + "/transfers/" + url.PathEscape(id)),
+		data: transferUpdateParams{
+			Version: version,
+			Type:    TransferTypeRegular,
+		},
+	}
+}
 
-// RecurringTransfersService provides access to recurring money transfer related API services.
-type RecurringTransfersService struct {
-	client *UserClient
+type transferUpdateParams struct {
+	Version int              `json:"version,omitempty"`
+	Type    TransferType     `json:"type"`
+	To      *TransferAddress `json:"to,omitempty"`
+	Amount  *MoneyAmount     `json:"amount,omitempty"`
+	Usage   *string          `json:"usage,omitempty"`
 }
 
-func NewRecurringTransfersService(u *UserClient) *RecurringTransfersService {
-	return &RecurringTransfersService{client: u}
+type UpdateTransferReq struct {
+	req
+	data transferUpdateParams
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *UpdateTransferReq) Context(ctx context.Context) *UpdateTransferReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *UpdateTransferReq) ClientID(id string) *UpdateTransferReq {
+	r.req.clientID = id
+	return r
+}
+
+// To sets a new recipient for the transfer.
+func (r *UpdateTransferReq) To(to TransferAddress) *UpdateTransferReq {
+	to = to.normalized()
+	r.data.To = &to
+	return r
+}
+
+// Amount sets a new amount for the transfer.
+func (r *UpdateTransferReq) Amount(amount MoneyAmount) *UpdateTransferReq {
+	r.data.Amount = &amount
+	return r
+}
+
+// Description sets a new human readable description for the transfer.
+func (r *UpdateTransferReq) Description(s string) *UpdateTransferReq {
+	r.data.Usage = &s
+	return r
+}
+
+// Send sends the request to update a money transfer.
+func (r *UpdateTransferReq) Send() (*Transfer, error) {
+	res, cleanup, err := r.req.putJSON(&r.data)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var tr Transfer
+	if err := json.NewDecoder(res.Body).Decode(&tr); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &tr, nil
+}
+
+// Delete returns a request that may be used to delete a money transfer that
+// has not yet been completed. Transfers that have already succeeded cannot be
+// deleted.
+func (t *TransfersService) Delete(id string) *DeleteTransferReq {
+	return &DeleteTransferReq{
+		req: t.client.newReq(apiV1 + "/transfers/" + url.PathEscape(id)),
+	}
+}
+
+type DeleteTransferReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *DeleteTransferReq) Context(ctx context.Context) *DeleteTransferReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *DeleteTransferReq) ClientID(id string) *DeleteTransferReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to delete a money transfer. It returns the transfer
+// in its cancelled state.
+func (r *DeleteTransferReq) Send() (*Transfer, error) {
+	data := struct {
+		Type string `json:"type"`
+	}{
+		Type: string(TransferTypeRegular),
+	}
+
+	res, cleanup, err := r.req.deleteJSON(&data)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var tr Transfer
+	if err := json.NewDecoder(res.Body).Decode(&tr); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &tr, nil
+}
+
+// Get returns a request that may be used to fetch the current state and step
+// of a money transfer, for example to resume processing it after the client
+// has been restarted.
+func (t *TransfersService) Get(id string) *GetTransferReq {
+	return &GetTransferReq{
+		req: t.client.newReq(apiV1 + "/transfers/" + url.PathEscape(id)),
+	}
+}
+
+type GetTransferReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *GetTransferReq) Context(ctx context.Context) *GetTransferReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *GetTransferReq) ClientID(id string) *GetTransferReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to get the money transfer.
+func (r *GetTransferReq) Send() (*Transfer, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var tr Transfer
+	if err := json.NewDecoder(res.Body).Decode(&tr); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &tr, nil
+}
+
+// SendInto decodes the response into v, a pointer to a struct embedding Transfer.
+func (r *GetTransferReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// List returns a request that may be used to list the user's money transfers,
+// including those that have completed. Transfers are listed in the order they
+// were created.
+func (t *TransfersService) List() *ListTransfersReq {
+	return &ListTransfersReq{
+		req: t.client.newReq(apiV1 + "/transfers"),
+	}
+}
+
+type ListTransfersReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ListTransfersReq) Context(ctx context.Context) *ListTransfersReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListTransfersReq) ClientID(id string) *ListTransfersReq {
+	r.req.clientID = id
+	return r
+}
+
+// State limits the list to transfers in the given state.
+func (r *ListTransfersReq) State(state TransferState) *ListTransfersReq {
+	r.req.par.Set("state", string(state))
+	return r
+}
+
+// Send sends the request to list money transfers.
+func (r *ListTransfersReq) Send() (*TransferPage, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var page TransferPage
+	if err := json.NewDecoder(res.Body).Decode(&page.Transfers); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &page, nil
+}
+
+// SendInto decodes the response into v, a pointer to a slice of structs embedding Transfer.
+func (r *ListTransfersReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// ResultingTransactions returns a request that may be used to find the
+// transactions booked as a result of a succeeded transfer. Since the API does
+// not link transactions to the transfers that created them, the transactions of
+// the transfer's source account are matched using Transaction.MatchesTransfer.
+func (t *TransfersService) ResultingTransactions(transfer *Transfer) *ResultingTransactionsReq {
+	r := t.client.newReq(apiV1 + "/transactions")
+	if transfer.From.AccountID != 0 {
+		r.par.Set("account_id", strconv.FormatInt(transfer.From.AccountID, 10))
+	}
+	if !transfer.EntryDate.IsZero() {
+		r.par.Set("since", transfer.EntryDate.Truncate(24*time.Hour).Format(time.RFC3339))
+	}
+
+	return &ResultingTransactionsReq{
+		req:      r,
+		transfer: transfer,
+	}
+}
+
+type ResultingTransactionsReq struct {
+	req
+	transfer *Transfer
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ResultingTransactionsReq) Context(ctx context.Context) *ResultingTransactionsReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ResultingTransactionsReq) ClientID(id string) *ResultingTransactionsReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request and returns the transactions that match the transfer.
+// The result is empty if the transfer has not been booked yet.
+func (r *ResultingTransactionsReq) Send() ([]Transaction, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var page TransactionPage
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	var txs []Transaction
+	for _, tx := range page.Transactions {
+		if tx.MatchesTransfer(r.transfer) {
+			txs = append(txs, tx)
+		}
+	}
+
+	return txs, nil
+}
+
+// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+//           RECURRING TRANSFERS SERVICE
+// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// RecurringTransfersService provides access to recurring money transfer related API services.
+type RecurringTransfersService struct {
+	client *UserClient
+}
+
+func NewRecurringTransfersService(u *UserClient) *RecurringTransfersService {
+	return &RecurringTransfersService{client: u}
 }
 
 // Create returns a request that may be used to create a money transfer. from is an account id belonging to the user.
@@ -1380,7 +2490,7 @@ func (t *RecurringTransfersService) Create(from int64, to TransferAddress, amoun
 		req: t.client.newReq(apiV1 + "/transfers"),
 		data: transferParams{
 			From:     from,
-			To:       to,
+			To:       to.normalized(),
 			Amount:   amount,
 			Type:     TransferTypeRecurring,
 			Schedule: &rule,
@@ -1599,3 +2709,184 @@ func (r *ConsentGetReq) Send() (*Consent, error) {
 
 	return &cons, nil
 }
+
+// SendInto decodes the response into v, a pointer to a struct embedding Consent.
+func (r *ConsentGetReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// BeneficiariesService provides access to beneficiary related API services.
+type BeneficiariesService struct {
+	client *UserClient
+}
+
+func NewBeneficiariesService(u *UserClient) *BeneficiariesService {
+	return &BeneficiariesService{client: u}
+}
+
+// List returns a request that may be used to list the beneficiaries saved by the user.
+func (b *BeneficiariesService) List() *ListBeneficiariesReq {
+	return &ListBeneficiariesReq{
+		req: b.client.newReq(apiV1 + "/beneficiaries"),
+	}
+}
+
+type ListBeneficiariesReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *ListBeneficiariesReq) Context(ctx context.Context) *ListBeneficiariesReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *ListBeneficiariesReq) ClientID(id string) *ListBeneficiariesReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to list beneficiaries.
+func (r *ListBeneficiariesReq) Send() ([]Beneficiary, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Beneficiary
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return list, nil
+}
+
+// SendInto decodes the response into v, a pointer to a slice of structs embedding Beneficiary.
+func (r *ListBeneficiariesReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// Get returns a request that may be used to get the details of a beneficiary.
+func (b *BeneficiariesService) Get(id int64) *GetBeneficiaryReq {
+	return &GetBeneficiaryReq{
+		req: b.client.newReq(apiV1 + "/beneficiaries/" + strconv.FormatInt(id, 10)),
+	}
+}
+
+type GetBeneficiaryReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *GetBeneficiaryReq) Context(ctx context.Context) *GetBeneficiaryReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *GetBeneficiaryReq) ClientID(id string) *GetBeneficiaryReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to get details of a beneficiary.
+func (r *GetBeneficiaryReq) Send() (*Beneficiary, error) {
+	res, cleanup, err := r.req.get()
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var b Beneficiary
+	if err := json.NewDecoder(res.Body).Decode(&b); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &b, nil
+}
+
+// SendInto decodes the response into v, a pointer to a struct embedding Beneficiary.
+func (r *GetBeneficiaryReq) SendInto(v interface{}) error {
+	return r.req.getInto(v)
+}
+
+// Create returns a request that may be used to save a new beneficiary for the user.
+func (b *BeneficiariesService) Create(addr TransferAddress) *CreateBeneficiaryReq {
+	return &CreateBeneficiaryReq{
+		req:  b.client.newReq(apiV1 + "/beneficiaries"),
+		addr: addr.normalized(),
+	}
+}
+
+type CreateBeneficiaryReq struct {
+	req
+	addr TransferAddress
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *CreateBeneficiaryReq) Context(ctx context.Context) *CreateBeneficiaryReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *CreateBeneficiaryReq) ClientID(id string) *CreateBeneficiaryReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to create a beneficiary.
+func (r *CreateBeneficiaryReq) Send() (*Beneficiary, error) {
+	res, cleanup, err := r.req.postJSON(&r.addr)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	var b Beneficiary
+	if err := json.NewDecoder(res.Body).Decode(&b); err != nil {
+		return nil, decodeError(err, res)
+	}
+
+	return &b, nil
+}
+
+// Delete returns a request that may be used to delete a beneficiary.
+func (b *BeneficiariesService) Delete(id int64) *DeleteBeneficiaryReq {
+	return &DeleteBeneficiaryReq{
+		req: b.client.newReq(apiV1 + "/beneficiaries/" + strconv.FormatInt(id, 10)),
+	}
+}
+
+type DeleteBeneficiaryReq struct {
+	req
+}
+
+// Context sets the context to be used during this request. If no context is supplied then
+// the request will use context.Background.
+func (r *DeleteBeneficiaryReq) Context(ctx context.Context) *DeleteBeneficiaryReq {
+	r.req.ctx = ctx
+	return r
+}
+
+// ClientID sets a client identifier that will be passed to the Bankrs API in
+// the X-Client-Id header.
+func (r *DeleteBeneficiaryReq) ClientID(id string) *DeleteBeneficiaryReq {
+	r.req.clientID = id
+	return r
+}
+
+// Send sends the request to delete a beneficiary.
+func (r *DeleteBeneficiaryReq) Send() error {
+	_, cleanup, err := r.req.delete(nil)
+	defer cleanup()
+	return err
+}
@@ -15,8 +15,14 @@
 package bosgo
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUserLogout(t *testing.T) {
@@ -35,3 +41,414 @@ func TestUserLogout(t *testing.T) {
 		t.Fatalf("failed to send logout request: %v", err)
 	}
 }
+
+func TestJobWaitTimeout(t *testing.T) {
+	routes := routeMap{
+		"/v1/jobs/": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"finished":false,"stage":"authenticated"}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+	_, err := userClient.Jobs.Wait(ctx, "/jobs/1234", 10*time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitForAllReadyRepeatedJob(t *testing.T) {
+	routes := routeMap{
+		"/v1/accesses/refresh": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"uri":"/jobs/1"},{"uri":"/jobs/1"},{"uri":"/jobs/2"}]`))
+			},
+		},
+		"/v1/jobs/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"finished":true,"stage":"imported","uri":"/jobs/1"}`))
+			},
+		},
+		"/v1/jobs/2": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"finished":false,"stage":"challenge","uri":"/jobs/2"}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+	summary, err := userClient.Accesses.WaitForAllReady(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.Succeeded) != 1 || summary.Succeeded[0].URI != "/jobs/1" {
+		t.Errorf("got succeeded jobs %+v, wanted /jobs/1", summary.Succeeded)
+	}
+	if len(summary.NeedsCredentials) != 1 || summary.NeedsCredentials[0].URI != "/jobs/2" {
+		t.Errorf("got jobs needing credentials %+v, wanted /jobs/2", summary.NeedsCredentials)
+	}
+}
+
+func TestAccountStatement(t *testing.T) {
+	routes := routeMap{
+		"/v1/accounts/1/statement": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept") != "application/pdf" {
+					w.WriteHeader(http.StatusNotAcceptable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Write([]byte("%PDF-1.4"))
+			},
+		},
+		"/v1/accounts/2/statement": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"resource_not_found"}]}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+
+	var buf bytes.Buffer
+	contentType, err := userClient.Accounts.Statement(1).Send(&buf)
+	if err != nil {
+		t.Fatalf("failed to send statement request: %v", err)
+	}
+	if contentType != "application/pdf" {
+		t.Errorf("got content type %q, wanted %q", contentType, "application/pdf")
+	}
+	if buf.String() != "%PDF-1.4" {
+		t.Errorf("got body %q, wanted %q", buf.String(), "%PDF-1.4")
+	}
+
+	_, err = userClient.Accounts.Statement(2).Send(&buf)
+	berr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("got error %T, wanted *Error", err)
+	}
+	if len(berr.Errors) != 1 || berr.Errors[0].Code != "resource_not_found" {
+		t.Errorf("got errors %+v, wanted resource_not_found", berr.Errors)
+	}
+}
+
+func TestAddAccessAnswers(t *testing.T) {
+	var bodies []string
+	routes := routeMap{
+		"/v1/accesses": {
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"uri":"/jobs/1"}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	login := ChallengeAnswer{ID: "login", Value: "user"}
+	pin := ChallengeAnswer{ID: "pin", Value: "1234", Store: true}
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+	if _, err := userClient.Accesses.Add("provider").ChallengeAnswer(login).ChallengeAnswer(pin).Send(); err != nil {
+		t.Fatalf("failed to add access: %v", err)
+	}
+	if _, err := userClient.Accesses.AddWithAnswers("provider", ChallengeAnswerList{login, pin}).Send(); err != nil {
+		t.Fatalf("failed to add access with answers: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("got %d requests, wanted 2", len(bodies))
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("got body %s from AddWithAnswers, wanted %s", bodies[1], bodies[0])
+	}
+}
+
+func TestAccountProvider(t *testing.T) {
+	routes := routeMap{
+		"/v1/accounts/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":1,"provider_id":"DE-BIN-10001000"}`))
+			},
+		},
+		"/v1/accounts/2": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":2,"provider_id":"DE-BIN-UNKNOWN"}`))
+			},
+		},
+		"/v1/accounts/4": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":4}`))
+			},
+		},
+		"/v1/providers/": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("got request for %s, wanted no provider lookup", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		"/v1/providers/DE-BIN-10001000": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Application-Key") != "appkey" {
+					unauthorizedHandler(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"DE-BIN-10001000","name":"Test Bank"}`))
+			},
+		},
+		"/v1/providers/DE-BIN-UNKNOWN": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"resource_not_found"}]}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+
+	p, err := userClient.Accounts.Provider(1).Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != "DE-BIN-10001000" || p.Name != "Test Bank" {
+		t.Errorf("got provider %+v, wanted Test Bank", p)
+	}
+
+	p, err = userClient.Accounts.Provider(2).Send()
+	if err != nil {
+		t.Fatalf("unexpected error for unknown provider: %v", err)
+	}
+	if p.ID != "DE-BIN-UNKNOWN" || p.Name != "" {
+		t.Errorf("got provider %+v, wanted only ID DE-BIN-UNKNOWN", p)
+	}
+
+	_, err = userClient.Accounts.Provider(3).Send()
+	if !IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+
+	p, err = userClient.Accounts.Provider(4).Send()
+	if err != nil {
+		t.Fatalf("unexpected error for account without provider: %v", err)
+	}
+	if p.ID != "" {
+		t.Errorf("got provider %+v, wanted empty provider", p)
+	}
+}
+
+func TestAccountTransferContext(t *testing.T) {
+	routes := routeMap{
+		"/v1/accounts/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":1,"bank_access_id":7,"provider_id":"DE-BIN-10001000","holder":"Bruce Lee","iban":"DE54200411110704357300","capabilities":{"transfer":["create","read"]}}`))
+			},
+		},
+		"/v1/accounts/2": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":2,"bank_access_id":7,"provider_id":"DE-BIN-UNKNOWN"}`))
+			},
+		},
+		"/v1/accounts/4": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":4,"bank_access_id":7}`))
+			},
+		},
+		"/v1/providers/": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("got request for %s, wanted no provider lookup", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		"/v1/accesses/7": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":7,"capabilities":{"scheduled_transfer":{"supported":true},"recurring_transfer":{"minimum_lead_time_create":5}}}`))
+			},
+		},
+		"/v1/providers/DE-BIN-10001000": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"DE-BIN-10001000","challenges":[{"id":"tan","methods":["901","902"],"info":{"901":"mobile TAN"}},{"id":"pin","methods":["901"]}]}`))
+			},
+		},
+		"/v1/providers/DE-BIN-UNKNOWN": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"resource_not_found"}]}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+
+	tc, err := userClient.Accounts.TransferContext(1).Send()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tc.Account.IBAN != "DE54200411110704357300" || tc.Account.Holder != "Bruce Lee" {
+		t.Errorf("got account %+v, wanted IBAN and holder", tc.Account)
+	}
+	if !reflect.DeepEqual(tc.Account.Capabilities.Transfer, []string{"create", "read"}) {
+		t.Errorf("got transfer capabilities %v, wanted create and read", tc.Account.Capabilities.Transfer)
+	}
+	if !tc.AccessCapabilities.ScheduledTransfer.Supported || tc.AccessCapabilities.RecurringTransfer.MinimumLeadTimeCreate != 5 {
+		t.Errorf("got access capabilities %+v, wanted those of access 7", tc.AccessCapabilities)
+	}
+	wantMethods := []AuthMethod{{ID: "901", Description: "mobile TAN"}, {ID: "902"}}
+	if !reflect.DeepEqual(tc.AuthMethods, wantMethods) {
+		t.Errorf("got auth methods %+v, wanted %+v", tc.AuthMethods, wantMethods)
+	}
+
+	tc, err = userClient.Accounts.TransferContext(2).Send()
+	if err != nil {
+		t.Fatalf("unexpected error for unknown provider: %v", err)
+	}
+	if tc.Account.ID != 2 || len(tc.AuthMethods) != 0 {
+		t.Errorf("got transfer context %+v, wanted account 2 without auth methods", tc)
+	}
+
+	_, err = userClient.Accounts.TransferContext(3).Send()
+	if !IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+
+	tc, err = userClient.Accounts.TransferContext(4).Send()
+	if err != nil {
+		t.Fatalf("unexpected error for account without provider: %v", err)
+	}
+	if len(tc.AuthMethods) != 0 {
+		t.Errorf("got auth methods %+v, wanted none", tc.AuthMethods)
+	}
+}
+
+func TestSendInto(t *testing.T) {
+	routes := routeMap{
+		"/v1/accounts": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"id":1,"name":"Girokonto","overdraft_fee":"9.50"},{"id":2,"name":"Sparkonto"}]`))
+			},
+		},
+		"/v1/accounts/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":1,"name":"Girokonto","iban":"DE54200411110704357300","overdraft_fee":"9.50"}`))
+			},
+		},
+		"/v1/accounts/2": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"resource_not_found"}]}`))
+			},
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+
+	type extendedAccount struct {
+		Account
+		OverdraftFee string `json:"overdraft_fee"`
+	}
+
+	var account extendedAccount
+	if err := userClient.Accounts.Get("1").SendInto(&account); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.ID != 1 || account.IBAN != "DE54200411110704357300" {
+		t.Errorf("got account %+v, wanted id 1 with IBAN", account.Account)
+	}
+	if account.OverdraftFee != "9.50" {
+		t.Errorf("got overdraft fee %q, wanted 9.50", account.OverdraftFee)
+	}
+
+	var accounts []extendedAccount
+	if err := userClient.Accounts.List().SendInto(&accounts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].OverdraftFee != "9.50" || accounts[1].Name != "Sparkonto" {
+		t.Errorf("got accounts %+v, wanted two accounts with the first's overdraft fee", accounts)
+	}
+
+	err := userClient.Accounts.Get("2").SendInto(&account)
+	if !IsNotFound(err) {
+		t.Errorf("got error %v, wanted not found", err)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	routes := routeMap{
+		"/v1/repeated_transactions/1": {
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"resource_not_found"}]}`))
+			},
+		},
+		"/v1/repeated_transactions/2": {
+			http.MethodGet: unauthorizedHandler,
+		},
+	}
+
+	hc, cleanup := startTestServer(t, routes)
+	defer cleanup()
+
+	userClient := NewUserClient(hc, SandboxAddr, "uid", "usertoken", "appkey")
+
+	_, err := userClient.RepeatedTransactions.Get("1").Send()
+	if !IsNotFound(err) {
+		t.Errorf("got IsNotFound false for %v, wanted true", err)
+	}
+	if wrapped := fmt.Errorf("getting standing order: %w", err); !IsNotFound(wrapped) {
+		t.Errorf("got IsNotFound false for %v, wanted true", wrapped)
+	}
+
+	_, err = userClient.RepeatedTransactions.Get("2").Send()
+	if err == nil {
+		t.Fatalf("got nil error, wanted non-nil")
+	}
+	if IsNotFound(err) {
+		t.Errorf("got IsNotFound true for %v, wanted false", err)
+	}
+}
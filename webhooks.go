@@ -2,7 +2,13 @@ package bosgo
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 )
 
@@ -276,3 +282,44 @@ func (r *TestWebhookReq) Send() (*WebhookTestResult, error) {
 
 	return &testResponse, nil
 }
+
+// WebhookSignatureHeader is the HTTP header holding the signature of a webhook
+// callback. The signature is the hex encoded HMAC-SHA256 of the request body,
+// keyed with the webhook secret.
+const WebhookSignatureHeader = "X-Bankrs-Signature"
+
+// ErrInvalidWebhookSignature is returned by ParseWebhookPayload when the
+// signature of a webhook callback is missing or does not match its body.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ParseWebhookPayload reads the body of a webhook callback request, verifies
+// its signature using secret and decodes the payload. It returns
+// ErrInvalidWebhookSignature if the signature cannot be verified, in which
+// case the payload must not be trusted.
+func ParseWebhookPayload(r *http.Request, secret string) (*WebhookPayload, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := hex.DecodeString(r.Header.Get(WebhookSignatureHeader))
+	if err != nil || len(sig) == 0 {
+		return nil, ErrInvalidWebhookSignature
+	}
+	if !hmac.Equal(sig, webhookSignature(body, secret)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+func webhookSignature(body []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,60 @@
+package bosgo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testWebhookBody = `{"event":{"id":"7ea712bd-2db9-4408-b2ca-4c43cc98f369","type":"transactions","api_version":1,"environment":"sandbox"},"data":{"user_id":"u1"}}`
+
+func newSignedWebhookRequest(body []byte, secret string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	r.Header.Set(WebhookSignatureHeader, hex.EncodeToString(webhookSignature(body, secret)))
+	return r
+}
+
+func TestParseWebhookPayload(t *testing.T) {
+	r := newSignedWebhookRequest([]byte(testWebhookBody), "secret")
+
+	payload, err := ParseWebhookPayload(r, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Event.ID != "7ea712bd-2db9-4408-b2ca-4c43cc98f369" {
+		t.Errorf("got event id %q, wanted %q", payload.Event.ID, "7ea712bd-2db9-4408-b2ca-4c43cc98f369")
+	}
+	if payload.Event.Type != "transactions" {
+		t.Errorf("got event type %q, wanted %q", payload.Event.Type, "transactions")
+	}
+	if payload.Data["user_id"] != "u1" {
+		t.Errorf("got user_id %v, wanted %q", payload.Data["user_id"], "u1")
+	}
+}
+
+func TestParseWebhookPayloadTampered(t *testing.T) {
+	r := newSignedWebhookRequest([]byte(testWebhookBody), "secret")
+
+	// Replace the body after signing
+	tampered := bytes.Replace([]byte(testWebhookBody), []byte(`"u1"`), []byte(`"u2"`), 1)
+	r.Body = ioutil.NopCloser(bytes.NewReader(tampered))
+
+	if _, err := ParseWebhookPayload(r, "secret"); err != ErrInvalidWebhookSignature {
+		t.Errorf("got error %v, wanted %v", err, ErrInvalidWebhookSignature)
+	}
+}
+
+func TestParseWebhookPayloadWrongSecret(t *testing.T) {
+	r := newSignedWebhookRequest([]byte(testWebhookBody), "secret")
+	if _, err := ParseWebhookPayload(r, "other"); err != ErrInvalidWebhookSignature {
+		t.Errorf("got error %v, wanted %v", err, ErrInvalidWebhookSignature)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader([]byte(testWebhookBody)))
+	if _, err := ParseWebhookPayload(r, "secret"); err != ErrInvalidWebhookSignature {
+		t.Errorf("unsigned request: got error %v, wanted %v", err, ErrInvalidWebhookSignature)
+	}
+}